@@ -1,13 +1,33 @@
 package builder
 
 import (
+	"time"
+
 	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+	auditHandler "github.com/SoraDaibu/go-clean-starter/internal/http/handler/audit"
+	itemHandler "github.com/SoraDaibu/go-clean-starter/internal/http/handler/item"
 	"github.com/SoraDaibu/go-clean-starter/internal/http/handler/user"
+	webhookHandler "github.com/SoraDaibu/go-clean-starter/internal/http/handler/webhook"
+	"github.com/SoraDaibu/go-clean-starter/internal/idempotency"
+	"github.com/SoraDaibu/go-clean-starter/internal/ratelimit"
+	"github.com/SoraDaibu/go-clean-starter/internal/redact"
 	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	auditRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/audit"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/cached"
+	idempotencyRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/idempotency"
 	itemRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/item"
+	rateLimitRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/ratelimit"
 	userRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/user"
+	webhookRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/webhook"
+	auditUsecase "github.com/SoraDaibu/go-clean-starter/internal/service/audit"
+	itemUsecase "github.com/SoraDaibu/go-clean-starter/internal/service/item"
 	userUsecase "github.com/SoraDaibu/go-clean-starter/internal/service/user"
+	webhookUsecase "github.com/SoraDaibu/go-clean-starter/internal/service/webhook"
+	idempotencyTask "github.com/SoraDaibu/go-clean-starter/internal/task/idempotency"
 	"github.com/SoraDaibu/go-clean-starter/internal/task/item"
+	"github.com/SoraDaibu/go-clean-starter/internal/task/purge"
 )
 
 // InitializeDependency creates a new Dependency instance with all required dependencies
@@ -17,20 +37,112 @@ func InitializeDependency(cfg *config.Config) (*Dependency, error) {
 
 // InitializeUserUsecase creates a new UserUsecase instance
 func InitializeUserUsecase(d *Dependency) userUsecase.UserUsecase {
-	userRepository := userRepo.NewUserRepository(d.DB)
-	return userUsecase.NewUserUsecase(userRepository)
+	transaction := repository.NewTransaction(d.DB)
+	return userUsecase.NewUserUsecase(
+		transaction,
+		InitializeUserRepository(d),
+		userRepo.NewUserLister(d.DB, d.Replicas),
+		InitializeAuditRecorder(d),
+	)
+}
+
+// InitializeUserRepository creates a new UserRepository, cached when a cache is configured
+func InitializeUserRepository(d *Dependency) domain.UserRepository {
+	userRepository := userRepo.NewUserRepository(d.DB, d.Replicas)
+	if d.Cache == nil {
+		return userRepository
+	}
+	return cached.NewUserRepository(userRepository, d.Cache, time.Duration(d.Config.Cache.TTLSeconds)*time.Second)
 }
 
 // InitializeUserHandler creates a new UserHandler instance
 func InitializeUserHandler(d *Dependency) *user.UserHandler {
-	userRepository := userRepo.NewUserRepository(d.DB)
-	uu := userUsecase.NewUserUsecase(userRepository)
+	uu := InitializeUserUsecase(d)
 	return user.NewUserHandler(uu)
 }
 
 // InitializeItemTaskUsecase creates a new ItemTaskUsecase instance
 func InitializeItemTaskUsecase(d *Dependency) item.ItemTaskUsecase {
 	transaction := repository.NewTransaction(d.DB)
-	itemRepository := itemRepo.NewItemRepository(d.DB)
-	return item.NewItemTaskUsecase(transaction, itemRepository)
+	return item.NewItemTaskUsecase(transaction, InitializeItemRepository(d), InitializeAuditRecorder(d))
+}
+
+// InitializeItemRepository creates a new ItemRepository, cached when a cache is configured
+func InitializeItemRepository(d *Dependency) domain.ItemRepository {
+	itemRepository := itemRepo.NewItemRepository(d.DB, d.Replicas)
+	if d.Cache == nil {
+		return itemRepository
+	}
+	return cached.NewItemRepository(itemRepository, d.Cache, time.Duration(d.Config.Cache.TTLSeconds)*time.Second)
+}
+
+// InitializeItemUsecase creates a new ItemUsecase instance
+func InitializeItemUsecase(d *Dependency) itemUsecase.ItemUsecase {
+	return itemUsecase.NewItemUsecase(itemRepo.NewItemSearcher(d.DB, d.Replicas), itemRepo.NewItemLister(d.DB, d.Replicas))
+}
+
+// InitializeItemHandler creates a new ItemHandler instance
+func InitializeItemHandler(d *Dependency) *itemHandler.ItemHandler {
+	return itemHandler.NewItemHandler(InitializeItemUsecase(d))
+}
+
+// InitializeAuditRecorder creates a new audit Recorder
+func InitializeAuditRecorder(d *Dependency) audit.Recorder {
+	return audit.NewRecorder(auditRepo.NewAuditRepository(d.DB))
+}
+
+// InitializeAuditUsecase creates a new AuditUsecase instance
+func InitializeAuditUsecase(d *Dependency) auditUsecase.AuditUsecase {
+	return auditUsecase.NewAuditUsecase(auditRepo.NewAuditRepository(d.DB))
+}
+
+// InitializeAuditHandler creates a new AuditHandler instance
+func InitializeAuditHandler(d *Dependency) *auditHandler.AuditHandler {
+	return auditHandler.NewAuditHandler(InitializeAuditUsecase(d))
+}
+
+// InitializeWebhookUsecase creates a new WebhookUsecase instance
+func InitializeWebhookUsecase(d *Dependency) webhookUsecase.WebhookUsecase {
+	return webhookUsecase.NewWebhookUsecase(webhookRepo.NewWebhookRepository(d.DB), d.Config.Webhook.AllowPrivateNetworks)
+}
+
+// InitializeWebhookHandler creates a new WebhookHandler instance
+func InitializeWebhookHandler(d *Dependency) *webhookHandler.WebhookHandler {
+	return webhookHandler.NewWebhookHandler(InitializeWebhookUsecase(d))
+}
+
+// InitializePurgeTaskUsecase creates a new PurgeTaskUsecase instance
+func InitializePurgeTaskUsecase(d *Dependency) purge.PurgeTaskUsecase {
+	transaction := repository.NewTransaction(d.DB)
+	return purge.NewPurgeTaskUsecase(transaction, InitializeUserRepository(d), InitializeItemRepository(d))
+}
+
+// InitializeIdempotencyStore creates a new idempotency Store
+func InitializeIdempotencyStore(d *Dependency) idempotency.Store {
+	return idempotencyRepo.NewIdempotencyRepository(d.DB)
+}
+
+// InitializeIdempotencyTaskUsecase creates a new IdempotencyTaskUsecase instance
+func InitializeIdempotencyTaskUsecase(d *Dependency) idempotencyTask.IdempotencyTaskUsecase {
+	return idempotencyTask.NewIdempotencyTaskUsecase(InitializeIdempotencyStore(d))
+}
+
+// InitializeRedactor creates a new Redactor for log output
+func InitializeRedactor(d *Dependency) *redact.Redactor {
+	return redact.New(redact.Config{
+		FieldNames:     d.Config.Log.RedactFields,
+		JSONPaths:      d.Config.Log.RedactJSONPaths,
+		Headers:        d.Config.Log.RedactHeaders,
+		MaskEmails:     d.Config.Log.MaskEmails,
+		MaxBodyBytes:   d.Config.Log.BodyMaxBytes,
+		BodySampleRate: d.Config.Log.BodySampleRate,
+	})
+}
+
+// InitializeRateLimitStore creates the rate limit store selected by config
+func InitializeRateLimitStore(d *Dependency) ratelimit.Store {
+	if d.Config.RateLimit.Store == "postgres" {
+		return rateLimitRepo.NewPostgresStore(d.DB)
+	}
+	return ratelimit.NewMemoryStore()
 }
@@ -2,26 +2,60 @@ package builder
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/cache"
+	"github.com/SoraDaibu/go-clean-starter/internal/httpclient"
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
 type Dependency struct {
 	Config *config.Config
 	DB     *pgxpool.Pool
-	HTTP   *http.Client
+	// Replicas serve reads of repositories that support them; nil when no replica is configured
+	Replicas *repository.Replicas
+	// Secrets refreshes the secrets of Config; nil when refreshing is disabled
+	Secrets *config.SecretRefresher
+	// Cache serves reads of users and items by id; nil when cache.backend is none
+	Cache cache.Cache
+	// HTTP sends requests to other services with retries and a circuit breaker per host
+	HTTP *http.Client
+	// Lifecycle starts, checks and stops the dependencies above. Commands may append their own, e.g. a message broker.
+	Lifecycle *Lifecycle
+}
+
+// Health reports the health of the started dependencies
+func (d *Dependency) Health(ctx context.Context) []HealthStatus {
+	return d.Lifecycle.Health(ctx)
+}
+
+// Close stops the dependencies in reverse order of their start
+func (d *Dependency) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return d.Lifecycle.Stop(ctx)
 }
 
 type (
 	NeedsDB bool
 )
 
-// DependencyNeeds specifies which dependencies are required
-// Add more dependency as needed such as S3, Redis, Stripe, etc.
+// DependencyNeeds specifies which dependencies are required, so that commands only start what they use.
+// Add more dependency as needed such as S3, Redis, Stripe, etc., each with a Hook appended in Resolve.
 type DependencyNeeds struct {
 	needsDB NeedsDB
 }
@@ -38,53 +72,209 @@ func NewDependencyNeedsAllTrue() *DependencyNeeds {
 	}
 }
 
+// Resolve starts the dependencies in dn in order: secrets first, since connections read them, then the database
+// and its replicas. Close the returned Dependency to stop them. On error, nothing is left running.
 func Resolve(c *config.Config, dn *DependencyNeeds) (*Dependency, error) {
-	d := &Dependency{
-		Config: c,
-		HTTP: &http.Client{
-			Timeout: time.Duration(c.HTTP.TimeoutSeconds) * time.Second,
-		},
+	d := &Dependency{Config: c, Lifecycle: &Lifecycle{}}
+	d.HTTP = httpclient.New(httpClientConfig(c), InitializeRedactor(d))
+
+	if c.Secrets.RefreshSeconds > 0 {
+		d.Lifecycle.Append(Hook{
+			Name: "secrets",
+			Start: func(context.Context) error {
+				d.Secrets = config.NewSecretRefresher(c, time.Duration(c.Secrets.RefreshSeconds)*time.Second)
+				go d.Secrets.Run()
+				return nil
+			},
+			Stop: func(context.Context) error {
+				d.Secrets.Close()
+				return nil
+			},
+		})
 	}
 
 	if dn.needsDB {
-		if err := connectDB(d); err != nil {
-			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		d.Lifecycle.Append(Hook{
+			Name:   "db",
+			Start:  func(ctx context.Context) error { return connectDB(ctx, d) },
+			Health: func(ctx context.Context) error { return d.DB.Ping(ctx) },
+			Stop: func(context.Context) error {
+				d.DB.Close()
+				return nil
+			},
+		})
+
+		if len(c.DB.Replica.Hosts) > 0 {
+			d.Lifecycle.Append(Hook{
+				Name:  "db-replicas",
+				Start: func(context.Context) error { return connectReplicas(d) },
+				// unhealthy replicas are taken out of rotation, so they never make the service unhealthy
+				Stop: func(context.Context) error {
+					d.Replicas.Close()
+					return nil
+				},
+			})
+		}
+
+		if c.Cache.Backend != "none" {
+			d.Lifecycle.Append(Hook{
+				Name:  "cache",
+				Start: func(context.Context) error { return connectCache(d) },
+				// reads fall back to the database while the cache fails, so it never makes the service unhealthy
+				Stop: func(context.Context) error {
+					if closer, ok := d.Cache.(io.Closer); ok {
+						return closer.Close()
+					}
+					return nil
+				},
+			})
 		}
 	}
 
+	d.Lifecycle.Append(Hook{
+		Name: "http-client",
+		Stop: func(context.Context) error {
+			d.HTTP.CloseIdleConnections()
+			return nil
+		},
+	})
+
+	if err := d.Lifecycle.Start(context.Background()); err != nil {
+		return nil, err
+	}
+
 	return d, nil
 }
 
-func connectDB(d *Dependency) error {
-	// Parse config with pool settings
-	config, err := pgxpool.ParseConfig(dbURL(d.Config, false))
+func connectDB(ctx context.Context, d *Dependency) error {
+	config, err := poolConfig(d.Config, d.Config.DB.Host, d.Config.DB.Port)
 	if err != nil {
-		return fmt.Errorf("%s: %w", dbURL(d.Config, true), err)
+		return err
 	}
 
-	// Set connection pool settings
-	config.MaxConnLifetime = time.Duration(d.Config.DB.Connection.LifetimeSeconds) * time.Second
-	config.MaxConnIdleTime = time.Duration(d.Config.DB.Connection.LifetimeSeconds) * time.Second
-	config.MinConns = int32(d.Config.DB.Connection.MinIdleConns)
-	config.MaxConns = int32(d.Config.DB.Connection.MaxOpen)
-
 	// Create connection pool
-	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return fmt.Errorf("failed to create connection pool: %w", err)
 	}
 
 	// Test the connection
-	if err := pool.Ping(context.Background()); err != nil {
+	if err := pool.Ping(ctx); err != nil {
 		pool.Close()
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	metrics.RegisterDBPool(pool)
+
 	d.DB = pool
 	return nil
 }
 
-func dbURL(c *config.Config, mask bool) string {
+// connectReplicas creates a pool per configured replica and starts their health checks.
+// Unreachable replicas do not fail startup; reads fall back to the primary until they are healthy.
+func connectReplicas(d *Dependency) error {
+	c := d.Config.DB.Replica
+
+	var pools []*pgxpool.Pool
+	for _, hostport := range c.Hosts {
+		host, port := hostport, d.Config.DB.Port
+		if h, p, err := net.SplitHostPort(hostport); err == nil {
+			host = h
+			if port, err = strconv.Atoi(p); err != nil {
+				return fmt.Errorf("invalid replica port %q: %w", hostport, err)
+			}
+		}
+
+		config, err := poolConfig(d.Config, host, port)
+		if err != nil {
+			return err
+		}
+
+		// connects lazily, so an unreachable replica only shows up in the health checks
+		pool, err := pgxpool.NewWithConfig(context.Background(), config)
+		if err != nil {
+			for _, p := range pools {
+				p.Close()
+			}
+			return fmt.Errorf("failed to create connection pool for replica %s: %w", hostport, err)
+		}
+		pools = append(pools, pool)
+	}
+
+	d.Replicas = repository.NewReplicas(pools, repository.ReplicaConfig{
+		Stickiness:          time.Duration(c.StickinessMs) * time.Millisecond,
+		HealthCheckInterval: time.Duration(c.HealthCheckSeconds) * time.Second,
+		MaxLag:              time.Duration(c.MaxLagSeconds) * time.Second,
+	})
+	go d.Replicas.Run()
+
+	return nil
+}
+
+// connectCache creates the cache backend selected by config.
+// Redis is connected lazily, so an unreachable server only shows up as cache errors in the logs.
+func connectCache(d *Dependency) error {
+	c := d.Config.Cache
+
+	switch c.Backend {
+	case "memory":
+		d.Cache = cache.NewLRU(c.Size)
+	case "redis":
+		opts, err := redis.ParseURL(c.RedisURL)
+		if err != nil {
+			// the URL may contain a password
+			return errors.New("invalid cache.redis_url")
+		}
+		d.Cache = cache.NewRedis(redis.NewClient(opts), c.Prefix)
+	}
+
+	return nil
+}
+
+// httpClientConfig returns the settings of clients sending requests to other services
+func httpClientConfig(c *config.Config) httpclient.Config {
+	return httpclient.Config{
+		Timeout:             time.Duration(c.HTTP.TimeoutSeconds) * time.Second,
+		MaxAttempts:         c.HTTP.MaxAttempts,
+		RetryBaseDelay:      time.Duration(c.HTTP.RetryBaseDelayMs) * time.Millisecond,
+		RetryMaxDelay:       time.Duration(c.HTTP.RetryMaxDelayMs) * time.Millisecond,
+		BreakerThreshold:    c.HTTP.BreakerThreshold,
+		BreakerCooldown:     time.Duration(c.HTTP.BreakerCooldownSeconds) * time.Second,
+		MaxIdleConns:        c.HTTP.MaxIdleConns,
+		MaxIdleConnsPerHost: c.HTTP.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     c.HTTP.MaxConnsPerHost,
+		IdleConnTimeout:     time.Duration(c.HTTP.IdleConnTimeoutSeconds) * time.Second,
+		LogBodies:           c.HTTP.LogBodies,
+	}
+}
+
+// poolConfig returns the pool settings for the database at host:port
+func poolConfig(c *config.Config, host string, port int) (*pgxpool.Config, error) {
+	// Parse config with pool settings
+	config, err := pgxpool.ParseConfig(dbURL(c, host, port, false))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dbURL(c, host, port, true), err)
+	}
+
+	// Set connection pool settings
+	config.MaxConnLifetime = time.Duration(c.DB.Connection.LifetimeSeconds) * time.Second
+	config.MaxConnIdleTime = time.Duration(c.DB.Connection.LifetimeSeconds) * time.Second
+	config.MinConns = int32(c.DB.Connection.MinIdleConns)
+	config.MaxConns = int32(c.DB.Connection.MaxOpen)
+
+	// Read the password for every new connection, so that a rotated password is used without a restart
+	config.BeforeConnect = func(_ context.Context, cc *pgx.ConnConfig) error {
+		cc.Password = c.Secret("db.password")
+		return nil
+	}
+
+	// Record query latency for /metrics and a span per statement
+	config.ConnConfig.Tracer = multitracer.New(metrics.NewQueryTracer(), tracing.NewQueryTracer())
+
+	return config, nil
+}
+
+func dbURL(c *config.Config, host string, port int, mask bool) string {
 	password := c.DB.Password
 	if mask {
 		password = "********"
@@ -94,8 +284,8 @@ func dbURL(c *config.Config, mask bool) string {
 		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		c.DB.User,
 		password,
-		c.DB.Host,
-		c.DB.Port,
+		host,
+		port,
 		c.DB.Name,
 		c.DB.SSLMode,
 	)
@@ -0,0 +1,114 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Hook is a dependency managed by a Lifecycle. Every func is optional.
+type Hook struct {
+	// Name identifies the dependency in errors, logs and health reports, e.g. "db"
+	Name string
+	// Start connects the dependency or starts its background work
+	Start func(ctx context.Context) error
+	// Health reports whether the dependency can serve, e.g. by pinging it
+	Health func(ctx context.Context) error
+	// Stop releases the dependency. It is only called after Start succeeded.
+	Stop func(ctx context.Context) error
+}
+
+// HealthStatus is the health of a dependency
+type HealthStatus struct {
+	Name    string
+	Healthy bool
+	// Error is why the dependency is unhealthy
+	Error string
+}
+
+// Lifecycle starts dependencies in the order they were appended, reports their health
+// and stops them in reverse order, so that a dependency is stopped before those it uses.
+type Lifecycle struct {
+	mu    sync.Mutex
+	hooks []Hook
+	// started is the number of hooks started, from the first
+	started int
+}
+
+// Append registers a dependency, to be started after the ones already appended
+func (l *Lifecycle) Append(h Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, h)
+}
+
+// Start starts the dependencies not started yet, in order.
+// When one fails, the ones started by this call are stopped again and the error is returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	from := l.started
+	for _, h := range l.hooks[from:] {
+		if h.Start != nil {
+			if err := h.Start(ctx); err != nil {
+				l.stop(ctx, from)
+				return fmt.Errorf("failed to start %s: %w", h.Name, err)
+			}
+		}
+		l.started++
+	}
+
+	return nil
+}
+
+// Health checks the started dependencies, in order
+func (l *Lifecycle) Health(ctx context.Context) []HealthStatus {
+	l.mu.Lock()
+	hooks := l.hooks[:l.started]
+	l.mu.Unlock()
+
+	statuses := make([]HealthStatus, 0, len(hooks))
+	for _, h := range hooks {
+		status := HealthStatus{Name: h.Name, Healthy: true}
+		if h.Health != nil {
+			if err := h.Health(ctx); err != nil {
+				status.Healthy = false
+				status.Error = err.Error()
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// Stop stops the started dependencies in reverse order. It keeps stopping when one fails
+// and returns every error. Stopping twice is a no-op.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stop(ctx, 0)
+}
+
+// stop stops the started hooks down to index from. l.mu must be held.
+func (l *Lifecycle) stop(ctx context.Context, from int) error {
+	var errs []error
+	for ; l.started > from; l.started-- {
+		h := l.hooks[l.started-1]
+		if h.Stop == nil {
+			continue
+		}
+		if err := h.Stop(ctx); err != nil {
+			log.Error().Err(err).Str("dependency", h.Name).Msg("failed to stop dependency")
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", h.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
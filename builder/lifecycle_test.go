@@ -0,0 +1,76 @@
+package builder_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/builder"
+)
+
+// recorded appends a hook to l that records its calls in calls
+func recorded(l *builder.Lifecycle, name string, calls *[]string, startErr, healthErr error) {
+	l.Append(builder.Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return startErr
+		},
+		Health: func(context.Context) error { return healthErr },
+		Stop: func(context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return nil
+		},
+	})
+}
+
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("starts in order and stops in reverse order", func(t *testing.T) {
+		var calls []string
+		l := &builder.Lifecycle{}
+		recorded(l, "secrets", &calls, nil, nil)
+		recorded(l, "db", &calls, nil, errors.New("connection refused"))
+		l.Append(builder.Hook{Name: "http-client"})
+
+		require.NoError(t, l.Start(ctx))
+		assert.Equal(t, []builder.HealthStatus{
+			{Name: "secrets", Healthy: true},
+			{Name: "db", Healthy: false, Error: "connection refused"},
+			{Name: "http-client", Healthy: true},
+		}, l.Health(ctx))
+
+		require.NoError(t, l.Stop(ctx))
+		require.NoError(t, l.Stop(ctx))
+		assert.Equal(t, []string{"start secrets", "start db", "stop db", "stop secrets"}, calls)
+		assert.Empty(t, l.Health(ctx))
+	})
+
+	t.Run("a failed start stops what was started", func(t *testing.T) {
+		var calls []string
+		l := &builder.Lifecycle{}
+		recorded(l, "secrets", &calls, nil, nil)
+		recorded(l, "db", &calls, errors.New("connection refused"), nil)
+		recorded(l, "db-replicas", &calls, nil, nil)
+
+		err := l.Start(ctx)
+		require.EqualError(t, err, "failed to start db: connection refused")
+		assert.Equal(t, []string{"start secrets", "start db", "stop secrets"}, calls)
+		assert.Empty(t, l.Health(ctx))
+	})
+
+	t.Run("stop errors are joined", func(t *testing.T) {
+		l := &builder.Lifecycle{}
+		for _, name := range []string{"a", "b"} {
+			l.Append(builder.Hook{Name: name, Stop: func(context.Context) error { return errors.New("closed") }})
+		}
+
+		require.NoError(t, l.Start(ctx))
+		err := l.Stop(ctx)
+		assert.EqualError(t, err, "failed to stop b: closed\nfailed to stop a: closed")
+	})
+}
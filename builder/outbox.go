@@ -0,0 +1,153 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/httpclient"
+	"github.com/SoraDaibu/go-clean-starter/internal/outbox"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	outboxRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/outbox"
+	webhookRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/webhook"
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+)
+
+// InitializeOutboxRelay creates a new outbox Relay publishing to sink
+func InitializeOutboxRelay(d *Dependency, sink outbox.Sink) *outbox.Relay {
+	return outbox.NewRelay(
+		repository.NewTransaction(d.DB),
+		outboxRepo.NewOutboxRepository(d.DB),
+		sink,
+		outbox.Config{
+			BatchSize:    d.Config.Outbox.BatchSize,
+			PollInterval: time.Duration(d.Config.Outbox.PollIntervalMs) * time.Millisecond,
+			Retention:    time.Duration(d.Config.Outbox.RetentionHours) * time.Hour,
+			MaxAttempts:  d.Config.Outbox.MaxAttempts,
+			BaseDelay:    time.Duration(d.Config.Outbox.BaseDelaySeconds) * time.Second,
+			MaxDelay:     time.Duration(d.Config.Outbox.MaxDelaySeconds) * time.Second,
+			ClaimTimeout: time.Duration(d.Config.Outbox.ClaimTimeoutSeconds) * time.Second,
+		},
+	)
+}
+
+// InitializeOutboxSink starts the outbox sinks selected by config, each as a Hook of d.Lifecycle,
+// so that closing d releases their connections and files after the relay stopped
+func InitializeOutboxSink(d *Dependency) (outbox.Sink, error) {
+	var sinks outbox.MultiSink
+	for _, name := range d.Config.Outbox.Sinks {
+		d.Lifecycle.Append(outboxSinkHook(d, name, &sinks))
+	}
+	if err := d.Lifecycle.Start(context.Background()); err != nil {
+		return nil, err
+	}
+
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+	return sinks, nil
+}
+
+// outboxSinkHook returns the Hook of the sink name, which adds the sink to sinks once started
+func outboxSinkHook(d *Dependency, name string, sinks *outbox.MultiSink) Hook {
+	c := d.Config.Outbox
+	h := Hook{Name: "outbox-" + name}
+
+	switch name {
+	case outbox.SinkStdout:
+		h.Start = func(context.Context) error {
+			*sinks = append(*sinks, outbox.NewWriterSink(os.Stdout))
+			return nil
+		}
+	case outbox.SinkFile:
+		var f *os.File
+		h.Start = func(context.Context) error {
+			var err error
+			if f, err = os.OpenFile(c.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+				return fmt.Errorf("failed to open outbox file %s: %w", c.FilePath, err)
+			}
+			*sinks = append(*sinks, outbox.NewWriterSink(f))
+			return nil
+		}
+		h.Stop = func(context.Context) error { return f.Close() }
+	case outbox.SinkWebhook:
+		h.Start = func(context.Context) error {
+			if c.WebhookURL == "" {
+				return fmt.Errorf("OUTBOX_WEBHOOK_URL is required for the webhook sink")
+			}
+			*sinks = append(*sinks, outbox.NewWebhookSink(d.HTTP, c.WebhookURL))
+			return nil
+		}
+	case outbox.SinkNATS:
+		var nc *nats.Conn
+		h.Start = func(context.Context) error {
+			var err error
+			if nc, err = nats.Connect(c.NATSURL); err != nil {
+				return fmt.Errorf("failed to connect to NATS: %w", err)
+			}
+			js, err := jetstream.New(nc)
+			if err != nil {
+				nc.Close()
+				return fmt.Errorf("failed to create JetStream context: %w", err)
+			}
+			*sinks = append(*sinks, outbox.NewNATSSink(js, c.NATSSubjectPrefix))
+			return nil
+		}
+		// the connection reconnects on its own, so the relay only publishes again once it is connected
+		h.Health = func(context.Context) error {
+			if !nc.IsConnected() {
+				return fmt.Errorf("NATS connection is %s", nc.Status())
+			}
+			return nil
+		}
+		h.Stop = func(context.Context) error { return nc.Drain() }
+	case outbox.SinkWebhooks:
+		h.Start = func(context.Context) error {
+			*sinks = append(*sinks, webhook.NewSink(webhookRepo.NewWebhookRepository(d.DB)))
+			return nil
+		}
+	default:
+		h.Start = func(context.Context) error { return fmt.Errorf("unknown outbox sink: %s", name) }
+	}
+
+	return h
+}
+
+// InitializeWebhookDispatcher creates a new webhook Dispatcher sending deliveries with a client like Dependency.HTTP
+// that refuses to connect to private networks, unless webhook.allow_private_networks is set.
+// The client is a Hook of d.Lifecycle, closing its connections when d is closed.
+func InitializeWebhookDispatcher(d *Dependency) (*webhook.Dispatcher, error) {
+	c := d.Config.Webhook
+	clientConfig := httpClientConfig(d.Config)
+	clientConfig.DenyPrivateNetworks = !c.AllowPrivateNetworks
+	client := httpclient.New(clientConfig, InitializeRedactor(d))
+
+	d.Lifecycle.Append(Hook{
+		Name: "webhook-client",
+		Stop: func(context.Context) error {
+			client.CloseIdleConnections()
+			return nil
+		},
+	})
+	if err := d.Lifecycle.Start(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return webhook.NewDispatcher(
+		repository.NewTransaction(d.DB),
+		webhookRepo.NewWebhookRepository(d.DB),
+		client,
+		webhook.Config{
+			BatchSize:    c.BatchSize,
+			PollInterval: time.Duration(c.PollIntervalMs) * time.Millisecond,
+			MaxAttempts:  c.MaxAttempts,
+			BaseDelay:    time.Duration(c.BaseDelaySeconds) * time.Second,
+			MaxDelay:     time.Duration(c.MaxDelaySeconds) * time.Second,
+			DisableAfter: c.DisableAfter,
+		},
+	), nil
+}
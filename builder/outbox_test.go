@@ -0,0 +1,60 @@
+package builder_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/builder"
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/outbox"
+)
+
+func TestInitializeOutboxSink(t *testing.T) {
+	newDependency := func(sinks ...string) *builder.Dependency {
+		var cfg config.Config
+		cfg.Outbox.Sinks = sinks
+		cfg.Outbox.FilePath = filepath.Join(t.TempDir(), "events.jsonl")
+		return &builder.Dependency{Config: &cfg, Lifecycle: &builder.Lifecycle{}}
+	}
+
+	t.Run("sinks are dependencies of the lifecycle", func(t *testing.T) {
+		d := newDependency(outbox.SinkStdout, outbox.SinkFile)
+
+		sink, err := builder.InitializeOutboxSink(d)
+		require.NoError(t, err)
+		assert.Len(t, sink, 2)
+
+		var names []string
+		for _, s := range d.Health(context.Background()) {
+			names = append(names, s.Name)
+		}
+		assert.Equal(t, []string{"outbox-stdout", "outbox-file"}, names)
+		require.NoError(t, d.Close())
+	})
+
+	t.Run("the file is written until the dependencies are closed", func(t *testing.T) {
+		d := newDependency(outbox.SinkFile)
+
+		sink, err := builder.InitializeOutboxSink(d)
+		require.NoError(t, err)
+		require.NoError(t, sink.Publish(context.Background(), outbox.Message{ID: 1, Type: "user.created", AggregateID: uuid.New()}))
+		require.NoError(t, d.Close())
+		b, err := os.ReadFile(d.Config.Outbox.FilePath)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), "user.created")
+	})
+
+	t.Run("a failing sink stops the ones started", func(t *testing.T) {
+		d := newDependency(outbox.SinkFile, "kafka")
+
+		_, err := builder.InitializeOutboxSink(d)
+		require.ErrorContains(t, err, "unknown outbox sink: kafka")
+		assert.Empty(t, d.Health(context.Background()))
+	})
+}
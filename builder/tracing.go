@@ -0,0 +1,24 @@
+package builder
+
+import (
+	"context"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/tracing"
+)
+
+// InitializeTracing installs the tracer provider as a Hook of d.Lifecycle. Appended after the dependencies
+// a server uses, it is stopped first when d is closed, once the server drained, so the spans of the last requests are flushed.
+func InitializeTracing(ctx context.Context, d *Dependency) error {
+	var shutdown func(context.Context) error
+	d.Lifecycle.Append(Hook{
+		Name: "tracing",
+		Start: func(ctx context.Context) error {
+			var err error
+			shutdown, err = tracing.Setup(ctx, d.Config)
+			return err
+		},
+		Stop: func(ctx context.Context) error { return shutdown(ctx) },
+	})
+
+	return d.Lifecycle.Start(ctx)
+}
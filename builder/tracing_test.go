@@ -0,0 +1,41 @@
+package builder_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	"github.com/SoraDaibu/go-clean-starter/builder"
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/tracing"
+)
+
+func TestInitializeTracing(t *testing.T) {
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	var cfg config.Config
+	cfg.Tracing.Exporter = tracing.ExporterFile
+	cfg.Tracing.FilePath = filepath.Join(t.TempDir(), "traces.json")
+	cfg.Tracing.ServiceName = "test"
+	cfg.Tracing.SampleRatio = 1
+	d := &builder.Dependency{Config: &cfg, Lifecycle: &builder.Lifecycle{}}
+
+	require.NoError(t, builder.InitializeTracing(context.Background(), d))
+	_, span := tracing.Tracer().Start(context.Background(), "last request")
+	span.End()
+
+	// closing the dependencies flushes the spans
+	require.NoError(t, d.Close())
+	b, err := os.ReadFile(cfg.Tracing.FilePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"Name":"last request"`)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/config/secret"
+)
+
+// ConfigFlags are the flags of the root command that every command loads its configuration with:
+// --config for a file, and one flag per key that overrides the file and env vars, e.g. --app.listen_port=8081
+func ConfigFlags() []cli.Flag {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:      "config",
+			Usage:     "YAML or TOML configuration file",
+			Sources:   cli.EnvVars("CONFIG_FILE"),
+			TakesFile: true,
+		},
+	}
+	for _, f := range config.Fields() {
+		usage := "overrides the config file"
+		if f.Env != "" {
+			usage += " and $" + f.Env
+		}
+		flags = append(flags, &cli.StringFlag{Name: f.Key, Usage: usage, Category: "Configuration"})
+	}
+
+	return flags
+}
+
+// loadConfig loads the configuration from the sources given by ConfigFlags
+func loadConfig(c *cli.Command) (*config.Config, error) {
+	overrides := map[string]string{}
+	for _, f := range config.Fields() {
+		if c.IsSet(f.Key) {
+			overrides[f.Key] = c.String(f.Key)
+		}
+	}
+
+	return config.Load(config.WithFile(c.String("config")), config.WithOverrides(overrides))
+}
+
+var ConfigCommand = &cli.Command{
+	Name:  "config",
+	Usage: "Parent Config Command. Exact subcommand is required.",
+	Commands: []*cli.Command{
+		{
+			Name:  "print",
+			Usage: "Print the effective configuration with secrets masked",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "format", Value: "yaml", Usage: "yaml or toml"},
+			},
+			Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) error {
+				cnf, err := loadConfig(c)
+				if err != nil {
+					return err
+				}
+
+				masked := cnf.Masked()
+				switch format := c.String("format"); format {
+				case "yaml":
+					enc := yaml.NewEncoder(os.Stdout)
+					enc.SetIndent(2)
+					defer enc.Close()
+					return enc.Encode(masked)
+				case "toml":
+					return toml.NewEncoder(os.Stdout).Encode(masked)
+				default:
+					return fmt.Errorf("unsupported format %q, expected yaml or toml", format)
+				}
+			}),
+		},
+		{
+			Name:  "keyring",
+			Usage: "Manage the local keyring of secrets referenced as keyring://name",
+			Commands: []*cli.Command{
+				{
+					Name:  "keygen",
+					Usage: "Print a new key for SECRETS_KEYRING_KEY",
+					Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) error {
+						key, err := secret.GenerateKey()
+						if err != nil {
+							return err
+						}
+
+						_, err = fmt.Fprintln(os.Stdout, key)
+						return err
+					}),
+				},
+				{
+					Name:      "set",
+					Usage:     "Encrypt a secret read from stdin into the keyring at SECRETS_KEYRING_PATH",
+					ArgsUsage: "NAME",
+					Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) error {
+						name := c.Args().First()
+						if name == "" {
+							return fmt.Errorf("the name of the secret is required")
+						}
+
+						cnf, err := loadConfig(c)
+						if err != nil {
+							return err
+						}
+						if cnf.Secrets.KeyringPath == "" {
+							return fmt.Errorf("secrets.keyring_path is not configured")
+						}
+						key, err := secret.ParseKey(cnf.Secrets.KeyringKey)
+						if err != nil {
+							return err
+						}
+						keyring, err := secret.NewKeyring(cnf.Secrets.KeyringPath, key)
+						if err != nil {
+							return err
+						}
+
+						value, err := readSecretValue(name)
+						if err != nil {
+							return err
+						}
+
+						return keyring.Set(name, value)
+					}),
+				},
+			},
+		},
+	},
+}
+
+// readSecretValue reads a secret from stdin, without echoing it when stdin is a terminal
+//
+//nolint:forbidigo
+func readSecretValue(name string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "%s> ", name)
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(value), err
+	}
+
+	value, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(value), "\r\n"), nil
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/SoraDaibu/go-clean-starter/config"
 	"github.com/SoraDaibu/go-clean-starter/migration"
 
 	"golang.org/x/term"
@@ -23,7 +24,12 @@ var cliOutput = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 var MigrationCommand = &cli.Command{
 	Name: "migrate",
 	Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) error {
-		return migration.Up(getMigrationDatabaseURL())
+		cnf, err := loadConfig(c)
+		if err != nil {
+			return err
+		}
+
+		return migration.Up(getMigrationDatabaseURL(cnf))
 	}),
 	Commands: []*cli.Command{{
 		Name: "up",
@@ -52,27 +58,14 @@ var MigrationCommand = &cli.Command{
 	}},
 }
 
-func getMigrationDatabaseURL() string {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	database := os.Getenv("DB_NAME")
-	username := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	sslMode := os.Getenv("PGSSLMODE")
-	if sslMode == "" {
-		sslMode = "disable"
-	}
-
-	return fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s",
-		strings.TrimSpace(username),
-		strings.TrimSpace(password),
-		strings.TrimSpace(host),
-		strings.TrimSpace(port),
-		strings.TrimSpace(database),
-	) + fmt.Sprintf("?%s", url.Values{
-		"sslmode": []string{sslMode},
-	}.Encode())
+func getMigrationDatabaseURL(cnf *config.Config) string {
+	return (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cnf.DB.User, cnf.DB.Password),
+		Host:     fmt.Sprintf("%s:%d", cnf.DB.Host, cnf.DB.Port),
+		Path:     cnf.DB.Name,
+		RawQuery: url.Values{"sslmode": []string{cnf.DB.SSLMode}}.Encode(),
+	}).String()
 }
 
 //nolint:forbidigo
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v3"
+
+	"github.com/SoraDaibu/go-clean-starter/builder"
+	"github.com/SoraDaibu/go-clean-starter/internal/outbox"
+	"github.com/SoraDaibu/go-clean-starter/internal/tracing"
+)
+
+var RelayCommand = &cli.Command{
+	Name:  "relay",
+	Usage: "To publish domain events from the outbox, and send webhook deliveries, until stopped",
+	Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) error {
+		log.Info().Msg("starting outbox relay by `relay` command...")
+
+		cnf, err := loadConfig(c)
+		if err != nil {
+			return err
+		}
+
+		log.Logger = log.Hook(tracing.LogHook{})
+		ctx = log.Logger.With().Str("process", "relay").Logger().WithContext(ctx)
+		shutdownTracing, err := tracing.Setup(ctx, cnf)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := shutdownTracing(context.Background()); err != nil {
+				log.Error().Err(err).Msg("failed to flush traces")
+			}
+		}()
+
+		dependencies, err := builder.InitializeDependency(cnf)
+		if err != nil {
+			return err
+		}
+		defer closeDependencies(dependencies)
+
+		// the sinks and the dispatcher are stopped with the dependencies, after the relay stopped
+		sink, err := builder.InitializeOutboxSink(dependencies)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// deliveries created by the webhooks sink are sent by the dispatcher
+		dispatched := make(chan error, 1)
+		if slices.Contains(cnf.Outbox.Sinks, outbox.SinkWebhooks) {
+			dispatcher, err := builder.InitializeWebhookDispatcher(dependencies)
+			if err != nil {
+				return err
+			}
+			go func() { dispatched <- dispatcher.Run(ctx) }()
+		} else {
+			dispatched <- nil
+		}
+
+		relay := builder.InitializeOutboxRelay(dependencies, sink)
+		err = relay.Run(ctx)
+		stop()
+		if err := errors.Join(err, <-dispatched); err != nil {
+			return err
+		}
+
+		log.Info().Msg("outbox relay stopped")
+
+		return nil
+	}),
+}
@@ -2,12 +2,12 @@ package cmd
 
 import (
 	"context"
+	"errors"
 
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v3"
 
 	"github.com/SoraDaibu/go-clean-starter/builder"
-	"github.com/SoraDaibu/go-clean-starter/config"
 	"github.com/SoraDaibu/go-clean-starter/internal/http"
 	"github.com/SoraDaibu/go-clean-starter/migration"
 )
@@ -15,11 +15,11 @@ import (
 var ServeCommand = &cli.Command{
 	Name:  "serve",
 	Usage: "To run a backend server",
-	Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) error {
+	Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) (err error) {
 		// run server
 		log.Info().Msg("starting server by `serve` command...")
 
-		cnf, err := config.Load()
+		cnf, err := loadConfig(c)
 		if err != nil {
 			return err
 		}
@@ -30,18 +30,22 @@ var ServeCommand = &cli.Command{
 			log.Error().Err(err).Msg("failed to resolve dependencies")
 			return err
 		}
+		// traces are flushed when the dependencies are closed, after the server drained
+		if err := builder.InitializeTracing(ctx, d); err != nil {
+			return errors.Join(err, d.Close())
+		}
 
 		// migrate if local
 		if cnf.App.Env == "local" {
-			if err := migration.Up(getMigrationDatabaseURL()); err != nil {
+			if err := migration.Up(getMigrationDatabaseURL(cnf)); err != nil {
 				return err
 			}
 		}
 
+		// drain requests on SIGINT or SIGTERM, then stop the dependencies in reverse order of their start
 		server := http.NewServer(d)
-		defer func() { err = server.Close() }()
-		server.Run()
+		defer func() { err = errors.Join(err, server.Close()) }()
 
-		return nil
+		return server.Run(ctx)
 	}),
 }
@@ -2,13 +2,18 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/SoraDaibu/go-clean-starter/builder"
-	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+	"github.com/SoraDaibu/go-clean-starter/internal/tracing"
 	"github.com/SoraDaibu/go-clean-starter/migration"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var TaskCommand = &cli.Command{
@@ -18,36 +23,32 @@ var TaskCommand = &cli.Command{
 		{
 			Name:  "import",
 			Usage: "Import item from files",
-			Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) error {
-				cnf, err := config.Load()
-				if err != nil {
-					return err
-				}
-
-				dependencies, err := builder.InitializeDependency(cnf)
+			Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) (err error) {
+				ctx, dependencies, finish, err := startTask(ctx, c, "import",
+					attribute.String("task.source_dir", c.String("source-dir")),
+					attribute.Bool("task.dry_run", c.Bool("dry-run")),
+				)
 				if err != nil {
 					return err
 				}
+				defer func() { finish(err) }()
 
 				// args
 				sourceDir := c.String("source-dir")
 				dryRun := c.Bool("dry-run")
-				log.Info().Str("source-dir", sourceDir).Bool("dry-run", dryRun).Msg("importing items")
-
-				// migrate if local
-				if cnf.App.Env == "local" {
-					if err := migration.Up(getMigrationDatabaseURL()); err != nil {
-						return err
-					}
-				}
+				zerolog.Ctx(ctx).Info().Str("source-dir", sourceDir).Bool("dry-run", dryRun).Msg("importing items")
 
 				task := builder.InitializeItemTaskUsecase(dependencies)
 				err = task.ImportItems(ctx, sourceDir, dryRun)
+				// exported even when the import failed, with the rows counted until then
+				if err := metrics.ExportTask(ctx, dependencies.Config, "import", metrics.ImportItemsTotal); err != nil {
+					zerolog.Ctx(ctx).Error().Err(err).Msg("failed to export metrics")
+				}
 				if err != nil {
 					return err
 				}
 
-				log.Info().Msg("item import success 🎉")
+				zerolog.Ctx(ctx).Info().Msg("item import success 🎉")
 
 				return nil
 			}),
@@ -63,6 +64,109 @@ var TaskCommand = &cli.Command{
 				},
 			},
 		},
+		{
+			Name:  "purge",
+			Usage: "Hard delete users and items soft deleted longer than the retention period",
+			Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) (err error) {
+				// args
+				retentionDays := c.Int("retention-days")
+				dryRun := c.Bool("dry-run")
+				if retentionDays < 0 {
+					return fmt.Errorf("retention-days must not be negative: %d", retentionDays)
+				}
+
+				ctx, dependencies, finish, err := startTask(ctx, c, "purge",
+					attribute.Int64("task.retention_days", retentionDays),
+					attribute.Bool("task.dry_run", dryRun),
+				)
+				if err != nil {
+					return err
+				}
+				defer func() { finish(err) }()
+
+				task := builder.InitializePurgeTaskUsecase(dependencies)
+				return task.PurgeDeleted(ctx, time.Duration(retentionDays)*24*time.Hour, dryRun)
+			}),
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "retention-days",
+					Usage: "Keep soft deleted rows for this many days",
+					Value: 30,
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Count rows to purge without deleting them",
+				},
+			},
+		},
+		{
+			Name:  "purge-idempotency-keys",
+			Usage: "Delete expired idempotency keys",
+			Action: cli.ActionFunc(func(ctx context.Context, c *cli.Command) (err error) {
+				ctx, dependencies, finish, err := startTask(ctx, c, "purge-idempotency-keys")
+				if err != nil {
+					return err
+				}
+				defer func() { finish(err) }()
+
+				task := builder.InitializeIdempotencyTaskUsecase(dependencies)
+				return task.PurgeExpiredKeys(ctx)
+			}),
+		},
 		// NOTE: Add more subcommands here for new tasks
 	},
 }
+
+// startTask sets up a run of the task name: it loads the config, logs and traces under a root span named after the task
+// and resolves the dependencies, migrating the database when local. Call finish with the error of the run once it is done,
+// to end the span, stop the dependencies and flush the traces.
+func startTask(ctx context.Context, c *cli.Command, name string, attrs ...attribute.KeyValue) (_ context.Context, _ *builder.Dependency, _ func(error), err error) {
+	cnf, err := loadConfig(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	log.Logger = log.Hook(tracing.LogHook{})
+	ctx = log.Logger.With().Str("task", name).Logger().WithContext(ctx)
+	shutdownTracing, err := tracing.Setup(ctx, cnf)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ctx, endSpan := tracing.StartTask(ctx, name, attrs...)
+
+	var dependencies *builder.Dependency
+	finish := func(err error) {
+		endSpan(err)
+		if dependencies != nil {
+			closeDependencies(dependencies)
+		}
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error().Err(err).Msg("failed to flush traces")
+		}
+	}
+	defer func() {
+		if err != nil {
+			finish(err)
+		}
+	}()
+
+	if dependencies, err = builder.InitializeDependency(cnf); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// migrate if local
+	if cnf.App.Env == "local" {
+		if err := migration.Up(getMigrationDatabaseURL(cnf)); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return ctx, dependencies, finish, nil
+}
+
+// closeDependencies stops the dependencies of a command, logging failures
+func closeDependencies(d *builder.Dependency) {
+	if err := d.Close(); err != nil {
+		log.Error().Err(err).Msg("failed to close dependencies")
+	}
+}
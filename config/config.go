@@ -1,79 +1,426 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/SoraDaibu/go-clean-starter/config/secret"
 )
 
+// RateLimitRule allows PerMinute requests per minute with bursts of up to Burst requests.
+// In env vars and flags it is written as "per_minute:burst".
+type RateLimitRule struct {
+	PerMinute int `yaml:"per_minute" toml:"per_minute"`
+	Burst     int `yaml:"burst" toml:"burst"`
+}
+
+// Config is the configuration of every command.
+// Each value is declared once by its tags: the key in config files and flags (yaml, toml),
+// the env var that overrides it (env), its default (default), whether it is masked when printed (secret)
+// and whether a Reloader applies changes at runtime (reload).
 type Config struct {
 	App struct {
-		Env        string
-		LogLevel   string
-		ListenPort uint16
-	}
+		// Env is one of local, development, staging or production
+		Env string `yaml:"env" toml:"env" env:"APP_ENV" default:"development"`
+		// LogLevel is one of debug, info, warn or error
+		LogLevel   string `yaml:"log_level" toml:"log_level" env:"APP_LOG_LEVEL" default:"info" reload:"true"`
+		ListenPort uint16 `yaml:"listen_port" toml:"listen_port" env:"APP_LISTEN_PORT" default:"8080"`
+		// ShutdownTimeoutSeconds bounds how long serve waits for in-flight requests on SIGINT or SIGTERM
+		ShutdownTimeoutSeconds int `yaml:"shutdown_timeout_seconds" toml:"shutdown_timeout_seconds" env:"APP_SHUTDOWN_TIMEOUT_SECONDS" default:"20"`
+	} `yaml:"app" toml:"app"`
 	DB struct {
-		Host       string
-		Port       int
-		User       string
-		Password   string
-		Name       string
-		SSLMode    string
+		Host       string `yaml:"host" toml:"host" env:"DB_HOST" default:"localhost"`
+		Port       int    `yaml:"port" toml:"port" env:"DB_PORT" default:"5432"`
+		User       string `yaml:"user" toml:"user" env:"DB_USER" default:"postgres"`
+		Password   string `yaml:"password" toml:"password" env:"DB_PASSWORD" secret:"true"`
+		Name       string `yaml:"name" toml:"name" env:"DB_NAME" default:"go_clean_starter"`
+		SSLMode    string `yaml:"sslmode" toml:"sslmode" env:"PGSSLMODE" default:"disable"`
 		Connection struct {
-			MinIdleConns    int
-			MaxOpen         int
-			LifetimeSeconds int
-		}
-	}
+			MinIdleConns    int `yaml:"min_idle_conns" toml:"min_idle_conns" env:"DB_MIN_IDLE_CONNS" default:"2"`
+			MaxOpen         int `yaml:"max_open" toml:"max_open" env:"DB_MAX_OPEN_CONNS" default:"100"`
+			LifetimeSeconds int `yaml:"lifetime_seconds" toml:"lifetime_seconds" env:"DB_CONN_LIFETIME_SECONDS" default:"3600"`
+		} `yaml:"connection" toml:"connection"`
+		Replica struct {
+			// Hosts lists read replicas as host or host:port; they share the credentials of the primary.
+			// Empty sends all queries to the primary.
+			Hosts []string `yaml:"hosts" toml:"hosts" env:"DB_REPLICA_HOSTS"`
+			// StickinessMs sends reads of a client to the primary for this long after its writes committed,
+			// so that it reads its own writes despite replication lag
+			StickinessMs       int `yaml:"stickiness_ms" toml:"stickiness_ms" env:"DB_REPLICA_STICKINESS_MS" default:"2000"`
+			HealthCheckSeconds int `yaml:"health_check_seconds" toml:"health_check_seconds" env:"DB_REPLICA_HEALTH_CHECK_SECONDS" default:"5"`
+			// MaxLagSeconds takes a replica out of rotation while it replays changes older than this. 0 disables the check.
+			MaxLagSeconds int `yaml:"max_lag_seconds" toml:"max_lag_seconds" env:"DB_REPLICA_MAX_LAG_SECONDS" default:"10"`
+		} `yaml:"replica" toml:"replica"`
+	} `yaml:"db" toml:"db"`
 	HTTP struct {
-		TimeoutSeconds int
+		// TimeoutSeconds limits requests to other services, including their retries
+		TimeoutSeconds int `yaml:"timeout_seconds" toml:"timeout_seconds" env:"HTTP_TIMEOUT_SECONDS" default:"10"`
+		// MaxAttempts is how often a request to another service is sent at most. 1 disables retries.
+		// Only idempotent methods and requests with an Idempotency-Key header are retried.
+		MaxAttempts      int `yaml:"max_attempts" toml:"max_attempts" env:"HTTP_MAX_ATTEMPTS" default:"3"`
+		RetryBaseDelayMs int `yaml:"retry_base_delay_ms" toml:"retry_base_delay_ms" env:"HTTP_RETRY_BASE_DELAY_MS" default:"100"`
+		RetryMaxDelayMs  int `yaml:"retry_max_delay_ms" toml:"retry_max_delay_ms" env:"HTTP_RETRY_MAX_DELAY_MS" default:"2000"`
+		// BreakerThreshold consecutive failures of a host fail further requests to it fast for BreakerCooldownSeconds.
+		// 0 disables circuit breaking.
+		BreakerThreshold       int `yaml:"breaker_threshold" toml:"breaker_threshold" env:"HTTP_BREAKER_THRESHOLD" default:"5"`
+		BreakerCooldownSeconds int `yaml:"breaker_cooldown_seconds" toml:"breaker_cooldown_seconds" env:"HTTP_BREAKER_COOLDOWN_SECONDS" default:"30"`
+		// Connection pool of requests to other services. MaxConnsPerHost 0 is unlimited.
+		MaxIdleConns           int `yaml:"max_idle_conns" toml:"max_idle_conns" env:"HTTP_MAX_IDLE_CONNS" default:"100"`
+		MaxIdleConnsPerHost    int `yaml:"max_idle_conns_per_host" toml:"max_idle_conns_per_host" env:"HTTP_MAX_IDLE_CONNS_PER_HOST" default:"10"`
+		MaxConnsPerHost        int `yaml:"max_conns_per_host" toml:"max_conns_per_host" env:"HTTP_MAX_CONNS_PER_HOST"`
+		IdleConnTimeoutSeconds int `yaml:"idle_conn_timeout_seconds" toml:"idle_conn_timeout_seconds" env:"HTTP_IDLE_CONN_TIMEOUT_SECONDS" default:"90"`
+		// LogBodies logs the redacted bodies of requests to other services and their responses at debug level
+		LogBodies bool `yaml:"log_bodies" toml:"log_bodies" env:"HTTP_LOG_BODIES"`
+		// RequestTimeoutSeconds limits the handling of API requests through their context. 0 disables the limit.
+		RequestTimeoutSeconds int `yaml:"request_timeout_seconds" toml:"request_timeout_seconds" env:"HTTP_REQUEST_TIMEOUT_SECONDS" default:"30" reload:"true"`
+		// CORSOrigins lists the origins allowed to call the API from browsers, e.g. https://app.example.com. Empty disables CORS.
+		CORSOrigins []string `yaml:"cors_origins" toml:"cors_origins" env:"HTTP_CORS_ORIGINS" reload:"true"`
+		// TrustedProxies lists the CIDRs of the proxies in front of the API, e.g. 10.0.0.0/8, whose X-Forwarded-For
+		// tells the client IP. Empty uses the address of the connection and ignores client supplied headers.
+		TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES"`
+	} `yaml:"http" toml:"http"`
+	Idempotency struct {
+		// TTLHours is how long responses are kept for replay
+		TTLHours int `yaml:"ttl_hours" toml:"ttl_hours" env:"IDEMPOTENCY_TTL_HOURS" default:"24"`
+		// LockTimeoutSeconds is how long a request holds its key; a retry takes over the key of a request
+		// that stopped without a response after it. Keep it above http.request_timeout_seconds.
+		LockTimeoutSeconds int `yaml:"lock_timeout_seconds" toml:"lock_timeout_seconds" env:"IDEMPOTENCY_LOCK_TIMEOUT_SECONDS" default:"60"`
+	} `yaml:"idempotency" toml:"idempotency"`
+	RateLimit struct {
+		Enabled bool `yaml:"enabled" toml:"enabled" env:"RATE_LIMIT_ENABLED"`
+		// Store is memory (per instance) or postgres (shared by replicas)
+		Store string        `yaml:"store" toml:"store" env:"RATE_LIMIT_STORE" default:"memory"`
+		IP    RateLimitRule `yaml:"ip" toml:"ip" env:"RATE_LIMIT_IP" reload:"true"`
+		User  RateLimitRule `yaml:"user" toml:"user" env:"RATE_LIMIT_USER" reload:"true"`
+		// Groups limits route groups by name, e.g. "users". In env vars and flags it is written as "name=per_minute:burst,...".
+		Groups map[string]RateLimitRule `yaml:"groups" toml:"groups" env:"RATE_LIMIT_GROUPS" reload:"true"`
+	} `yaml:"rate_limit" toml:"rate_limit"`
+	Log struct {
+		// Redact* lists extend the built-in deny lists of the redact package
+		RedactFields    []string `yaml:"redact_fields" toml:"redact_fields" env:"LOG_REDACT_FIELDS"`
+		RedactJSONPaths []string `yaml:"redact_json_paths" toml:"redact_json_paths" env:"LOG_REDACT_JSON_PATHS"`
+		RedactHeaders   []string `yaml:"redact_headers" toml:"redact_headers" env:"LOG_REDACT_HEADERS"`
+		MaskEmails      bool     `yaml:"mask_emails" toml:"mask_emails" env:"LOG_MASK_EMAILS" default:"true"`
+		BodyMaxBytes    int      `yaml:"body_max_bytes" toml:"body_max_bytes" env:"LOG_BODY_MAX_BYTES" default:"4096"`
+		BodySampleRate  float64  `yaml:"body_sample_rate" toml:"body_sample_rate" env:"LOG_BODY_SAMPLE_RATE" default:"1"`
+	} `yaml:"log" toml:"log"`
+	// Metrics of tasks, which end before Prometheus could scrape them, are exported when they finish
+	Metrics struct {
+		// PushgatewayURL is the Prometheus Pushgateway tasks push to, e.g. http://pushgateway:9091. Empty disables pushing.
+		PushgatewayURL string `yaml:"pushgateway_url" toml:"pushgateway_url" env:"METRICS_PUSHGATEWAY_URL"`
+		// TextfilePath is the .prom file tasks write, for the textfile collector of node-exporter. Empty disables it.
+		TextfilePath string `yaml:"textfile_path" toml:"textfile_path" env:"METRICS_TEXTFILE_PATH"`
+	} `yaml:"metrics" toml:"metrics"`
+	Tracing struct {
+		// Exporter is one of none, otlp, stdout or file. Empty disables tracing.
+		Exporter     string  `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER"`
+		OTLPEndpoint string  `yaml:"otlp_endpoint" toml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+		FilePath     string  `yaml:"file_path" toml:"file_path" env:"TRACING_FILE_PATH"`
+		ServiceName  string  `yaml:"service_name" toml:"service_name" env:"TRACING_SERVICE_NAME" default:"go-clean-starter"`
+		SampleRatio  float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" default:"1"`
+	} `yaml:"tracing" toml:"tracing"`
+	Outbox struct {
+		// Sinks lists where events are published: stdout, file, webhook, nats or webhooks (subscriptions)
+		Sinks      []string `yaml:"sinks" toml:"sinks" env:"OUTBOX_SINKS" default:"stdout"`
+		FilePath   string   `yaml:"file_path" toml:"file_path" env:"OUTBOX_FILE_PATH"`
+		WebhookURL string   `yaml:"webhook_url" toml:"webhook_url" env:"OUTBOX_WEBHOOK_URL" secret:"url"`
+		NATSURL    string   `yaml:"nats_url" toml:"nats_url" env:"OUTBOX_NATS_URL" secret:"url"`
+
+		NATSSubjectPrefix string `yaml:"nats_subject_prefix" toml:"nats_subject_prefix" env:"OUTBOX_NATS_SUBJECT_PREFIX" default:"events"`
+		BatchSize         int    `yaml:"batch_size" toml:"batch_size" env:"OUTBOX_BATCH_SIZE" default:"100"`
+		PollIntervalMs    int    `yaml:"poll_interval_ms" toml:"poll_interval_ms" env:"OUTBOX_POLL_INTERVAL_MS" default:"1000"`
+		// RetentionHours is how long published events are kept
+		RetentionHours int `yaml:"retention_hours" toml:"retention_hours" env:"OUTBOX_RETENTION_HOURS" default:"168"`
+		// MaxAttempts is how many times an event is published before it is dead-lettered
+		MaxAttempts int `yaml:"max_attempts" toml:"max_attempts" env:"OUTBOX_MAX_ATTEMPTS" default:"10"`
+		// BaseDelaySeconds is the delay before the first retry; later retries double it up to MaxDelaySeconds
+		BaseDelaySeconds int `yaml:"base_delay_seconds" toml:"base_delay_seconds" env:"OUTBOX_BASE_DELAY_SECONDS" default:"5"`
+		MaxDelaySeconds  int `yaml:"max_delay_seconds" toml:"max_delay_seconds" env:"OUTBOX_MAX_DELAY_SECONDS" default:"600"`
+		// ClaimTimeoutSeconds is how long a relay may publish the events it claimed before other relays claim them again
+		ClaimTimeoutSeconds int `yaml:"claim_timeout_seconds" toml:"claim_timeout_seconds" env:"OUTBOX_CLAIM_TIMEOUT_SECONDS" default:"60"`
+	} `yaml:"outbox" toml:"outbox"`
+	Webhook struct {
+		BatchSize      int `yaml:"batch_size" toml:"batch_size" env:"WEBHOOK_BATCH_SIZE" default:"50"`
+		PollIntervalMs int `yaml:"poll_interval_ms" toml:"poll_interval_ms" env:"WEBHOOK_POLL_INTERVAL_MS" default:"1000"`
+		// MaxAttempts is how many times a delivery is sent before it is marked failed
+		MaxAttempts int `yaml:"max_attempts" toml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" default:"10"`
+		// BaseDelaySeconds is the delay before the first retry; later retries double it up to MaxDelaySeconds
+		BaseDelaySeconds int `yaml:"base_delay_seconds" toml:"base_delay_seconds" env:"WEBHOOK_BASE_DELAY_SECONDS" default:"30"`
+		MaxDelaySeconds  int `yaml:"max_delay_seconds" toml:"max_delay_seconds" env:"WEBHOOK_MAX_DELAY_SECONDS" default:"21600"`
+		// DisableAfter is the number of consecutive failed attempts after which a subscription is disabled
+		DisableAfter int `yaml:"disable_after" toml:"disable_after" env:"WEBHOOK_DISABLE_AFTER" default:"50"`
+		// AllowPrivateNetworks lets subscriptions reach loopback, private and link-local addresses, such as a
+		// receiver on localhost during development. Keep it off in production, where it exposes internal services.
+		AllowPrivateNetworks bool `yaml:"allow_private_networks" toml:"allow_private_networks" env:"WEBHOOK_ALLOW_PRIVATE_NETWORKS" default:"false"`
+	} `yaml:"webhook" toml:"webhook"`
+	Cache struct {
+		// Backend caches users and items read by id: "none", "memory" for an LRU per process, or "redis" shared by replicas.
+		// memory only invalidates the entries of the process that wrote, so with several replicas of the API
+		// the others serve old versions until TTLSeconds pass; use it for a single replica only.
+		Backend string `yaml:"backend" toml:"backend" env:"CACHE_BACKEND" default:"none"`
+		// TTLSeconds is how long an entry is served before it is read from the database again
+		TTLSeconds int `yaml:"ttl_seconds" toml:"ttl_seconds" env:"CACHE_TTL_SECONDS" default:"60"`
+		// Size is the number of entries the memory backend keeps at most
+		Size int `yaml:"size" toml:"size" env:"CACHE_SIZE" default:"10000"`
+		// RedisURL is the Redis server of the redis backend, e.g. redis://localhost:6379/0
+		RedisURL string `yaml:"redis_url" toml:"redis_url" env:"CACHE_REDIS_URL" secret:"url"`
+		// Prefix is prepended to every Redis key, to share a server with other applications
+		Prefix string `yaml:"prefix" toml:"prefix" env:"CACHE_PREFIX" default:"go-clean-starter:"`
+	} `yaml:"cache" toml:"cache"`
+	Admin struct {
+		// ListenPort serves operational endpoints such as /metrics on a separate port, along with debug endpoints
+		// such as pprof. 0 serves /metrics and /admin on App.ListenPort and disables the debug endpoints.
+		ListenPort uint16 `yaml:"listen_port" toml:"listen_port" env:"ADMIN_LISTEN_PORT"`
+		// BindAddress is the IP address the admin port listens on. Empty listens on every interface.
+		BindAddress string `yaml:"bind_address" toml:"bind_address" env:"ADMIN_BIND_ADDRESS" default:"127.0.0.1"`
+		// Token authenticates requests to admin and debug endpoints, as a bearer token.
+		// Empty disables those endpoints.
+		Token string `yaml:"token" toml:"token" env:"ADMIN_TOKEN" secret:"true"`
+	} `yaml:"admin" toml:"admin"`
+	Secrets struct {
+		// KeyringPath is a local keyring written by `config keyring set`, which resolves references keyring://name
+		KeyringPath string `yaml:"keyring_path" toml:"keyring_path" env:"SECRETS_KEYRING_PATH"`
+		// KeyringKey is the base64 encoded key of the keyring, usually given by SECRETS_KEYRING_KEY_FILE
+		KeyringKey string `yaml:"keyring_key" toml:"keyring_key" env:"SECRETS_KEYRING_KEY" secret:"true"`
+		// RefreshSeconds reads the secrets from files and providers again at this interval. 0 disables refreshing.
+		RefreshSeconds int `yaml:"refresh_seconds" toml:"refresh_seconds" env:"SECRETS_REFRESH_SECONDS"`
+	} `yaml:"secrets" toml:"secrets"`
+
+	// secrets holds the current values of the secret fields
+	secrets *secretStore
+	// sources are the options Load was called with, to load the configuration again when reloading
+	sources []Option
+}
+
+// Option adds a source to Load
+type Option func(*options)
+
+type options struct {
+	file      string
+	overrides map[string]string
+	providers map[string]secret.Provider
+}
+
+// WithFile reads a YAML (.yaml, .yml) or TOML (.toml) file with the keys of Config, e.g.
+//
+//	app:
+//	  listen_port: 8080
+//
+// An empty path reads no file.
+func WithFile(path string) Option {
+	return func(o *options) {
+		o.file = path
 	}
 }
 
-func Load() (*Config, error) {
-	cnf := &Config{}
-	var err error
+// WithOverrides sets values by key, e.g. "app.listen_port", like command line flags.
+// Values are written like env vars.
+func WithOverrides(values map[string]string) Option {
+	return func(o *options) {
+		o.overrides = values
+	}
+}
 
-	// app
-	cnf.App.Env = os.Getenv("APP_ENV")
-	cnf.App.LogLevel = os.Getenv("APP_LOG_LEVEL")
-	listenPort, err := strconv.Atoi(os.Getenv("APP_LISTEN_PORT"))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get LISTEN_PORT: %w", err)
+// Load builds the configuration from the declared defaults, then the file, env vars and overrides,
+// each taking precedence over the previous ones. Empty env vars are ignored.
+// An env var can also be read from the file named by the same env var suffixed with _FILE, e.g. DB_PASSWORD_FILE.
+// Secret values that are references, e.g. keyring://db_password, are resolved through the secret providers.
+// Values that don't parse or don't validate are reported together in a *ValidationError.
+func Load(opts ...Option) (*Config, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
 	}
-	cnf.App.ListenPort = uint16(listenPort)
 
-	// database
-	cnf.DB.Host = os.Getenv("DB_HOST")
-	cnf.DB.Port, err = strconv.Atoi(os.Getenv("DB_PORT"))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get DB_PORT: %w", err)
+	cnf := &Config{secrets: newSecretStore(), sources: opts}
+	for _, f := range cnf.fields() {
+		if err := f.set(f.Default); err != nil {
+			return nil, fmt.Errorf("invalid default of %s: %w", f.Key, err)
+		}
 	}
-	cnf.DB.User = os.Getenv("DB_USER")
-	cnf.DB.Password = os.Getenv("DB_PASSWORD")
-	cnf.DB.Name = os.Getenv("DB_NAME")
-	cnf.DB.SSLMode = os.Getenv("PGSSLMODE")
 
-	// Add missing database connection pool configuration
-	cnf.DB.Connection.MinIdleConns, err = strconv.Atoi(os.Getenv("DB_MIN_IDLE_CONNS"))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get DB_MIN_IDLE_CONNS: %w", err)
+	if o.file != "" {
+		if err := cnf.readFile(o.file); err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", o.file, err)
+		}
 	}
-	cnf.DB.Connection.MaxOpen, err = strconv.Atoi(os.Getenv("DB_MAX_OPEN_CONNS"))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get DB_MAX_OPEN_CONNS: %w", err)
+
+	ctx := context.Background()
+	var problems []string
+	fields := map[string]Field{}
+	for _, f := range cnf.fields() {
+		fields[f.Key] = f
+		if f.Env == "" {
+			continue
+		}
+
+		v, path := os.Getenv(f.Env), os.Getenv(f.Env+"_FILE")
+		switch {
+		case v != "" && path != "":
+			problems = append(problems, fmt.Sprintf("%s: set only one of %s and %s_FILE", f.Env, f.Env, f.Env))
+			continue
+		case path != "":
+			src := readSecretFile(path)
+			var err error
+			if v, err = src(ctx); err != nil {
+				problems = append(problems, fmt.Sprintf("%s_FILE: %v", f.Env, err))
+				continue
+			}
+			if f.Secret != "" {
+				cnf.secrets.sources[f.Key] = src
+			}
+		case v == "":
+			continue
+		}
+
+		if err := f.set(v); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", f.Env, err))
+		}
 	}
-	cnf.DB.Connection.LifetimeSeconds, err = strconv.Atoi(os.Getenv("DB_CONN_LIFETIME_SECONDS"))
+
+	for _, key := range sortedKeys(o.overrides) {
+		f, ok := fields[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: unknown key", key))
+			continue
+		}
+		if err := f.set(o.overrides[key]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+		}
+		delete(cnf.secrets.sources, key)
+	}
+
+	problems = append(problems, cnf.resolveSecrets(ctx, o.providers)...)
+	if problems = append(problems, cnf.problems()...); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return cnf, nil
+}
+
+// resolveSecrets replaces references in secret values by the secrets they refer to,
+// then records the current values of all secret fields
+func (c *Config) resolveSecrets(ctx context.Context, registered map[string]secret.Provider) []string {
+	providers, err := c.secretProviders(registered)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get DB_CONN_LIFETIME_SECONDS: %w", err)
+		return []string{fmt.Sprintf("secrets.keyring_key: %v", err)}
+	}
+
+	var problems []string
+	for _, f := range c.fields() {
+		if f.Secret == "" {
+			continue
+		}
+
+		ref := f.value.String()
+		if scheme, name, ok := secret.ParseReference(ref); ok {
+			// other schemes are plain values, e.g. a NATS URL
+			if p, ok := providers[scheme]; ok {
+				src := readSecretReference(p, ref, name)
+				v, err := src(ctx)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %v", f.Key, err))
+					continue
+				}
+				f.value.SetString(v)
+				c.secrets.sources[f.Key] = src
+			}
+		}
+
+		c.secrets.values[f.Key] = f.value.String()
 	}
 
-	// http
-	// timeout seconds for http request
-	cnf.HTTP.TimeoutSeconds, err = strconv.Atoi(os.Getenv("HTTP_TIMEOUT_SECONDS"))
+	return problems
+}
+
+// readFile decodes a config file over the defaults. Unknown keys are errors, so that typos don't go unnoticed.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get HTTP_TIMEOUT_SECONDS: %w", err)
+		return err
 	}
 
-	return cnf, nil
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), c)
+		if err != nil {
+			return err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("unknown keys %v", undecoded)
+		}
+	default:
+		return fmt.Errorf("unsupported format %q, expected .yaml, .yml or .toml", ext)
+	}
+
+	return nil
+}
+
+// splitList parses a comma-separated env value, ignoring empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+// parseRateLimitRule parses "per_minute:burst". An empty value disables the rule.
+func parseRateLimitRule(v string) (RateLimitRule, error) {
+	if v == "" {
+		return RateLimitRule{}, nil
+	}
+
+	perMinute, burst, ok := strings.Cut(v, ":")
+	if !ok {
+		return RateLimitRule{}, fmt.Errorf("expected per_minute:burst, got %q", v)
+	}
+
+	var r RateLimitRule
+	var err error
+	if r.PerMinute, err = strconv.Atoi(perMinute); err != nil {
+		return RateLimitRule{}, err
+	}
+	if r.Burst, err = strconv.Atoi(burst); err != nil {
+		return RateLimitRule{}, err
+	}
+
+	return r, nil
+}
+
+// parseRateLimitGroups parses "name=per_minute:burst,...".
+func parseRateLimitGroups(v string) (map[string]RateLimitRule, error) {
+	groups := map[string]RateLimitRule{}
+	for _, g := range splitList(v) {
+		name, rule, ok := strings.Cut(g, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=per_minute:burst, got %q", g)
+		}
+
+		var err error
+		if groups[name], err = parseRateLimitRule(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
 }
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Change is a value that differs between the configuration in effect and a reloaded one
+type Change struct {
+	Key string `json:"key"`
+	// Old and New are printed values, masked for secrets
+	Old string `json:"old"`
+	New string `json:"new"`
+	// Applied is false for values that only change with a restart
+	Applied bool `json:"applied"`
+}
+
+// Reloader holds the configuration in effect and replaces it when the configuration is reloaded,
+// e.g. on SIGHUP after editing the config file. Only values tagged reload change at runtime;
+// readers of those values go through Current instead of keeping a *Config.
+type Reloader struct {
+	current atomic.Pointer[Config]
+	// mu serializes reloads
+	mu        sync.Mutex
+	listeners []func(prev, next *Config)
+}
+
+// NewReloader creates a reloader starting with c, which must come from Load
+func NewReloader(c *Config) *Reloader {
+	r := &Reloader{}
+	r.current.Store(c)
+
+	return r
+}
+
+// Current returns the configuration in effect
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// OnReload registers fn to be called with the previous and the new configuration after a reload applied changes.
+// Register listeners at startup, before the first Reload.
+func (r *Reloader) OnReload(fn func(prev, next *Config)) {
+	r.listeners = append(r.listeners, fn)
+}
+
+// Reload loads the configuration again from the sources of the current one and applies the changed
+// reloadable values. Every change is logged as an audit line naming the actor that asked for the reload.
+// When loading fails, the current configuration stays in effect.
+func (r *Reloader) Reload(actor string) ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.Current()
+	loaded, err := Load(current.sources...)
+	if err != nil {
+		return nil, err
+	}
+
+	next := *current
+	loadedFields := loaded.fields()
+
+	var changes []Change
+	applied := false
+	for i, f := range next.fields() {
+		l := loadedFields[i]
+		if fmt.Sprint(f.value.Interface()) == fmt.Sprint(l.value.Interface()) {
+			continue
+		}
+		// rotated secrets are picked up by the SecretRefresher, not by reloads
+		if current.secrets != nil {
+			if _, refreshed := current.secrets.sources[f.Key]; refreshed {
+				continue
+			}
+		}
+
+		change := Change{Key: f.Key, Old: f.display(), New: l.display(), Applied: f.Reload}
+		if f.Reload {
+			f.value.Set(l.value)
+			applied = true
+		}
+		changes = append(changes, change)
+
+		event := log.Info()
+		msg := "config changed"
+		if !change.Applied {
+			event = log.Warn()
+			msg = "config change ignored until restart"
+		}
+		event.Bool("audit", true).Str("actor", actor).Str("key", change.Key).Str("old", change.Old).Str("new", change.New).Msg(msg)
+	}
+
+	if applied {
+		r.current.Store(&next)
+		for _, fn := range r.listeners {
+			fn(current, &next)
+		}
+	}
+
+	return changes, nil
+}
+
+// display prints the value for logs and responses, masking secrets
+func (f Field) display() string {
+	v := fmt.Sprint(f.value.Interface())
+	if f.Secret == "" || v == "" {
+		return v
+	}
+	if f.Secret == "url" {
+		return maskURL(v)
+	}
+
+	return mask
+}
@@ -0,0 +1,79 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/config"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "config.yaml", `
+app:
+  log_level: info
+  listen_port: 8080
+rate_limit:
+  ip: {per_minute: 60, burst: 10}
+admin:
+  token: old-token
+`)
+	cnf, err := config.Load(config.WithFile(path))
+	require.NoError(t, err)
+
+	reloader := config.NewReloader(cnf)
+	var notified *config.Config
+	reloader.OnReload(func(prev, next *config.Config) {
+		assert.Equal(t, "info", prev.App.LogLevel)
+		notified = next
+	})
+
+	t.Run("unchanged file", func(t *testing.T) {
+		changes, err := reloader.Reload("test")
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+		assert.Nil(t, notified)
+	})
+
+	t.Run("invalid file keeps the current configuration", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("app:\n  log_level: loud\n"), 0o600))
+
+		_, err := reloader.Reload("test")
+		require.Error(t, err)
+		assert.Same(t, cnf, reloader.Current())
+	})
+
+	t.Run("reloadable values are applied, others wait for a restart", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`
+app:
+  log_level: debug
+  listen_port: 9090
+rate_limit:
+  ip: {per_minute: 120, burst: 20}
+admin:
+  token: new-token
+`), 0o600))
+
+		changes, err := reloader.Reload("test")
+		require.NoError(t, err)
+		assert.Equal(t, []config.Change{
+			{Key: "app.log_level", Old: "info", New: "debug", Applied: true},
+			{Key: "app.listen_port", Old: "8080", New: "9090", Applied: false},
+			{Key: "rate_limit.ip", Old: "{60 10}", New: "{120 20}", Applied: true},
+			{Key: "admin.token", Old: "********", New: "********", Applied: false},
+		}, changes)
+
+		current := reloader.Current()
+		assert.Equal(t, "debug", current.App.LogLevel)
+		assert.Equal(t, config.RateLimitRule{PerMinute: 120, Burst: 20}, current.RateLimit.IP)
+		assert.Equal(t, uint16(8080), current.App.ListenPort)
+		assert.Equal(t, "old-token", current.Admin.Token)
+		assert.Same(t, current, notified)
+
+		// the configuration the reloader started with is left untouched
+		assert.Equal(t, "info", cnf.App.LogLevel)
+	})
+}
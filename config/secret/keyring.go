@@ -0,0 +1,157 @@
+package secret
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// KeySize is the size of keyring keys: AES-256
+const KeySize = 32
+
+// Keyring is a local file of secrets encrypted at rest with AES-256-GCM, for machines without a secret manager.
+// Each secret is sealed on its own with its name as additional data, so that entries can't be swapped.
+// The file is read on every Get, so secrets changed with Set are picked up by running processes.
+type Keyring struct {
+	path string
+	aead cipher.AEAD
+	// mu serializes Set within the process
+	mu sync.Mutex
+}
+
+// keyringFile is the JSON layout of a keyring file
+type keyringFile struct {
+	// Secrets maps names to the base64 of nonce followed by ciphertext
+	Secrets map[string]string `json:"secrets"`
+}
+
+// NewKeyring opens the keyring at path with a KeySize key. The file is created by the first Set.
+func NewKeyring(path string, key []byte) (*Keyring, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("keyring key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keyring{path: path, aead: aead}, nil
+}
+
+// GenerateKey returns a random key, base64 encoded like ParseKey expects
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey decodes a base64 encoded key
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("keyring key must be base64 encoded: %w", err)
+	}
+
+	return key, nil
+}
+
+// Get implements Provider
+func (k *Keyring) Get(_ context.Context, name string) (string, error) {
+	f, err := k.read()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, ok := f.Secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < k.aead.NonceSize() {
+		return "", fmt.Errorf("keyring entry %s is corrupt", name)
+	}
+	nonce, ciphertext := data[:k.aead.NonceSize()], data[k.aead.NonceSize():]
+	plaintext, err := k.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt keyring entry %s, is the key right?: %w", name, err)
+	}
+
+	return string(plaintext), nil
+}
+
+// Set encrypts and stores a secret, replacing the file atomically
+func (k *Keyring) Set(name, value string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	f, err := k.read()
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	f.Secrets[name] = base64.StdEncoding.EncodeToString(k.aead.Seal(nonce, nonce, []byte(value), []byte(name)))
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(k.path), filepath.Base(k.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), k.path)
+}
+
+func (k *Keyring) read() (*keyringFile, error) {
+	f := &keyringFile{Secrets: map[string]string{}}
+
+	data, err := os.ReadFile(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring %s: %w", k.path, err)
+	}
+	if f.Secrets == nil {
+		f.Secrets = map[string]string{}
+	}
+
+	return f, nil
+}
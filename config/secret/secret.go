@@ -0,0 +1,61 @@
+// Package secret resolves secrets stored outside of the configuration, so that they are not
+// passed around as plain env vars.
+//
+// A secret value of the configuration can be a reference of the form scheme://name, which is resolved
+// by the Provider registered for the scheme, e.g. keyring://db_password.
+// Cloud secret managers can be added by implementing Provider for a scheme of their own.
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNotFound is returned by providers for a secret that does not exist
+var ErrNotFound = errors.New("secret not found")
+
+// Provider reads secrets by name from a store
+type Provider interface {
+	// Get returns the current value of the secret called name.
+	// It is called again on every refresh, so it must not cache values.
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// ProviderFunc adapts a function to Provider
+type ProviderFunc func(ctx context.Context, name string) (string, error)
+
+// Get implements Provider
+func (f ProviderFunc) Get(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// ParseReference splits a reference into its scheme and name, e.g. "keyring://db_password" into "keyring" and "db_password".
+// It returns false for values that are not references.
+func ParseReference(v string) (scheme, name string, ok bool) {
+	scheme, name, ok = strings.Cut(v, "://")
+	if !ok || scheme == "" || name == "" {
+		return "", "", false
+	}
+
+	return scheme, name, true
+}
+
+// File reads secrets from files, such as Docker and Kubernetes secrets. The name is the path,
+// e.g. file:///run/secrets/db_password.
+type File struct{}
+
+// Get implements Provider. A trailing newline is not part of the secret.
+func (File) Get(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,121 @@
+package secret_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/config/secret"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		value          string
+		expectedScheme string
+		expectedName   string
+		expectedOK     bool
+	}{
+		{value: "keyring://db_password", expectedScheme: "keyring", expectedName: "db_password", expectedOK: true},
+		{value: "file:///run/secrets/db_password", expectedScheme: "file", expectedName: "/run/secrets/db_password", expectedOK: true},
+		{value: "example", expectedOK: false},
+		{value: "keyring://", expectedOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			scheme, name, ok := secret.ParseReference(tt.value)
+			assert.Equal(t, tt.expectedScheme, scheme)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db_password")
+	require.NoError(t, os.WriteFile(path, []byte("s3cret\n"), 0o600))
+
+	v, err := secret.File{}.Get(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", v)
+
+	_, err = secret.File{}.Get(context.Background(), path+".missing")
+	assert.ErrorIs(t, err, secret.ErrNotFound)
+}
+
+func newKeyring(t *testing.T, path string) *secret.Keyring {
+	t.Helper()
+
+	encoded, err := secret.GenerateKey()
+	require.NoError(t, err)
+	key, err := secret.ParseKey(encoded)
+	require.NoError(t, err)
+	keyring, err := secret.NewKeyring(path, key)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestKeyring(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("set and get", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keyring.json")
+		keyring := newKeyring(t, path)
+
+		_, err := keyring.Get(ctx, "db_password")
+		assert.ErrorIs(t, err, secret.ErrNotFound)
+
+		require.NoError(t, keyring.Set("db_password", "s3cret"))
+		require.NoError(t, keyring.Set("api_token", "t0ken"))
+		require.NoError(t, keyring.Set("db_password", "rotated"))
+
+		v, err := keyring.Get(ctx, "db_password")
+		require.NoError(t, err)
+		assert.Equal(t, "rotated", v)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "rotated", "secrets are encrypted at rest")
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keyring.json")
+		require.NoError(t, newKeyring(t, path).Set("db_password", "s3cret"))
+
+		_, err := newKeyring(t, path).Get(ctx, "db_password")
+		assert.ErrorContains(t, err, "failed to decrypt")
+	})
+
+	t.Run("entries can't be swapped", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keyring.json")
+		keyring := newKeyring(t, path)
+		require.NoError(t, keyring.Set("db_password", "s3cret"))
+		require.NoError(t, keyring.Set("readonly_password", "public"))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var f map[string]map[string]string
+		require.NoError(t, json.Unmarshal(data, &f))
+		f["secrets"]["db_password"] = f["secrets"]["readonly_password"]
+		data, err = json.Marshal(f)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		_, err = keyring.Get(ctx, "db_password")
+		assert.ErrorContains(t, err, "failed to decrypt")
+	})
+
+	t.Run("key size", func(t *testing.T) {
+		_, err := secret.NewKeyring("keyring.json", []byte("short"))
+		assert.ErrorContains(t, err, "32 bytes")
+	})
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/SoraDaibu/go-clean-starter/config/secret"
+)
+
+// secretSource reads the current value of a secret
+type secretSource func(ctx context.Context) (string, error)
+
+// secretStore holds the current values of the secret fields, which change when they are refreshed
+type secretStore struct {
+	mu     sync.RWMutex
+	values map[string]string
+	// sources are the secrets read from files or providers, which can be read again
+	sources map[string]secretSource
+}
+
+func newSecretStore() *secretStore {
+	return &secretStore{values: map[string]string{}, sources: map[string]secretSource{}}
+}
+
+// WithSecretProvider registers a provider for references of the form scheme://name in secret values,
+// e.g. a cloud secret manager. The file and keyring schemes are built in.
+func WithSecretProvider(scheme string, p secret.Provider) Option {
+	return func(o *options) {
+		if o.providers == nil {
+			o.providers = map[string]secret.Provider{}
+		}
+		o.providers[scheme] = p
+	}
+}
+
+// secretProviders returns the providers of references: file, keyring when configured and the registered ones
+func (c *Config) secretProviders(registered map[string]secret.Provider) (map[string]secret.Provider, error) {
+	providers := map[string]secret.Provider{"file": secret.File{}}
+
+	// a missing key is reported by validation
+	if c.Secrets.KeyringPath != "" && c.Secrets.KeyringKey != "" {
+		key, err := secret.ParseKey(c.Secrets.KeyringKey)
+		if err != nil {
+			return nil, err
+		}
+		keyring, err := secret.NewKeyring(c.Secrets.KeyringPath, key)
+		if err != nil {
+			return nil, err
+		}
+		providers["keyring"] = keyring
+	}
+
+	for scheme, p := range registered {
+		providers[scheme] = p
+	}
+
+	return providers, nil
+}
+
+// Secret returns the current value of a secret, e.g. "db.password".
+// Unlike the field, it reflects the refreshes of a SecretRefresher, so read it where rotation matters,
+// e.g. when opening a connection.
+func (c *Config) Secret(key string) string {
+	if c.secrets != nil {
+		c.secrets.mu.RLock()
+		v, ok := c.secrets.values[key]
+		c.secrets.mu.RUnlock()
+		if ok {
+			return v
+		}
+	}
+
+	for _, f := range c.fields() {
+		if f.Key == key {
+			return f.value.String()
+		}
+	}
+
+	return ""
+}
+
+// refreshSecrets reads the secrets from files and providers again.
+// A secret that fails to read keeps its previous value.
+func (c *Config) refreshSecrets(ctx context.Context) {
+	if c.secrets == nil {
+		return
+	}
+
+	c.secrets.mu.RLock()
+	sources := make(map[string]secretSource, len(c.secrets.sources))
+	for key, src := range c.secrets.sources {
+		sources[key] = src
+	}
+	c.secrets.mu.RUnlock()
+
+	for key, src := range sources {
+		v, err := src(ctx)
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("failed to refresh secret, keeping the previous value")
+			continue
+		}
+
+		c.secrets.mu.Lock()
+		if c.secrets.values[key] != v {
+			c.secrets.values[key] = v
+			log.Info().Str("key", key).Msg("secret rotated")
+		}
+		c.secrets.mu.Unlock()
+	}
+}
+
+// SecretRefresher periodically reads the secrets of a Config from files and providers again,
+// so that rotated secrets are used without a restart.
+type SecretRefresher struct {
+	config   *Config
+	interval time.Duration
+
+	started atomic.Bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewSecretRefresher creates a refresher of the secrets of c
+func NewSecretRefresher(c *Config, interval time.Duration) *SecretRefresher {
+	return &SecretRefresher{
+		config:   c,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Run refreshes the secrets every interval until Close
+func (r *SecretRefresher) Run() {
+	r.started.Store(true)
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-r.stop
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.config.refreshSecrets(ctx)
+		}
+	}
+}
+
+// Close stops Run. It is safe to call on a nil refresher.
+func (r *SecretRefresher) Close() {
+	if r == nil {
+		return
+	}
+
+	close(r.stop)
+	if r.started.Load() {
+		<-r.done
+	}
+}
+
+// readSecretFile is the source of a value given by a *_FILE env var
+func readSecretFile(path string) secretSource {
+	return func(ctx context.Context) (string, error) {
+		v, err := secret.File{}.Get(ctx, path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		return v, nil
+	}
+}
+
+// readSecretReference is the source of a value given by a reference to a provider
+func readSecretReference(p secret.Provider, ref, name string) secretSource {
+	return func(ctx context.Context) (string, error) {
+		v, err := p.Get(ctx, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", ref, err)
+		}
+
+		return v, nil
+	}
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ValidationError reports every invalid value of a configuration at once
+type ValidationError struct {
+	// Problems are prefixed with the key or env var of the value, e.g. "app.log_level: must be one of ..."
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Validate checks the values of c. It returns a *ValidationError listing every problem.
+func (c *Config) Validate() error {
+	if problems := c.problems(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+func (c *Config) problems() []string {
+	var v validator
+
+	// app
+	v.oneOf("app.env", c.App.Env, "local", "development", "staging", "production")
+	v.oneOf("app.log_level", c.App.LogLevel, "debug", "info", "warn", "warning", "error")
+	v.port("app.listen_port", int(c.App.ListenPort))
+	v.atLeast("app.shutdown_timeout_seconds", c.App.ShutdownTimeoutSeconds, 1)
+
+	// database
+	v.required("db.host", c.DB.Host)
+	v.port("db.port", c.DB.Port)
+	v.required("db.user", c.DB.User)
+	v.required("db.name", c.DB.Name)
+	v.oneOf("db.sslmode", c.DB.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	v.atLeast("db.connection.min_idle_conns", c.DB.Connection.MinIdleConns, 0)
+	v.atLeast("db.connection.max_open", c.DB.Connection.MaxOpen, 1)
+	if c.DB.Connection.MinIdleConns > c.DB.Connection.MaxOpen {
+		v.add("db.connection.min_idle_conns", "must not exceed db.connection.max_open (%d), got %d", c.DB.Connection.MaxOpen, c.DB.Connection.MinIdleConns)
+	}
+	v.atLeast("db.connection.lifetime_seconds", c.DB.Connection.LifetimeSeconds, 1)
+	v.atLeast("db.replica.stickiness_ms", c.DB.Replica.StickinessMs, 0)
+	v.atLeast("db.replica.health_check_seconds", c.DB.Replica.HealthCheckSeconds, 1)
+	v.atLeast("db.replica.max_lag_seconds", c.DB.Replica.MaxLagSeconds, 0)
+
+	// http
+	v.atLeast("http.timeout_seconds", c.HTTP.TimeoutSeconds, 1)
+	v.atLeast("http.max_attempts", c.HTTP.MaxAttempts, 1)
+	v.atLeast("http.retry_base_delay_ms", c.HTTP.RetryBaseDelayMs, 1)
+	if c.HTTP.RetryBaseDelayMs > c.HTTP.RetryMaxDelayMs {
+		v.add("http.retry_base_delay_ms", "must not exceed http.retry_max_delay_ms (%d), got %d", c.HTTP.RetryMaxDelayMs, c.HTTP.RetryBaseDelayMs)
+	}
+	v.atLeast("http.breaker_threshold", c.HTTP.BreakerThreshold, 0)
+	v.atLeast("http.breaker_cooldown_seconds", c.HTTP.BreakerCooldownSeconds, 1)
+	v.atLeast("http.max_idle_conns", c.HTTP.MaxIdleConns, 0)
+	v.atLeast("http.max_idle_conns_per_host", c.HTTP.MaxIdleConnsPerHost, 0)
+	v.atLeast("http.max_conns_per_host", c.HTTP.MaxConnsPerHost, 0)
+	v.atLeast("http.idle_conn_timeout_seconds", c.HTTP.IdleConnTimeoutSeconds, 0)
+	v.atLeast("http.request_timeout_seconds", c.HTTP.RequestTimeoutSeconds, 0)
+	for _, origin := range c.HTTP.CORSOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			v.add("http.cors_origins", "must be * or start with http:// or https://, got %q", origin)
+		}
+	}
+	for _, cidr := range c.HTTP.TrustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			v.add("http.trusted_proxies", "must be CIDRs such as 10.0.0.0/8, got %q", cidr)
+		}
+	}
+	v.atLeast("idempotency.ttl_hours", c.Idempotency.TTLHours, 1)
+	v.atLeast("idempotency.lock_timeout_seconds", c.Idempotency.LockTimeoutSeconds, 1)
+	if c.HTTP.RequestTimeoutSeconds > 0 && c.Idempotency.LockTimeoutSeconds <= c.HTTP.RequestTimeoutSeconds {
+		v.add("idempotency.lock_timeout_seconds", "must exceed http.request_timeout_seconds (%d), got %d", c.HTTP.RequestTimeoutSeconds, c.Idempotency.LockTimeoutSeconds)
+	}
+
+	// rate limit
+	v.oneOf("rate_limit.store", c.RateLimit.Store, "memory", "postgres")
+	v.rateLimitRule("rate_limit.ip", c.RateLimit.IP)
+	v.rateLimitRule("rate_limit.user", c.RateLimit.User)
+	for _, name := range sortedKeys(c.RateLimit.Groups) {
+		v.rateLimitRule("rate_limit.groups."+name, c.RateLimit.Groups[name])
+	}
+
+	// log
+	v.atLeast("log.body_max_bytes", c.Log.BodyMaxBytes, 0)
+	v.ratio("log.body_sample_rate", c.Log.BodySampleRate)
+
+	// metrics
+	if c.Metrics.TextfilePath != "" && filepath.Ext(c.Metrics.TextfilePath) != ".prom" {
+		v.add("metrics.textfile_path", "must end with .prom to be read by node-exporter, got %s", c.Metrics.TextfilePath)
+	}
+
+	// tracing
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "", "none", "otlp", "stdout", "file")
+	if c.Tracing.Exporter == "file" {
+		v.required("tracing.file_path", c.Tracing.FilePath)
+	}
+	v.ratio("tracing.sample_ratio", c.Tracing.SampleRatio)
+
+	// outbox
+	for _, sink := range c.Outbox.Sinks {
+		v.oneOf("outbox.sinks", sink, "stdout", "file", "webhook", "nats", "webhooks")
+		switch sink {
+		case "file":
+			v.required("outbox.file_path", c.Outbox.FilePath)
+		case "webhook":
+			v.required("outbox.webhook_url", c.Outbox.WebhookURL)
+		case "nats":
+			v.required("outbox.nats_url", c.Outbox.NATSURL)
+		}
+	}
+	v.atLeast("outbox.batch_size", c.Outbox.BatchSize, 1)
+	v.atLeast("outbox.poll_interval_ms", c.Outbox.PollIntervalMs, 1)
+	v.atLeast("outbox.retention_hours", c.Outbox.RetentionHours, 1)
+	v.atLeast("outbox.max_attempts", c.Outbox.MaxAttempts, 1)
+	v.atLeast("outbox.base_delay_seconds", c.Outbox.BaseDelaySeconds, 1)
+	if c.Outbox.BaseDelaySeconds > c.Outbox.MaxDelaySeconds {
+		v.add("outbox.base_delay_seconds", "must not exceed outbox.max_delay_seconds (%d), got %d", c.Outbox.MaxDelaySeconds, c.Outbox.BaseDelaySeconds)
+	}
+	v.atLeast("outbox.claim_timeout_seconds", c.Outbox.ClaimTimeoutSeconds, 1)
+
+	// webhook
+	v.atLeast("webhook.batch_size", c.Webhook.BatchSize, 1)
+	v.atLeast("webhook.poll_interval_ms", c.Webhook.PollIntervalMs, 1)
+	v.atLeast("webhook.max_attempts", c.Webhook.MaxAttempts, 1)
+	v.atLeast("webhook.base_delay_seconds", c.Webhook.BaseDelaySeconds, 1)
+	if c.Webhook.BaseDelaySeconds > c.Webhook.MaxDelaySeconds {
+		v.add("webhook.base_delay_seconds", "must not exceed webhook.max_delay_seconds (%d), got %d", c.Webhook.MaxDelaySeconds, c.Webhook.BaseDelaySeconds)
+	}
+	v.atLeast("webhook.disable_after", c.Webhook.DisableAfter, 1)
+
+	// cache
+	v.oneOf("cache.backend", c.Cache.Backend, "none", "memory", "redis")
+	if c.Cache.Backend != "none" {
+		v.atLeast("cache.ttl_seconds", c.Cache.TTLSeconds, 1)
+	}
+	if c.Cache.Backend == "memory" {
+		v.atLeast("cache.size", c.Cache.Size, 1)
+	}
+	if c.Cache.Backend == "redis" {
+		v.required("cache.redis_url", c.Cache.RedisURL)
+	}
+
+	// admin
+	if c.Admin.ListenPort != 0 && c.Admin.ListenPort == c.App.ListenPort {
+		v.add("admin.listen_port", "must differ from app.listen_port, got %d", c.Admin.ListenPort)
+	}
+	if c.Admin.BindAddress != "" && net.ParseIP(c.Admin.BindAddress) == nil {
+		v.add("admin.bind_address", "must be an IP address, got %q", c.Admin.BindAddress)
+	}
+
+	// secrets
+	if c.Secrets.KeyringPath != "" {
+		v.required("secrets.keyring_key", c.Secrets.KeyringKey)
+	}
+	v.atLeast("secrets.refresh_seconds", c.Secrets.RefreshSeconds, 0)
+
+	return v.problems
+}
+
+// validator collects problems instead of stopping at the first one
+type validator struct {
+	problems []string
+}
+
+func (v *validator) add(key, format string, args ...any) {
+	v.problems = append(v.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) required(key, value string) {
+	if value == "" {
+		v.add(key, "is required")
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.add(key, "must be one of %s, got %q", strings.Join(slices.DeleteFunc(allowed, func(s string) bool { return s == "" }), ", "), value)
+	}
+}
+
+func (v *validator) port(key string, port int) {
+	if port < 1 || port > 65535 {
+		v.add(key, "must be a port between 1 and 65535, got %d", port)
+	}
+}
+
+func (v *validator) atLeast(key string, n, least int) {
+	if n < least {
+		v.add(key, "must be at least %d, got %d", least, n)
+	}
+}
+
+func (v *validator) ratio(key string, r float64) {
+	if r < 0 || r > 1 {
+		v.add(key, "must be between 0 and 1, got %g", r)
+	}
+}
+
+func (v *validator) rateLimitRule(key string, r RateLimitRule) {
+	if r.PerMinute < 0 || r.Burst < 0 {
+		v.add(key, "must not be negative, got %d:%d", r.PerMinute, r.Burst)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrVersionConflict is matched by VersionConflictError with errors.Is
+var ErrVersionConflict = errors.New("version conflict")
+
+// VersionConflictError is returned when an entity was changed since it was read,
+// so writing it would overwrite someone else's changes.
+type VersionConflictError struct {
+	Entity  string
+	ID      uuid.UUID
+	Version int32
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s %s was modified: version %d is stale", e.Entity, e.ID, e.Version)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+var (
+	// ErrNotFound is matched by NotFoundError with errors.Is
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is matched by ConflictError with errors.Is
+	ErrConflict = errors.New("conflict")
+)
+
+// NotFoundError is returned when an entity does not exist or is soft deleted.
+// Field and Value tell how it was looked up, e.g. by "id"; Value is empty when not known.
+type NotFoundError struct {
+	Entity string
+	Field  string
+	Value  string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Field == "" || e.Value == "" {
+		return fmt.Sprintf("%s not found", e.Entity)
+	}
+
+	return fmt.Sprintf("%s with %s %s not found", e.Entity, e.Field, e.Value)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ConflictError is returned when writing an entity would break a uniqueness rule,
+// e.g. a second active user with the same email. Field is empty when the rule spans several fields.
+type ConflictError struct {
+	Entity string
+	Field  string
+}
+
+func (e *ConflictError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s already exists", e.Entity)
+	}
+
+	return fmt.Sprintf("%s with this %s already exists", e.Entity, e.Field)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
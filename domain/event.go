@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types raised by aggregates
+const (
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+	EventItemCreated = "item.created"
+	EventItemUpdated = "item.updated"
+	EventItemDeleted = "item.deleted"
+)
+
+// EventTypes lists every event type raised by aggregates
+var EventTypes = []string{
+	EventUserCreated,
+	EventUserUpdated,
+	EventUserDeleted,
+	EventItemCreated,
+	EventItemUpdated,
+	EventItemDeleted,
+}
+
+// Event is something that happened to an aggregate that other services may react to.
+// Repositories save raised events to the outbox in the same transaction as the aggregate.
+type Event struct {
+	Type          string
+	AggregateType string
+	AggregateID   uuid.UUID
+	// Payload is marshalled to JSON when published
+	Payload    any
+	OccurredAt time.Time
+}
+
+type UserPayload struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name,omitempty"`
+	Email string    `json:"email,omitempty"`
+}
+
+type ItemPayload struct {
+	ID     uuid.UUID `json:"id"`
+	TypeID uint      `json:"type_id,omitempty"`
+	Name   string    `json:"name,omitempty"`
+}
+
+// events collects the events raised by an aggregate until they are saved
+type events struct {
+	pending []Event
+}
+
+func (e *events) raise(eventType, aggregateType string, aggregateID uuid.UUID, payload any) {
+	e.pending = append(e.pending, Event{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       payload,
+		OccurredAt:    time.Now(),
+	})
+}
+
+// PullEvents returns the events raised since the last call, in the order they were raised
+func (e *events) PullEvents() []Event {
+	pending := e.pending
+	e.pending = nil
+	return pending
+}
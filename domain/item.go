@@ -1,16 +1,40 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type Item struct {
-	id     uuid.UUID
-	typeID uint
+	events
+
+	id          uuid.UUID
+	typeID      uint
+	name        string
+	description string
+	version     int32
+	deletedAt   *time.Time
+}
+
+// ItemQuerySchema whitelists the fields items can be listed by
+var ItemQuerySchema = QuerySchema{
+	Fields: map[string]QueryField{
+		"id":          {Type: FieldUUID},
+		"type_id":     {Type: FieldInt, Ops: []Op{OpEq, OpNe, OpIn}},
+		"name":        {Type: FieldString, Sortable: true},
+		"description": {Type: FieldString, Ops: []Op{OpContains}},
+		"created_at":  {Type: FieldTime, Sortable: true},
+		"updated_at":  {Type: FieldTime, Sortable: true},
+	},
+	DefaultSort: []SortField{{Field: "created_at", Desc: true}},
 }
 
-func NewItem(typeID uint) *Item {
-	return &Item{id: uuid.New(), typeID: typeID}
+func NewItem(typeID uint, name string, description string) *Item {
+	item := &Item{id: uuid.New(), typeID: typeID, name: name, description: description, version: 1}
+	item.raise(EventItemCreated, "item", item.id, ItemPayload{ID: item.id, TypeID: typeID, Name: name})
+
+	return item
 }
 
 func (i *Item) ID() uuid.UUID {
@@ -21,13 +45,56 @@ func (i *Item) TypeID() uint {
 	return i.typeID
 }
 
+func (i *Item) Name() string {
+	return i.name
+}
+
+func (i *Item) Description() string {
+	return i.description
+}
+
 func (i *Item) SetTypeID(typeID uint) {
+	if i.typeID == typeID {
+		return
+	}
+
 	i.typeID = typeID
+	i.raise(EventItemUpdated, "item", i.id, ItemPayload{ID: i.id, TypeID: typeID})
+}
+
+// Delete marks the item as soft deleted
+func (i *Item) Delete() {
+	now := time.Now()
+	i.deletedAt = &now
+	i.raise(EventItemDeleted, "item", i.id, ItemPayload{ID: i.id})
+}
+
+// Version is incremented by every update and used for optimistic concurrency control
+func (i *Item) Version() int32 {
+	return i.version
+}
+
+// DeletedAt is set while the item is soft deleted
+func (i *Item) DeletedAt() *time.Time {
+	return i.deletedAt
+}
+
+// CheckVersion returns a VersionConflictError unless the item is at the expected version
+func (i *Item) CheckVersion(expected int32) error {
+	if i.version != expected {
+		return &VersionConflictError{Entity: "item", ID: i.id, Version: expected}
+	}
+
+	return nil
 }
 
-func ItemFromSource(id uuid.UUID, typeID uint) *Item {
+func ItemFromSource(id uuid.UUID, typeID uint, name string, description string, version int32, deletedAt *time.Time) *Item {
 	return &Item{
-		id:     id,
-		typeID: typeID,
+		id:          id,
+		typeID:      typeID,
+		name:        name,
+		description: description,
+		version:     version,
+		deletedAt:   deletedAt,
 	}
 }
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ItemSort orders item search results
+type ItemSort string
+
+const (
+	// ItemSortRelevance puts the best matches first
+	ItemSortRelevance ItemSort = "relevance"
+	// ItemSortNewest puts the most recently created items first
+	ItemSortNewest ItemSort = "-created_at"
+	// ItemSortOldest puts the first created items first
+	ItemSortOldest ItemSort = "created_at"
+	// ItemSortName orders items by name
+	ItemSortName ItemSort = "name"
+)
+
+// ItemSorts are the valid ItemSort values
+var ItemSorts = []ItemSort{ItemSortRelevance, ItemSortNewest, ItemSortOldest, ItemSortName}
+
+// ItemSearch finds active items by text in their name or description
+type ItemSearch struct {
+	Text string
+	// TypeIDs restricts the results to items of these types; empty matches every type
+	TypeIDs []uint
+	// CreatedFrom and CreatedTo restrict the results to items created in [CreatedFrom, CreatedTo); zero is unbounded
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Sort        ItemSort
+	// After is the last result of the previous page, nil for the first page
+	After *ItemSearchKey
+	Limit int
+}
+
+// ItemSearchKey positions a result in the order of an ItemSort, to continue after it on the next page.
+// Only the fields of the sort are set, along with ID to break ties.
+type ItemSearchKey struct {
+	ID        uuid.UUID
+	Score     float32
+	CreatedAt time.Time
+	Name      string
+}
+
+// ItemSearchResult is an item matching an ItemSearch
+type ItemSearchResult struct {
+	Item      *Item
+	CreatedAt time.Time
+	// Score is the relevance of the item, higher is better
+	Score float32
+	// NameHighlight and DescriptionHighlight are the name and excerpts of the description as HTML,
+	// escaped, with the matching words wrapped in <mark> and </mark>
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+// Key returns the position of the result in the order of sort
+func (r *ItemSearchResult) Key(sort ItemSort) *ItemSearchKey {
+	key := &ItemSearchKey{ID: r.Item.ID()}
+	switch sort {
+	case ItemSortRelevance:
+		key.Score = r.Score
+	case ItemSortNewest, ItemSortOldest:
+		key.CreatedAt = r.CreatedAt
+	case ItemSortName:
+		key.Name = r.Item.Name()
+	}
+
+	return key
+}
@@ -0,0 +1,259 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Op compares a field to a value in a Condition
+type Op string
+
+const (
+	OpEq  Op = "eq"
+	OpNe  Op = "ne"
+	OpGt  Op = "gt"
+	OpGte Op = "gte"
+	OpLt  Op = "lt"
+	OpLte Op = "lte"
+	// OpContains matches strings containing the value, ignoring case
+	OpContains Op = "contains"
+	// OpPrefix matches strings starting with the value, ignoring case
+	OpPrefix Op = "prefix"
+	// OpIn matches any value of a list
+	OpIn Op = "in"
+)
+
+// FieldType is the type of the values of a QueryField.
+// Values are string, int64, time.Time and uuid.UUID respectively.
+type FieldType int
+
+const (
+	FieldString FieldType = iota
+	// FieldInt values are limited to 32 bits, like INTEGER columns
+	FieldInt
+	FieldTime
+	FieldUUID
+)
+
+// defaultOps are the operators allowed on a field that doesn't list its own
+var defaultOps = map[FieldType][]Op{
+	FieldString: {OpEq, OpNe, OpContains, OpPrefix, OpIn},
+	FieldInt:    {OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn},
+	FieldTime:   {OpGt, OpGte, OpLt, OpLte},
+	FieldUUID:   {OpEq, OpNe, OpIn},
+}
+
+// Parse parses a value of the type from its text, as in a query parameter or a cursor
+func (t FieldType) Parse(s string) (any, error) {
+	switch t {
+	case FieldString:
+		return s, nil
+	case FieldInt:
+		v, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return v, nil
+	case FieldTime:
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, errors.New("must be an RFC 3339 timestamp")
+		}
+		return v, nil
+	case FieldUUID:
+		v, err := uuid.Parse(s)
+		if err != nil {
+			return nil, errors.New("must be a UUID")
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("unknown field type %d", t)
+}
+
+// Format is the inverse of Parse
+func (t FieldType) Format(v any) string {
+	if tm, ok := v.(time.Time); ok {
+		return tm.UTC().Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// QueryField is a field clients can filter by
+type QueryField struct {
+	Type FieldType
+	// Ops are the operators allowed on the field; nil allows the default ones of Type
+	Ops []Op
+	// Sortable lets clients sort by the field.
+	// Only set it on NOT NULL columns, as pages continue after the values of the last entity.
+	Sortable bool
+}
+
+// AllowedOps returns the operators allowed on the field
+func (f QueryField) AllowedOps() []Op {
+	if f.Ops != nil {
+		return f.Ops
+	}
+
+	return defaultOps[f.Type]
+}
+
+// QuerySchema whitelists the fields clients can filter and sort a list of entities by, by their API name.
+// Fields that are not in a schema can't be queried, whatever the store can do.
+type QuerySchema struct {
+	Fields map[string]QueryField
+	// DefaultSort applies to queries without a sort
+	DefaultSort []SortField
+}
+
+// FieldNames returns the names of the fields, sorted, that pass keep
+func (s QuerySchema) FieldNames(keep func(QueryField) bool) []string {
+	var names []string
+	for name, field := range s.Fields {
+		if keep(field) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Condition matches the entities whose Field compares to Value with Op.
+// Value has the Go type of the FieldType of Field, or is a []any of them for OpIn.
+type Condition struct {
+	Field string
+	Op    Op
+	Value any
+}
+
+// SortField orders entities by a field
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+func (s SortField) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+
+	return s.Field
+}
+
+// Query filters, sorts and pages a list of entities.
+// Its fields and operators are checked against a QuerySchema when it is parsed.
+type Query struct {
+	// Filters all have to match
+	Filters []Condition
+	Sort    []SortField
+	// After holds the values of Sort of the last entity of the previous page, followed by its ID; nil for the first page
+	After []any
+	Limit int
+}
+
+// Page is a page of entities matching a Query
+type Page[T any] struct {
+	Items []*T
+	// Next is the After of the next page, nil on the last page
+	Next []any
+}
+
+// ErrInvalidCursor is returned for cursors that were not made by EncodeCursor for the same sort
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// EncodeCursor makes the After of the next page of a query sorted by sort opaque to clients
+func (s QuerySchema) EncodeCursor(sort []SortField, after []any) string {
+	c := cursor{Sort: sortString(sort), Values: make([]string, len(after))}
+	for i, v := range after {
+		// the last value is the ID
+		t := FieldUUID
+		if i < len(sort) {
+			t = s.Fields[sort[i].Field].Type
+		}
+		c.Values[i] = t.Format(v)
+	}
+
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor returns the After encoded by EncodeCursor, nil for an empty cursor.
+// It returns ErrInvalidCursor when the cursor was made for another sort.
+func (s QuerySchema) DecodeCursor(sort []SortField, encoded string) ([]any, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sortString(sort) || len(c.Values) != len(sort)+1 {
+		return nil, ErrInvalidCursor
+	}
+
+	after := make([]any, len(c.Values))
+	for i, text := range c.Values {
+		t := FieldUUID
+		if i < len(sort) {
+			t = s.Fields[sort[i].Field].Type
+		}
+		if after[i], err = t.Parse(text); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return after, nil
+}
+
+func sortString(sort []SortField) string {
+	fields := make([]string, len(sort))
+	for i, s := range sort {
+		fields[i] = s.String()
+	}
+
+	return strings.Join(fields, ",")
+}
+
+// FieldError is an invalid field of a request, such as a query parameter, or an invalid request as a whole
+// when Field is empty
+type FieldError struct {
+	Field string
+	Text  string
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Text
+	}
+	return e.Field + ": " + e.Text
+}
+
+// FieldErrors are all the invalid fields of a request
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	texts := make([]string, len(e))
+	for i, err := range e {
+		texts[i] = err.Error()
+	}
+
+	return strings.Join(texts, "; ")
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,8 +18,10 @@ type BaseReader[T any] interface {
 // Following OCP: new entity types can implement this interface without modifying existing code
 type BaseWriter[T any] interface {
 	Create(ctx context.Context, entity *T) (*T, error)
+	// Update returns a VersionConflictError when the stored entity is no longer at the entity's version
 	Update(ctx context.Context, entity *T) (*T, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete returns a VersionConflictError when the stored entity is no longer at the entity's version
+	Delete(ctx context.Context, entity *T) error
 }
 
 // BaseRepository combines read and write operations for any entity
@@ -34,14 +37,29 @@ type UserReader interface {
 	GetUser(ctx context.Context, id uuid.UUID) (*User, error)
 	ListUsers(ctx context.Context, limit, offset int) ([]*User, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	// ListDeletedUsers lists soft deleted users, most recently deleted first
+	ListDeletedUsers(ctx context.Context, limit, offset int) ([]*User, error)
+}
+
+// UserLister lists users matching a Query of UserQuerySchema
+// Following ISP: separate from UserReader, as not every store can run arbitrary filters
+type UserLister interface {
+	// QueryUsers returns up to q.Limit active users, and the After of the next page if there are more
+	QueryUsers(ctx context.Context, q Query) (*Page[User], error)
 }
 
 // UserWriter defines write operations for users
 // Following ISP: clients that only need to write users don't depend on read operations
 type UserWriter interface {
 	CreateUser(ctx context.Context, user *User) (*User, error)
+	// UpdateUser returns a VersionConflictError when the stored user is no longer at user.Version()
 	UpdateUser(ctx context.Context, user *User) (*User, error)
-	DeleteUser(ctx context.Context, id uuid.UUID) error
+	// DeleteUser soft deletes the user. It returns a VersionConflictError when the stored user is no longer at user.Version()
+	DeleteUser(ctx context.Context, user *User) error
+	// RestoreUser undoes a soft delete
+	RestoreUser(ctx context.Context, id uuid.UUID) (*User, error)
+	// PurgeDeletedUsers hard deletes users soft deleted before the given time
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error)
 }
 
 // UserRepository combines read and write operations
@@ -55,13 +73,34 @@ type UserRepository interface {
 type ItemReader interface {
 	GetItem(ctx context.Context, id uuid.UUID) (*Item, error)
 	ListItems(ctx context.Context, limit, offset int) ([]*Item, error)
+	// ListDeletedItems lists soft deleted items, most recently deleted first
+	ListDeletedItems(ctx context.Context, limit, offset int) ([]*Item, error)
+}
+
+// ItemLister lists items matching a Query of ItemQuerySchema
+// Following ISP: separate from ItemReader, as not every store can run arbitrary filters
+type ItemLister interface {
+	// QueryItems returns up to q.Limit active items, and the After of the next page if there are more
+	QueryItems(ctx context.Context, q Query) (*Page[Item], error)
+}
+
+// ItemSearcher defines full-text search of items
+// Following ISP: separate from ItemReader, as not every store can search text
+type ItemSearcher interface {
+	SearchItems(ctx context.Context, search ItemSearch) ([]*ItemSearchResult, error)
 }
 
 // ItemWriter defines write operations for items
 type ItemWriter interface {
 	CreateItem(ctx context.Context, item *Item) (*Item, error)
+	// UpdateItem returns a VersionConflictError when the stored item is no longer at item.Version()
 	UpdateItem(ctx context.Context, item *Item) (*Item, error)
-	DeleteItem(ctx context.Context, id uuid.UUID) error
+	// DeleteItem soft deletes the item. It returns a VersionConflictError when the stored item is no longer at item.Version()
+	DeleteItem(ctx context.Context, item *Item) error
+	// RestoreItem undoes a soft delete
+	RestoreItem(ctx context.Context, id uuid.UUID) (*Item, error)
+	// PurgeDeletedItems hard deletes items soft deleted before the given time
+	PurgeDeletedItems(ctx context.Context, before time.Time) (int64, error)
 }
 
 // ItemRepository combines read and write operations for items
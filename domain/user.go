@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -19,10 +21,26 @@ func (p Password) Hash() (HashedPassword, error) {
 type HashedPassword []byte
 
 type User struct {
-	id       uuid.UUID
-	name     string
-	email    string
-	password HashedPassword
+	events
+
+	id        uuid.UUID
+	name      string
+	email     string
+	password  HashedPassword
+	version   int32
+	deletedAt *time.Time
+}
+
+// UserQuerySchema whitelists the fields users can be listed by.
+// Email is left out so that lists can't be used to find out the address of a user.
+var UserQuerySchema = QuerySchema{
+	Fields: map[string]QueryField{
+		"id":         {Type: FieldUUID},
+		"name":       {Type: FieldString, Sortable: true},
+		"created_at": {Type: FieldTime, Sortable: true},
+		"updated_at": {Type: FieldTime, Sortable: true},
+	},
+	DefaultSort: []SortField{{Field: "created_at", Desc: true}},
 }
 
 func NewUser(name string, email string, password Password) (*User, error) {
@@ -31,7 +49,10 @@ func NewUser(name string, email string, password Password) (*User, error) {
 		return nil, err
 	}
 
-	return &User{id: uuid.New(), name: name, email: email, password: hashedPassword}, nil
+	user := &User{id: uuid.New(), name: name, email: email, password: hashedPassword, version: 1}
+	user.raise(EventUserCreated, "user", user.id, UserPayload{ID: user.id, Name: name, Email: email})
+
+	return user, nil
 }
 
 func (u *User) ID() uuid.UUID {
@@ -50,10 +71,47 @@ func (u *User) Password() HashedPassword {
 	return u.password
 }
 
-func UserFromSource(id uuid.UUID, name string, email string) *User {
+func (u *User) SetName(name string) {
+	if u.name == name {
+		return
+	}
+
+	u.name = name
+	u.raise(EventUserUpdated, "user", u.id, UserPayload{ID: u.id, Name: name})
+}
+
+// Delete marks the user as soft deleted
+func (u *User) Delete() {
+	now := time.Now()
+	u.deletedAt = &now
+	u.raise(EventUserDeleted, "user", u.id, UserPayload{ID: u.id})
+}
+
+// Version is incremented by every update and used for optimistic concurrency control
+func (u *User) Version() int32 {
+	return u.version
+}
+
+// DeletedAt is set while the user is soft deleted
+func (u *User) DeletedAt() *time.Time {
+	return u.deletedAt
+}
+
+// CheckVersion returns a VersionConflictError unless the user is at the expected version
+func (u *User) CheckVersion(expected int32) error {
+	if u.version != expected {
+		return &VersionConflictError{Entity: "user", ID: u.id, Version: expected}
+	}
+
+	return nil
+}
+
+func UserFromSource(id uuid.UUID, name string, email string, version int32, deletedAt *time.Time) *User {
 	return &User{
-		id:    id,
-		name:  name,
-		email: email,
+		id:        id,
+		name:      name,
+		email:     email,
+		version:   version,
+		deletedAt: deletedAt,
 	}
 }
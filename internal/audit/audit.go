@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Action is what was done to an entity
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionRestore Action = "restore"
+	ActionImport  Action = "import"
+)
+
+const (
+	// ActorSystem is recorded for changes made outside of a request, e.g. by tasks
+	ActorSystem = "system"
+	// ActorAnonymous is recorded for requests without an authenticated user
+	ActorAnonymous = "anonymous"
+)
+
+// Entry describes a change to record.
+// Before and After are snapshots of the entity marshalled to JSON objects; nil for creations and deletions.
+type Entry struct {
+	EntityType string
+	EntityID   uuid.UUID
+	Action     Action
+	Before     any
+	After      any
+}
+
+// Change holds the old and new value of a field
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Event is a recorded Entry
+type Event struct {
+	ID         int64
+	Actor      string
+	EntityType string
+	// EntityID is uuid.Nil for events about several entities, e.g. imports
+	EntityID  uuid.UUID
+	Action    Action
+	Diff      map[string]Change
+	CreatedAt time.Time
+}
+
+// Filter selects events to list. Zero values match everything.
+type Filter struct {
+	EntityType string
+	EntityID   uuid.UUID
+	Actor      string
+	Action     Action
+	Since      time.Time
+	Until      time.Time
+	// AfterID lists events older than the event with this ID
+	AfterID int64
+	Limit   int
+}
+
+// Repository stores events
+type Repository interface {
+	CreateEvent(ctx context.Context, event *Event) (*Event, error)
+	ListEvents(ctx context.Context, filter Filter) ([]*Event, error)
+}
+
+// Recorder records changes made by use cases.
+// Call it inside the Transaction.Do of the change so that both are committed together.
+type Recorder interface {
+	Record(ctx context.Context, entry Entry) error
+}
+
+type recorder struct {
+	repository Repository
+}
+
+// NewRecorder creates a new recorder
+// Following DIP: depends on the repository interface, not concrete implementation
+func NewRecorder(repository Repository) Recorder {
+	return &recorder{repository: repository}
+}
+
+// Record implements Recorder
+func (r *recorder) Record(ctx context.Context, entry Entry) error {
+	diff, err := Diff(entry.Before, entry.After)
+	if err != nil {
+		return fmt.Errorf("failed to diff %s %s: %w", entry.EntityType, entry.EntityID, err)
+	}
+
+	_, err = r.repository.CreateEvent(ctx, &Event{
+		Actor:      ActorFrom(ctx),
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Action:     entry.Action,
+		Diff:       diff,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return nil
+}
+
+// Diff returns the fields of the JSON objects before and after whose values differ.
+func Diff(before, after any) (map[string]Change, error) {
+	b, err := toObject(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toObject(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := map[string]Change{}
+	for k, v := range b {
+		if av, ok := a[k]; !ok || !reflect.DeepEqual(v, av) {
+			diff[k] = Change{Before: v, After: a[k]}
+		}
+	}
+	for k, v := range a {
+		if _, ok := b[k]; !ok {
+			diff[k] = Change{After: v}
+		}
+	}
+
+	return diff, nil
+}
+
+func toObject(v any) (map[string]any, error) {
+	if v == nil {
+		return map[string]any{}, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := map[string]any{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+type actorKey struct{}
+
+// WithActor sets who is making changes with ctx
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor set by WithActor, or ActorSystem
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+
+	return ActorSystem
+}
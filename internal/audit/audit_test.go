@@ -0,0 +1,68 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+)
+
+func TestDiff(t *testing.T) {
+	type snapshot struct {
+		Name    string `json:"name"`
+		Email   string `json:"email"`
+		Version int32  `json:"version"`
+	}
+
+	tests := []struct {
+		name     string
+		before   any
+		after    any
+		expected map[string]audit.Change
+	}{
+		{
+			name:   "create",
+			before: nil,
+			after:  snapshot{Name: "John", Email: "john@example.com", Version: 1},
+			expected: map[string]audit.Change{
+				"name":    {After: "John"},
+				"email":   {After: "john@example.com"},
+				"version": {After: float64(1)},
+			},
+		},
+		{
+			name:   "update only lists changed fields",
+			before: snapshot{Name: "John", Email: "john@example.com", Version: 1},
+			after:  snapshot{Name: "Jane", Email: "john@example.com", Version: 2},
+			expected: map[string]audit.Change{
+				"name":    {Before: "John", After: "Jane"},
+				"version": {Before: float64(1), After: float64(2)},
+			},
+		},
+		{
+			name:   "delete",
+			before: map[string]any{"name": "John"},
+			after:  nil,
+			expected: map[string]audit.Change{
+				"name": {Before: "John"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := audit.Diff(tt.before, tt.after)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, diff)
+		})
+	}
+}
+
+func TestActorFrom(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, audit.ActorSystem, audit.ActorFrom(ctx))
+	assert.Equal(t, "user-1", audit.ActorFrom(audit.WithActor(ctx, "user-1")))
+}
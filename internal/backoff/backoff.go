@@ -0,0 +1,20 @@
+// Package backoff computes retry delays shared by the HTTP client, webhook deliveries, the outbox relay
+// and database transactions.
+package backoff
+
+import "time"
+
+// Delay returns the delay before retrying after the given number of attempts.
+// The delay doubles with each attempt from base up to max; jitter in [0, 1) spreads it over its upper half
+// so that callers failing together are not retried together.
+func Delay(attempts int, base, max time.Duration, jitter float64) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	return delay/2 + time.Duration(jitter*float64(delay/2))
+}
@@ -0,0 +1,34 @@
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/backoff"
+)
+
+func TestDelay(t *testing.T) {
+	base, max := 10*time.Second, 5*time.Minute
+
+	tests := []struct {
+		attempts int
+		jitter   float64
+		expected time.Duration
+	}{
+		{attempts: 1, jitter: 0, expected: 5 * time.Second},
+		{attempts: 1, jitter: 0.5, expected: 7500 * time.Millisecond},
+		{attempts: 2, jitter: 0, expected: 10 * time.Second},
+		{attempts: 3, jitter: 0, expected: 20 * time.Second},
+		{attempts: 3, jitter: 1, expected: 40 * time.Second},
+		{attempts: 20, jitter: 0, expected: max / 2},
+		{attempts: 20, jitter: 0.999, expected: max/2 + time.Duration(0.999*float64(max/2))},
+		// more attempts than a duration can double
+		{attempts: 1000, jitter: 0, expected: max / 2},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, backoff.Delay(tt.attempts, base, max, tt.jitter), "attempts=%d jitter=%v", tt.attempts, tt.jitter)
+	}
+}
@@ -0,0 +1,48 @@
+// Package buildinfo describes the running binary, for operational endpoints
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+var (
+	version  = "edge"
+	revision = "latest"
+)
+
+// Set records the release tag and commit hash embedded on build, see main.go. Call it once on startup.
+func Set(v, r string) {
+	version, revision = v, r
+}
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	GoVersion string `json:"go_version"`
+	// Module is the main module path and VCS the settings recorded by the go command, e.g. vcs.modified
+	Module string            `json:"module,omitempty"`
+	VCS    map[string]string `json:"vcs,omitempty"`
+}
+
+// Get returns the info of the running binary
+func Get() Info {
+	info := Info{Version: version, Revision: revision, GoVersion: runtime.Version()}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Module = bi.Main.Path
+		for _, s := range bi.Settings {
+			if !strings.HasPrefix(s.Key, "vcs.") {
+				continue
+			}
+			if info.VCS == nil {
+				info.VCS = map[string]string{}
+			}
+			info.VCS[s.Key] = s.Value
+		}
+	}
+
+	return info
+}
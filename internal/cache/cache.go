@@ -0,0 +1,18 @@
+// Package cache keeps encoded values by key for a limited time, in process memory or in Redis.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache stores values by key until their TTL passes or they are deleted.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value of key, and false when there is none or it expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the keys. Missing keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLRU(2)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+
+	v, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+
+	// b is the least recently used and makes room for c
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+	assert.Equal(t, 2, c.Len())
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok)
+	_, ok, _ = c.Get(ctx, "a")
+	assert.True(t, ok)
+
+	// setting a key again replaces its value
+	require.NoError(t, c.Set(ctx, "a", []byte("4"), time.Minute))
+	v, _, _ = c.Get(ctx, "a")
+	assert.Equal(t, []byte("4"), v)
+
+	require.NoError(t, c.Delete(ctx, "a", "missing"))
+	_, ok, _ = c.Get(ctx, "a")
+	assert.False(t, ok)
+
+	// values expire after their ttl
+	now = now.Add(time.Minute)
+	_, ok, _ = c.Get(ctx, "c")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,50 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/cache"
+)
+
+func TestRedis(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	c := cache.NewRedis(client, "test:")
+	ctx := context.Background()
+
+	_, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+	assert.True(t, srv.Exists("test:a"), "keys are prefixed")
+
+	v, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+
+	require.NoError(t, c.Delete(ctx, "a", "missing"))
+	_, ok, _ = c.Get(ctx, "a")
+	assert.False(t, ok)
+
+	// values expire after their ttl
+	srv.FastForward(time.Minute)
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok)
+
+	// errors of the server are returned
+	srv.Close()
+	_, _, err = c.Get(ctx, "b")
+	assert.Error(t, err)
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/SoraDaibu/go-clean-starter/builder"
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+	"github.com/SoraDaibu/go-clean-starter/internal/logging"
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+)
+
+// adminServer serves operational endpoints on a port separate from the public API,
+// so they can be kept off the ingress.
+type adminServer struct {
+	srv *http.Server
+}
+
+func newAdminServer(d *builder.Dependency, controls, debug http.Handler) *adminServer {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/admin/", controls)
+	mux.Handle("/debug/", debug)
+
+	return &adminServer{
+		srv: &http.Server{
+			Addr:              net.JoinHostPort(d.Config.Admin.BindAddress, strconv.Itoa(int(d.Config.Admin.ListenPort))),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+func (a *adminServer) run() {
+	log.Info().Str("addr", a.srv.Addr).Msg("admin server started")
+
+	if err := a.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error().Err(err).Msg("admin server stopped")
+	}
+}
+
+func (a *adminServer) close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return a.srv.Shutdown(ctx)
+}
+
+// adminControls serves the admin endpoints that change the running server:
+//
+//	GET    /admin/log-level                    the levels in effect
+//	PUT    /admin/log-level                    {"level": "debug"} with an optional "package" or "route"
+//	DELETE /admin/log-level?package=|route=    removes the level of a package or route
+//	POST   /admin/config/reload                reloads the configuration like SIGHUP
+//
+// They require the bearer token admin.token, see requireAdminToken.
+type adminControls struct {
+	reloader *config.Reloader
+	levels   *logging.Levels
+}
+
+func newAdminControls(d *builder.Dependency, reloader *config.Reloader, levels *logging.Levels) http.Handler {
+	a := &adminControls{reloader: reloader, levels: levels}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/log-level", a.getLogLevel)
+	mux.HandleFunc("PUT /admin/log-level", a.setLogLevel)
+	mux.HandleFunc("DELETE /admin/log-level", a.deleteLogLevel)
+	mux.HandleFunc("POST /admin/config/reload", a.reloadConfig)
+
+	return requireAdminToken(d.Config, mux)
+}
+
+// requireAdminToken responds 401 to requests without the bearer token admin.token,
+// and 404 to every request while the token is not configured.
+func requireAdminToken(c *config.Config, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// read on every request, so that a rotated token takes effect
+		token := c.Secret("admin.token")
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAdminError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+type logLevelRequest struct {
+	Level   string `json:"level"`
+	Package string `json:"package"`
+	Route   string `json:"route"`
+}
+
+func (a *adminControls) getLogLevel(w http.ResponseWriter, _ *http.Request) {
+	writeAdminJSON(w, http.StatusOK, a.levels.Snapshot())
+}
+
+func (a *adminControls) setLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req logLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if req.Package != "" && req.Route != "" {
+		writeAdminError(w, http.StatusBadRequest, "Set either package or route, not both")
+		return
+	}
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch {
+	case req.Package != "":
+		a.levels.SetPackage(req.Package, level)
+	case req.Route != "":
+		a.levels.SetRoute(req.Route, level)
+	default:
+		a.levels.SetDefault(level)
+	}
+	auditLogLevel(r, req, level.String())
+
+	writeAdminJSON(w, http.StatusOK, a.levels.Snapshot())
+}
+
+func (a *adminControls) deleteLogLevel(w http.ResponseWriter, r *http.Request) {
+	req := logLevelRequest{Package: r.URL.Query().Get("package"), Route: r.URL.Query().Get("route")}
+	switch {
+	case req.Package != "" && req.Route != "":
+		writeAdminError(w, http.StatusBadRequest, "Set either package or route, not both")
+		return
+	case req.Package != "":
+		a.levels.SetPackage(req.Package, zerolog.NoLevel)
+	case req.Route != "":
+		a.levels.SetRoute(req.Route, zerolog.NoLevel)
+	default:
+		writeAdminError(w, http.StatusBadRequest, "package or route is required")
+		return
+	}
+	auditLogLevel(r, req, "")
+
+	writeAdminJSON(w, http.StatusOK, a.levels.Snapshot())
+}
+
+func (a *adminControls) reloadConfig(w http.ResponseWriter, _ *http.Request) {
+	changes, err := a.reloader.Reload("admin")
+	if err != nil {
+		writeAdminError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if changes == nil {
+		changes = []config.Change{}
+	}
+
+	writeAdminJSON(w, http.StatusOK, changes)
+}
+
+// auditLogLevel logs a change of a log level like config.Reloader logs config changes. An empty level is a removal.
+func auditLogLevel(r *http.Request, req logLevelRequest, level string) {
+	scope := "default"
+	switch {
+	case req.Package != "":
+		scope = "package:" + req.Package
+	case req.Route != "":
+		scope = "route:" + req.Route
+	}
+
+	log.Info().
+		Bool("audit", true).
+		Str("actor", "admin").
+		Str("remote_addr", r.RemoteAddr).
+		Str("key", "log_level").
+		Str("scope", scope).
+		Str("new", level).
+		Msg("log level changed")
+}
+
+func writeAdminJSON(w http.ResponseWriter, code int, data any) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		writeAdminError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	obj := json.RawMessage(b)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	//nolint:errchkjson
+	_ = json.NewEncoder(w).Encode(&base.ResponseRoot{Data: &obj})
+}
+
+func writeAdminError(w http.ResponseWriter, code int, title string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	//nolint:errchkjson
+	_ = json.NewEncoder(w).Encode(&base.ErrorResponse{Status: code, Title: title})
+}
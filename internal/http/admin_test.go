@@ -0,0 +1,190 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/builder"
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/logging"
+)
+
+// newTestAdmin sets up the admin port with the token adminToken, returning it with the reloader and levels it changes
+func newTestAdmin(t *testing.T, adminToken string) (http.Handler, *config.Reloader, *logging.Levels) {
+	t.Helper()
+
+	t.Setenv("ADMIN_TOKEN", adminToken)
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	// the levels lower the global level as they change
+	previous := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(previous) })
+
+	d := &builder.Dependency{Config: cfg, Lifecycle: &builder.Lifecycle{}}
+	reloader := config.NewReloader(cfg)
+	levels := logging.NewLevels(zerolog.InfoLevel)
+	admin := newAdminServer(d, newAdminControls(d, reloader, levels), newDebugHandler(d, newTestEcho(t, adminToken), reloader))
+
+	return admin.srv.Handler, reloader, levels
+}
+
+func serveBody(h http.Handler, method, target, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// data decodes the data of an admin response into v
+func data(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+
+	var res struct {
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res), rec.Body.String())
+	require.NoError(t, json.Unmarshal(res.Data, v))
+}
+
+func TestRequireAdminToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := []struct {
+		name          string
+		configured    string
+		authorization string
+		expected      int
+	}{
+		{name: "not configured", configured: "", authorization: "Bearer ", expected: http.StatusNotFound},
+		{name: "missing token", configured: testAdminToken, expected: http.StatusUnauthorized},
+		{name: "wrong token", configured: testAdminToken, authorization: "Bearer wrong", expected: http.StatusUnauthorized},
+		{name: "token prefix", configured: testAdminToken, authorization: "Bearer " + testAdminToken[:4], expected: http.StatusUnauthorized},
+		{name: "other scheme", configured: testAdminToken, authorization: "Basic " + testAdminToken, expected: http.StatusUnauthorized},
+		{name: "correct token", configured: testAdminToken, authorization: "Bearer " + testAdminToken, expected: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ADMIN_TOKEN", tt.configured)
+			cfg, err := config.Load()
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/log-level", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			requireAdminToken(cfg, ok).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+				assert.JSONEq(t, `{"status":401,"title":"Unauthorized"}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	t.Run("requires the token on every admin endpoint", func(t *testing.T) {
+		admin, _, _ := newTestAdmin(t, testAdminToken)
+
+		for _, r := range []struct{ method, target string }{
+			{http.MethodGet, "/admin/log-level"},
+			{http.MethodPut, "/admin/log-level"},
+			{http.MethodDelete, "/admin/log-level?package=internal/webhook"},
+			{http.MethodPost, "/admin/config/reload"},
+		} {
+			for _, token := range []string{"", "wrong"} {
+				assert.Equal(t, http.StatusUnauthorized, serve(admin, r.method, r.target, token).Code, "%s %s", r.method, r.target)
+			}
+		}
+	})
+
+	t.Run("metrics need no token", func(t *testing.T) {
+		admin, _, _ := newTestAdmin(t, testAdminToken)
+
+		assert.Equal(t, http.StatusOK, serve(admin, http.MethodGet, "/metrics", "").Code)
+	})
+
+	t.Run("changes log levels", func(t *testing.T) {
+		admin, _, levels := newTestAdmin(t, testAdminToken)
+
+		rec := serveBody(admin, http.MethodPut, "/admin/log-level", testAdminToken, `{"level":"debug","package":"internal/webhook"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var snapshot logging.Snapshot
+		data(t, rec, &snapshot)
+		assert.Equal(t, "debug", snapshot.Packages["internal/webhook"])
+		assert.Equal(t, "debug", levels.Snapshot().Packages["internal/webhook"])
+
+		rec = serveBody(admin, http.MethodPut, "/admin/log-level", testAdminToken, `{"level":"warn"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "warn", levels.Snapshot().Default)
+
+		rec = serve(admin, http.MethodDelete, "/admin/log-level?package=internal/webhook", testAdminToken)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, levels.Snapshot().Packages, "internal/webhook")
+
+		rec = serve(admin, http.MethodGet, "/admin/log-level", testAdminToken)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var current logging.Snapshot
+		data(t, rec, &current)
+		assert.Equal(t, levels.Snapshot(), current)
+	})
+
+	t.Run("rejects invalid log levels", func(t *testing.T) {
+		admin, _, levels := newTestAdmin(t, testAdminToken)
+
+		for _, body := range []string{`{`, `{"level":"loud"}`, `{"level":"debug","package":"internal/webhook","route":"/users"}`} {
+			assert.Equal(t, http.StatusBadRequest, serveBody(admin, http.MethodPut, "/admin/log-level", testAdminToken, body).Code, body)
+		}
+		assert.Equal(t, http.StatusBadRequest, serve(admin, http.MethodDelete, "/admin/log-level", testAdminToken).Code)
+		assert.Equal(t, "info", levels.Snapshot().Default)
+	})
+
+	t.Run("reloads the configuration", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_IP", "60:5")
+		admin, reloader, _ := newTestAdmin(t, testAdminToken)
+
+		t.Setenv("RATE_LIMIT_IP", "120:10")
+		rec := serve(admin, http.MethodPost, "/admin/config/reload", testAdminToken)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var changes []config.Change
+		data(t, rec, &changes)
+		require.Len(t, changes, 1)
+		assert.Equal(t, "rate_limit.ip", changes[0].Key)
+		assert.True(t, changes[0].Applied)
+		assert.Equal(t, config.RateLimitRule{PerMinute: 120, Burst: 10}, reloader.Current().RateLimit.IP)
+
+		// nothing changed since
+		rec = serve(admin, http.MethodPost, "/admin/config/reload", testAdminToken)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var unchanged []config.Change
+		data(t, rec, &unchanged)
+		assert.Empty(t, unchanged)
+	})
+
+	t.Run("keeps the configuration when the reload fails", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_IP", "60:5")
+		admin, reloader, _ := newTestAdmin(t, testAdminToken)
+
+		t.Setenv("RATE_LIMIT_IP", "fast")
+		rec := serve(admin, http.MethodPost, "/admin/config/reload", testAdminToken)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, config.RateLimitRule{PerMinute: 60, Burst: 5}, reloader.Current().RateLimit.IP)
+	})
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
 )
 
 type ResponseRoot struct {
@@ -50,7 +52,7 @@ func JSONWithTotal(c echo.Context, code int, data interface{}, total uint64) err
 
 func Bind(c echo.Context, v interface{}) error {
 	if err := c.Bind(v); err != nil {
-		log.Error().Stack().Err(errors.WithStack(err)).Msg("")
+		zerolog.Ctx(c.Request().Context()).Error().Stack().Err(errors.WithStack(err)).Msg("")
 
 		code := http.StatusBadRequest
 
@@ -59,7 +61,7 @@ func Bind(c echo.Context, v interface{}) error {
 			Title:   http.StatusText(code),
 			Details: []*ErrorDetail{{Text: "invalid parameter"}},
 		}); err != nil {
-			log.Error().Stack().Err(errors.WithStack(err)).Msg("")
+			zerolog.Ctx(c.Request().Context()).Error().Stack().Err(errors.WithStack(err)).Msg("")
 		}
 
 		return err
@@ -92,20 +94,38 @@ func HandleError(c echo.Context, err error) error {
 		code = http.StatusBadRequest
 		details = []*ErrorDetail{{Field: "password", Text: err.Error()}}
 	default:
-		// Handle UUID parsing errors
-		if strings.Contains(err.Error(), "invalid UUID") {
+		var notFound *domain.NotFoundError
+		var conflict *domain.ConflictError
+		var invalid domain.FieldErrors
+		var invalidField *domain.FieldError
+
+		switch {
+		// Handle invalid query parameters such as filters
+		case errors.As(err, &invalid):
 			code = http.StatusBadRequest
-			details = []*ErrorDetail{{Text: "invalid UUID format"}}
-		}
-		// Handle not found errors
-		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "no rows") {
+			details = make([]*ErrorDetail, len(invalid))
+			for i, e := range invalid {
+				details[i] = &ErrorDetail{Field: e.Field, Text: e.Text}
+			}
+		case errors.As(err, &invalidField):
+			code = http.StatusBadRequest
+			details = []*ErrorDetail{{Field: invalidField.Field, Text: invalidField.Text}}
+		// Handle stale writes
+		case errors.Is(err, domain.ErrVersionConflict):
+			code = http.StatusPreconditionFailed
+			details = []*ErrorDetail{{Field: HeaderIfMatch, Text: "resource was modified, fetch it again and retry"}}
+		// Handle missing entities
+		case errors.As(err, &notFound):
 			code = http.StatusNotFound
-			details = []*ErrorDetail{{Text: "User not found"}}
-		}
-		// Handle duplicate key errors
-		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint") {
+			details = []*ErrorDetail{{Text: capitalize(notFound.Entity) + " not found"}}
+		// Handle uniqueness violations
+		case errors.As(err, &conflict):
 			code = http.StatusConflict
-			details = []*ErrorDetail{{Text: "Resource already exists"}}
+			details = []*ErrorDetail{{Field: conflict.Field, Text: "Resource already exists"}}
+		// Handle UUID parsing errors
+		case strings.Contains(err.Error(), "invalid UUID"):
+			code = http.StatusBadRequest
+			details = []*ErrorDetail{{Text: "invalid UUID format"}}
 		}
 	}
 
@@ -114,8 +134,17 @@ func HandleError(c echo.Context, err error) error {
 		Title:   http.StatusText(code),
 		Details: details,
 	}); err != nil {
-		log.Error().Stack().Err(errors.WithStack(err)).Msg("")
+		zerolog.Ctx(c.Request().Context()).Error().Stack().Err(errors.WithStack(err)).Msg("")
 	}
 
 	return err
 }
+
+// capitalize upper-cases the first letter of an entity name for messages, e.g. "User not found"
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,68 @@
+package base_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+)
+
+func TestHandleError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "item not found",
+			err:            fmt.Errorf("failed to get item: %w", &domain.NotFoundError{Entity: "item", Field: "id", Value: "1"}),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"title":"Not Found","details":[{"text":"Item not found"}]}`,
+		},
+		{
+			name:           "duplicate email",
+			err:            fmt.Errorf("failed to create user: %w", &domain.ConflictError{Entity: "user", Field: "email"}),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"status":409,"title":"Conflict","details":[{"field":"email","text":"Resource already exists"}]}`,
+		},
+		{
+			name: "invalid filters",
+			err: domain.FieldErrors{
+				{Field: "filter[email]", Text: "unknown field"},
+				{Field: "sort", Text: "unknown field"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"title":"Bad Request","details":[{"field":"filter[email]","text":"unknown field"},{"field":"sort","text":"unknown field"}]}`,
+		},
+		{
+			name:           "invalid field",
+			err:            fmt.Errorf("failed to search items: %w", &domain.FieldError{Field: "q", Text: "q is required"}),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"title":"Bad Request","details":[{"field":"q","text":"q is required"}]}`,
+		},
+		{
+			name:           "invalid request",
+			err:            &domain.FieldError{Text: "url, event_types or enabled is required"},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"title":"Bad Request","details":[{"text":"url, event_types or enabled is required"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+			assert.Error(t, base.HandleError(c, tt.err))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package base
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+var (
+	ErrPreconditionRequired = errors.New("If-Match header is required")
+	ErrPreconditionFailed   = errors.New("If-Match header does not match the current version")
+)
+
+// ETag formats an entity version as a strong entity tag
+func ETag(version int32) string {
+	return `"` + strconv.FormatInt(int64(version), 10) + `"`
+}
+
+// NotModified reports whether the If-None-Match header matches etag, using weak comparison.
+func NotModified(c echo.Context, etag string) bool {
+	header := c.Request().Header.Get(HeaderIfNoneMatch)
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IfMatch returns the versions listed by the If-Match header, any of which may match, or nil for "*".
+// Like Bind, it writes the error response itself: 428 when the header is missing
+// and 412 when none of its entity tags can match a version.
+func IfMatch(c echo.Context) ([]int32, error) {
+	header := strings.TrimSpace(c.Request().Header.Get(HeaderIfMatch))
+	if header == "" {
+		return nil, writePreconditionError(c, http.StatusPreconditionRequired, ErrPreconditionRequired)
+	}
+
+	var versions []int32
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, nil
+		}
+
+		// weak tags never match with the strong comparison required by If-Match
+		unquoted, err := strconv.Unquote(tag)
+		if err != nil || !strings.HasPrefix(tag, `"`) {
+			continue
+		}
+		if version, err := strconv.ParseInt(unquoted, 10, 32); err == nil {
+			versions = append(versions, int32(version))
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, writePreconditionError(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+	}
+
+	return versions, nil
+}
+
+func writePreconditionError(c echo.Context, code int, err error) error {
+	if err := c.JSON(code, &ErrorResponse{
+		Status:  code,
+		Title:   http.StatusText(code),
+		Details: []*ErrorDetail{{Field: HeaderIfMatch, Text: err.Error()}},
+	}); err != nil {
+		zerolog.Ctx(c.Request().Context()).Error().Stack().Err(errors.WithStack(err)).Msg("")
+	}
+
+	return err
+}
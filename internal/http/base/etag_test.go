@@ -0,0 +1,75 @@
+package base_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name             string
+		header           string
+		expectedVersions []int32
+		expectedStatus   int
+	}{
+		{name: "strong tag", header: `"3"`, expectedVersions: []int32{3}, expectedStatus: http.StatusOK},
+		{name: "any version", header: "*", expectedStatus: http.StatusOK},
+		{name: "missing header", header: "", expectedStatus: http.StatusPreconditionRequired},
+		{name: "weak tag never matches", header: `W/"3"`, expectedStatus: http.StatusPreconditionFailed},
+		{name: "not a version", header: `"abc"`, expectedStatus: http.StatusPreconditionFailed},
+		{name: "several tags", header: `"3", "4"`, expectedVersions: []int32{3, 4}, expectedStatus: http.StatusOK},
+		{name: "tags that cannot match are skipped", header: `W/"2", "abc",  "4"`, expectedVersions: []int32{4}, expectedStatus: http.StatusOK},
+		{name: "no tag can match", header: `W/"3", "abc"`, expectedStatus: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/users/1", nil)
+			if tt.header != "" {
+				req.Header.Set(base.HeaderIfMatch, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			v, err := base.IfMatch(c)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedVersions, v)
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{header: "", expected: false},
+		{header: `"3"`, expected: true},
+		{header: `W/"3"`, expected: true},
+		{header: `"1", "3"`, expected: true},
+		{header: `"2"`, expected: false},
+		{header: "*", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			req.Header.Set(base.HeaderIfNoneMatch, tt.header)
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			assert.Equal(t, tt.expected, base.NotModified(c, base.ETag(3)))
+		})
+	}
+}
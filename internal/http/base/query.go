@@ -0,0 +1,165 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+)
+
+const (
+	// maxSortFields bounds the size of the SQL that continues a page after a cursor
+	maxSortFields = 3
+	// maxInValues bounds the number of values of the in operator
+	maxInValues = 100
+	// maxFilterLength bounds the length of filter values
+	maxFilterLength = 200
+)
+
+// filterParam matches filter[field] and filter[field][operator]
+var filterParam = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
+// ParseQuery parses the filter and sort parameters of a list endpoint into a domain.Query, e.g.
+//
+//	?filter[name][contains]=jo&filter[created_at][gte]=2025-01-01T00:00:00Z&sort=-created_at,name
+//
+// filter[field]=value is short for filter[field][eq]=value, the in operator takes a comma-separated list
+// and sort takes fields prefixed with - for a descending order.
+// Fields and operators that schema doesn't allow are rejected, with every invalid parameter in a domain.FieldErrors.
+// Other parameters, such as cursor and limit, are left to the caller.
+func ParseQuery(params url.Values, schema domain.QuerySchema) (domain.Query, error) {
+	var q domain.Query
+	var errs domain.FieldErrors
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if strings.HasPrefix(key, "filter") {
+			keys = append(keys, key)
+		}
+	}
+	// report errors in a stable order
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		c, err := parseFilter(key, params[key], schema)
+		if err != nil {
+			errs = append(errs, &domain.FieldError{Field: key, Text: err.Error()})
+			continue
+		}
+		q.Filters = append(q.Filters, c)
+	}
+
+	if values, ok := params["sort"]; ok {
+		sort, err := parseSort(values, schema)
+		if err != nil {
+			errs = append(errs, &domain.FieldError{Field: "sort", Text: err.Error()})
+		}
+		q.Sort = sort
+	}
+
+	if len(errs) > 0 {
+		return domain.Query{}, errs
+	}
+
+	return q, nil
+}
+
+func parseFilter(key string, values []string, schema domain.QuerySchema) (domain.Condition, error) {
+	m := filterParam.FindStringSubmatch(key)
+	if m == nil {
+		return domain.Condition{}, errors.New("must be filter[field][operator]")
+	}
+	name, op := m[1], domain.Op(m[2])
+	if op == "" {
+		op = domain.OpEq
+	}
+
+	field, ok := schema.Fields[name]
+	if !ok {
+		return domain.Condition{}, fmt.Errorf("unknown field %q, expected one of %s", name,
+			strings.Join(schema.FieldNames(func(domain.QueryField) bool { return true }), ", "))
+	}
+	if !slices.Contains(field.AllowedOps(), op) {
+		return domain.Condition{}, fmt.Errorf("unknown operator %q for %s, expected one of %s", op, name, joinOps(field.AllowedOps()))
+	}
+	if len(values) != 1 {
+		return domain.Condition{}, errors.New("must be given once")
+	}
+
+	value := values[0]
+	if len(value) > maxFilterLength {
+		return domain.Condition{}, fmt.Errorf("must be at most %d characters", maxFilterLength)
+	}
+
+	c := domain.Condition{Field: name, Op: op}
+	switch op {
+	case domain.OpIn:
+		texts := strings.Split(value, ",")
+		if len(texts) > maxInValues {
+			return domain.Condition{}, fmt.Errorf("must have at most %d values", maxInValues)
+		}
+		list := make([]any, len(texts))
+		for i, text := range texts {
+			v, err := field.Type.Parse(strings.TrimSpace(text))
+			if err != nil {
+				return domain.Condition{}, fmt.Errorf("every value %w", err)
+			}
+			list[i] = v
+		}
+		c.Value = list
+	case domain.OpContains, domain.OpPrefix:
+		if value == "" {
+			return domain.Condition{}, errors.New("must not be empty")
+		}
+		c.Value = value
+	default:
+		v, err := field.Type.Parse(value)
+		if err != nil {
+			return domain.Condition{}, err
+		}
+		c.Value = v
+	}
+
+	return c, nil
+}
+
+func parseSort(values []string, schema domain.QuerySchema) ([]domain.SortField, error) {
+	if len(values) != 1 {
+		return nil, errors.New("must be given once")
+	}
+
+	var sort []domain.SortField
+	for _, text := range strings.Split(values[0], ",") {
+		s := domain.SortField{Field: strings.TrimSpace(text)}
+		if rest, ok := strings.CutPrefix(s.Field, "-"); ok {
+			s = domain.SortField{Field: rest, Desc: true}
+		}
+
+		if field, ok := schema.Fields[s.Field]; !ok || !field.Sortable {
+			return nil, fmt.Errorf("unknown field %q, expected one of %s", s.Field,
+				strings.Join(schema.FieldNames(func(f domain.QueryField) bool { return f.Sortable }), ", "))
+		}
+		if slices.ContainsFunc(sort, func(prev domain.SortField) bool { return prev.Field == s.Field }) {
+			return nil, fmt.Errorf("sorts by %s more than once", s.Field)
+		}
+		sort = append(sort, s)
+	}
+	if len(sort) > maxSortFields {
+		return nil, fmt.Errorf("must have at most %d fields", maxSortFields)
+	}
+
+	return sort, nil
+}
+
+func joinOps(ops []domain.Op) string {
+	texts := make([]string, len(ops))
+	for i, op := range ops {
+		texts[i] = string(op)
+	}
+
+	return strings.Join(texts, ", ")
+}
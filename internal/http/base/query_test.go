@@ -0,0 +1,77 @@
+package base_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+)
+
+func TestParseQuery(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("parses filters and sort", func(t *testing.T) {
+		params, err := url.ParseQuery("filter[name][contains]=jo&filter[created_at][gte]=2025-01-01T00:00:00Z" +
+			"&filter[type_id][in]=1,2&filter[id]=" + id.String() + "&sort=-created_at,name&limit=10")
+		require.NoError(t, err)
+
+		q, err := base.ParseQuery(params, domain.ItemQuerySchema)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Condition{
+			{Field: "created_at", Op: domain.OpGte, Value: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{Field: "id", Op: domain.OpEq, Value: id},
+			{Field: "name", Op: domain.OpContains, Value: "jo"},
+			{Field: "type_id", Op: domain.OpIn, Value: []any{int64(1), int64(2)}},
+		}, q.Filters)
+		assert.Equal(t, []domain.SortField{{Field: "created_at", Desc: true}, {Field: "name"}}, q.Sort)
+	})
+
+	t.Run("reports every invalid parameter", func(t *testing.T) {
+		params, err := url.ParseQuery("filter[email][eq]=a@example.com&filter[name][gt]=a&filter[created_at][lt]=yesterday" +
+			"&filter[name]]=a&sort=name,password")
+		require.NoError(t, err)
+
+		_, err = base.ParseQuery(params, domain.UserQuerySchema)
+		var errs domain.FieldErrors
+		require.ErrorAs(t, err, &errs)
+		assert.Equal(t, domain.FieldErrors{
+			{Field: "filter[created_at][lt]", Text: "must be an RFC 3339 timestamp"},
+			{Field: "filter[email][eq]", Text: `unknown field "email", expected one of created_at, id, name, updated_at`},
+			{Field: "filter[name][gt]", Text: `unknown operator "gt" for name, expected one of eq, ne, contains, prefix, in`},
+			{Field: "filter[name]]", Text: "must be filter[field][operator]"},
+			{Field: "sort", Text: `unknown field "password", expected one of created_at, name, updated_at`},
+		}, errs)
+	})
+
+	t.Run("rejects invalid values", func(t *testing.T) {
+		tests := []struct {
+			query string
+			text  string
+		}{
+			{"filter[name][contains]=", "must not be empty"},
+			{"filter[id][in]=" + id.String() + ",1", "every value must be a UUID"},
+			{"filter[name]=a&filter[name]=b", "must be given once"},
+			{"sort=name,-name", "sorts by name more than once"},
+			{"sort=", `unknown field "", expected one of created_at, name, updated_at`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.query, func(t *testing.T) {
+				params, err := url.ParseQuery(tt.query)
+				require.NoError(t, err)
+
+				_, err = base.ParseQuery(params, domain.UserQuerySchema)
+				var errs domain.FieldErrors
+				require.ErrorAs(t, err, &errs)
+				require.Len(t, errs, 1)
+				assert.Equal(t, tt.text, errs[0].Text)
+			})
+		}
+	})
+}
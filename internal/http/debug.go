@@ -0,0 +1,128 @@
+package http
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+
+	"github.com/SoraDaibu/go-clean-starter/builder"
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/buildinfo"
+)
+
+// newDebugHandler serves endpoints to inspect the running server, only on the admin port:
+//
+//	GET /debug/pprof/   net/http/pprof profiles
+//	GET /debug/routes   the routes of the API
+//	GET /debug/db       connection pool statistics of the primary and the replicas
+//	GET /debug/health   the health of the dependencies with why they are unhealthy, left out of the public /health
+//	GET /debug/build    version, revision and Go version of the binary
+//	GET /debug/config   the configuration in effect, with secrets masked
+//
+// They require the bearer token admin.token, see requireAdminToken.
+func newDebugHandler(d *builder.Dependency, e *echo.Echo, reloader *config.Reloader) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("GET /debug/routes", func(w http.ResponseWriter, _ *http.Request) {
+		writeAdminJSON(w, http.StatusOK, e.Routes())
+	})
+
+	mux.HandleFunc("GET /debug/db", func(w http.ResponseWriter, _ *http.Request) {
+		writeAdminJSON(w, http.StatusOK, dbStats(d))
+	})
+
+	mux.HandleFunc("GET /debug/health", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, dependencyHealth(d, r))
+	})
+
+	mux.HandleFunc("GET /debug/build", func(w http.ResponseWriter, _ *http.Request) {
+		writeAdminJSON(w, http.StatusOK, buildinfo.Get())
+	})
+
+	mux.HandleFunc("GET /debug/config", func(w http.ResponseWriter, _ *http.Request) {
+		writeAdminJSON(w, http.StatusOK, reloader.Current().Values())
+	})
+
+	return requireAdminToken(d.Config, mux)
+}
+
+type dependencyHealthResponse struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	// Error may reveal hosts and credentials, so it is only served on the admin port
+	Error string `json:"error,omitempty"`
+}
+
+func dependencyHealth(d *builder.Dependency, r *http.Request) []dependencyHealthResponse {
+	res := []dependencyHealthResponse{}
+	for _, dep := range d.Health(r.Context()) {
+		res = append(res, dependencyHealthResponse{Name: dep.Name, Healthy: dep.Healthy, Error: dep.Error})
+	}
+
+	return res
+}
+
+type poolStats struct {
+	Healthy *bool `json:"healthy,omitempty"`
+
+	MaxConns          int32 `json:"max_conns"`
+	TotalConns        int32 `json:"total_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	ConstructingConns int32 `json:"constructing_conns"`
+
+	AcquireCount            int64         `json:"acquire_count"`
+	AcquireDuration         time.Duration `json:"acquire_duration_ns"`
+	EmptyAcquireCount       int64         `json:"empty_acquire_count"`
+	CanceledAcquireCount    int64         `json:"canceled_acquire_count"`
+	NewConnsCount           int64         `json:"new_conns_count"`
+	MaxLifetimeDestroyCount int64         `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64         `json:"max_idle_destroy_count"`
+}
+
+type dbStatsResponse struct {
+	Primary  *poolStats  `json:"primary"`
+	Replicas []poolStats `json:"replicas"`
+}
+
+func dbStats(d *builder.Dependency) dbStatsResponse {
+	res := dbStatsResponse{Replicas: []poolStats{}}
+	if d.DB != nil {
+		s := newPoolStats(d.DB.Stat())
+		res.Primary = &s
+	}
+	for _, r := range d.Replicas.Stats() {
+		s := newPoolStats(r.Pool)
+		s.Healthy = &r.Healthy
+		res.Replicas = append(res.Replicas, s)
+	}
+
+	return res
+}
+
+func newPoolStats(s *pgxpool.Stat) poolStats {
+	return poolStats{
+		MaxConns:                s.MaxConns(),
+		TotalConns:              s.TotalConns(),
+		AcquiredConns:           s.AcquiredConns(),
+		IdleConns:               s.IdleConns(),
+		ConstructingConns:       s.ConstructingConns(),
+		AcquireCount:            s.AcquireCount(),
+		AcquireDuration:         s.AcquireDuration(),
+		EmptyAcquireCount:       s.EmptyAcquireCount(),
+		CanceledAcquireCount:    s.CanceledAcquireCount(),
+		NewConnsCount:           s.NewConnsCount(),
+		MaxLifetimeDestroyCount: s.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     s.MaxIdleDestroyCount(),
+	}
+}
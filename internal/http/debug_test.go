@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var debugTargets = []string{"/debug/pprof/", "/debug/pprof/cmdline", "/debug/routes", "/debug/db", "/debug/health", "/debug/build", "/debug/config"}
+
+func TestDebugHandler(t *testing.T) {
+	t.Run("unreachable without the admin token", func(t *testing.T) {
+		admin, _, _ := newTestAdmin(t, testAdminToken)
+
+		for _, target := range debugTargets {
+			for _, token := range []string{"", "wrong"} {
+				rec := serve(admin, http.MethodGet, target, token)
+				assert.Equal(t, http.StatusUnauthorized, rec.Code, target)
+				assert.NotContains(t, rec.Body.String(), "goroutine", target)
+			}
+		}
+	})
+
+	t.Run("unreachable when no admin token is configured", func(t *testing.T) {
+		admin, _, _ := newTestAdmin(t, "")
+
+		for _, target := range debugTargets {
+			assert.Equal(t, http.StatusNotFound, serve(admin, http.MethodGet, target, "").Code, target)
+		}
+	})
+
+	t.Run("not served on the API port", func(t *testing.T) {
+		e := newTestEcho(t, testAdminToken)
+
+		for _, target := range debugTargets {
+			assert.Equal(t, http.StatusNotFound, serve(e, http.MethodGet, target, testAdminToken).Code, target)
+		}
+	})
+
+	t.Run("served with the admin token", func(t *testing.T) {
+		admin, _, _ := newTestAdmin(t, testAdminToken)
+
+		for _, target := range debugTargets {
+			assert.Equal(t, http.StatusOK, serve(admin, http.MethodGet, target, testAdminToken).Code, target)
+		}
+
+		// secrets such as the token itself are masked
+		rec := serve(admin, http.MethodGet, "/debug/config", testAdminToken)
+		assert.NotContains(t, rec.Body.String(), testAdminToken)
+	})
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+	"github.com/SoraDaibu/go-clean-starter/internal/http/handler"
+	"github.com/SoraDaibu/go-clean-starter/internal/service/audit"
+)
+
+func (a *AuditHandler) ListAuditEvents(c echo.Context) error {
+	input := &audit.ListAuditEventsInput{
+		EntityType: c.QueryParam("entity_type"),
+		Actor:      c.QueryParam("actor"),
+		Action:     c.QueryParam("action"),
+		Cursor:     c.QueryParam("cursor"),
+	}
+
+	var err error
+	if v := c.QueryParam("entity_id"); v != "" {
+		if input.EntityID, err = uuid.Parse(v); err != nil {
+			return base.HandleError(c, err)
+		}
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		if input.Limit, err = strconv.Atoi(v); err != nil {
+			return base.HandleError(c, audit.ErrInvalidLimit)
+		}
+	}
+	if v := c.QueryParam("since"); v != "" {
+		if input.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return base.HandleError(c, audit.ErrInvalidSince)
+		}
+	}
+	if v := c.QueryParam("until"); v != "" {
+		if input.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return base.HandleError(c, audit.ErrInvalidUntil)
+		}
+	}
+
+	output, err := a.usecase.ListAuditEvents(c.Request().Context(), input)
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	response := handler.AuditEventListResponse{Events: make([]handler.AuditEventResponse, len(output.Events))}
+	for i, event := range output.Events {
+		diff := make(map[string]handler.AuditChange, len(event.Diff))
+		for field, change := range event.Diff {
+			diff[field] = handler.AuditChange{Before: change.Before, After: change.After}
+		}
+
+		response.Events[i] = handler.AuditEventResponse{
+			Id:         event.ID,
+			Actor:      event.Actor,
+			EntityType: event.EntityType,
+			EntityId:   event.EntityID,
+			Action:     event.Action,
+			Diff:       diff,
+			CreatedAt:  event.CreatedAt,
+		}
+	}
+	if output.NextCursor != "" {
+		response.NextCursor = &output.NextCursor
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
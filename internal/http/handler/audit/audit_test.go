@@ -0,0 +1,49 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+	auditHandler "github.com/SoraDaibu/go-clean-starter/internal/http/handler/audit"
+	service "github.com/SoraDaibu/go-clean-starter/internal/service/audit"
+)
+
+func TestAuditHandler_ListAuditEvents(t *testing.T) {
+	// invalid parameters are rejected before the repository is used
+	h := auditHandler.NewAuditHandler(service.NewAuditUsecase(nil))
+	e := echo.New()
+	e.GET("/audit-events", h.ListAuditEvents)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected *base.ErrorDetail
+	}{
+		{name: "malformed since", query: "since=yesterday", expected: &base.ErrorDetail{Field: "since", Text: service.ErrInvalidSince.Text}},
+		{name: "malformed until", query: "since=2025-01-01T00:00:00Z&until=today", expected: &base.ErrorDetail{Field: "until", Text: service.ErrInvalidUntil.Text}},
+		{
+			name:     "since after until",
+			query:    "since=2025-01-02T00:00:00Z&until=2025-01-01T00:00:00Z",
+			expected: &base.ErrorDetail{Field: "since", Text: service.ErrInvalidPeriod.Text},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/audit-events?"+tt.query, nil))
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+			var res base.ErrorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.Equal(t, []*base.ErrorDetail{tt.expected}, res.Details)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package audit
+
+import (
+	"github.com/SoraDaibu/go-clean-starter/internal/service/audit"
+)
+
+type AuditHandler struct {
+	usecase audit.AuditUsecase
+}
+
+func NewAuditHandler(
+	usecase audit.AuditUsecase,
+) *AuditHandler {
+	return &AuditHandler{
+		usecase: usecase,
+	}
+}
@@ -1,6 +1,11 @@
 package handler
 
-import "github.com/SoraDaibu/go-clean-starter/internal/service/user"
+import (
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/service/user"
+	"github.com/SoraDaibu/go-clean-starter/internal/service/webhook"
+)
 
 func (r *CreateUserRequest) ToCreateUserInput() *user.CreateUserInput {
 	return &user.CreateUserInput{
@@ -9,3 +14,32 @@ func (r *CreateUserRequest) ToCreateUserInput() *user.CreateUserInput {
 		Password: r.Password,
 	}
 }
+
+func (r *UpdateUserRequest) ToUpdateUserInput(id uuid.UUID, versions []int32) *user.UpdateUserInput {
+	return &user.UpdateUserInput{
+		ID:       id,
+		Name:     r.Name,
+		Versions: versions,
+	}
+}
+
+func (r *CreateWebhookSubscriptionRequest) ToCreateSubscriptionInput() *webhook.CreateSubscriptionInput {
+	input := &webhook.CreateSubscriptionInput{URL: r.Url}
+	if r.EventTypes != nil {
+		input.EventTypes = *r.EventTypes
+	}
+	if r.Secret != nil {
+		input.Secret = *r.Secret
+	}
+
+	return input
+}
+
+func (r *UpdateWebhookSubscriptionRequest) ToUpdateSubscriptionInput(id uuid.UUID) *webhook.UpdateSubscriptionInput {
+	return &webhook.UpdateSubscriptionInput{
+		ID:         id,
+		URL:        r.Url,
+		EventTypes: r.EventTypes,
+		Enabled:    r.Enabled,
+	}
+}
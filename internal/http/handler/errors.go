@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/labstack/echo/v4"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
 )
 
 // ErrorResponse represents the structure of error responses
@@ -104,7 +107,7 @@ func HandleError(c echo.Context, err error) error {
 }
 
 func handleAppError(c echo.Context, appErr *AppError) error {
-	log.Error().Err(appErr).Msgf("handleAppError: processing error type %d with message: %s", appErr.Type, appErr.Message)
+	zerolog.Ctx(c.Request().Context()).Error().Err(appErr).Msgf("handleAppError: processing error type %d with message: %s", appErr.Type, appErr.Message)
 
 	var statusCode int
 	var errorCode string
@@ -133,32 +136,32 @@ func handleAppError(c echo.Context, appErr *AppError) error {
 		Message: appErr.Message,
 	}
 
-	log.Info().Msgf("handleAppError: returning status %d with response: %+v", statusCode, response)
+	zerolog.Ctx(c.Request().Context()).Info().Msgf("handleAppError: returning status %d with response: %+v", statusCode, response)
 	return c.JSON(statusCode, response)
 }
 
 func handleGenericError(c echo.Context, err error) error {
 	errMsg := err.Error()
-	log.Error().Err(err).Msgf("handleGenericError: processing error message: %s", errMsg)
+	zerolog.Ctx(c.Request().Context()).Error().Err(err).Msgf("handleGenericError: processing error message: %s", errMsg)
 
-	// Check for common database errors
-	if strings.Contains(errMsg, "duplicate key") || strings.Contains(errMsg, "unique constraint") {
+	// Check for domain errors translated by the repositories
+	if errors.Is(err, domain.ErrConflict) {
 		response := ErrorResponse{
 			Error:   "Resource already exists",
 			Code:    "CONFLICT",
 			Message: "A resource with this information already exists",
 		}
-		log.Error().Err(err).Msg("handleGenericError: detected duplicate key error, returning conflict")
+		zerolog.Ctx(c.Request().Context()).Error().Err(err).Msg("handleGenericError: detected conflict error, returning conflict")
 		return c.JSON(http.StatusConflict, response)
 	}
 
-	if strings.Contains(errMsg, "no rows in result set") || strings.Contains(errMsg, "not found") {
+	if errors.Is(err, domain.ErrNotFound) {
 		response := ErrorResponse{
 			Error:   "Resource not found",
 			Code:    "NOT_FOUND",
 			Message: "The requested resource was not found",
 		}
-		log.Error().Err(err).Msg("handleGenericError: detected not found error")
+		zerolog.Ctx(c.Request().Context()).Error().Err(err).Msg("handleGenericError: detected not found error")
 		return c.JSON(http.StatusNotFound, response)
 	}
 
@@ -169,7 +172,7 @@ func handleGenericError(c echo.Context, err error) error {
 			Code:    "BAD_REQUEST",
 			Message: "The provided ID is not a valid UUID",
 		}
-		log.Error().Err(err).Msg("handleGenericError: detected UUID parsing error")
+		zerolog.Ctx(c.Request().Context()).Error().Err(err).Msg("handleGenericError: detected UUID parsing error")
 		return c.JSON(http.StatusBadRequest, response)
 	}
 
@@ -180,7 +183,7 @@ func handleGenericError(c echo.Context, err error) error {
 			Code:    "BAD_REQUEST",
 			Message: "The request body contains invalid JSON",
 		}
-		log.Error().Err(err).Msg("handleGenericError: detected JSON binding error")
+		zerolog.Ctx(c.Request().Context()).Error().Err(err).Msg("handleGenericError: detected JSON binding error")
 		return c.JSON(http.StatusBadRequest, response)
 	}
 
@@ -190,6 +193,6 @@ func handleGenericError(c echo.Context, err error) error {
 		Code:    "INTERNAL_ERROR",
 		Message: "An unexpected error occurred",
 	}
-	log.Error().Err(err).Msg("handleGenericError: defaulting to internal server error")
+	zerolog.Ctx(c.Request().Context()).Error().Err(err).Msg("handleGenericError: defaulting to internal server error")
 	return c.JSON(http.StatusInternalServerError, response)
 }
@@ -0,0 +1,17 @@
+package item
+
+import (
+	"github.com/SoraDaibu/go-clean-starter/internal/service/item"
+)
+
+type ItemHandler struct {
+	usecase item.ItemUsecase
+}
+
+func NewItemHandler(
+	usecase item.ItemUsecase,
+) *ItemHandler {
+	return &ItemHandler{
+		usecase: usecase,
+	}
+}
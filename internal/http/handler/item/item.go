@@ -0,0 +1,110 @@
+package item
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+	"github.com/SoraDaibu/go-clean-starter/internal/http/handler"
+	"github.com/SoraDaibu/go-clean-starter/internal/service/item"
+)
+
+func (h *ItemHandler) ListItems(c echo.Context) error {
+	query, err := base.ParseQuery(c.QueryParams(), domain.ItemQuerySchema)
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	input := &item.ListItemsInput{Query: query, Cursor: c.QueryParam("cursor")}
+	if v := c.QueryParam("limit"); v != "" {
+		if input.Limit, err = strconv.Atoi(v); err != nil {
+			return base.HandleError(c, item.ErrInvalidLimit)
+		}
+	}
+
+	output, err := h.usecase.ListItems(c.Request().Context(), input)
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	response := handler.ItemListResponse{Items: make([]handler.ItemResponse, len(output.Items))}
+	for i, item := range output.Items {
+		response.Items[i] = handler.ItemResponse{
+			Id:          item.ID,
+			TypeId:      int(item.TypeID),
+			Name:        item.Name,
+			Description: item.Description,
+		}
+	}
+	if output.NextCursor != "" {
+		response.NextCursor = &output.NextCursor
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *ItemHandler) SearchItems(c echo.Context) error {
+	input := &item.SearchItemsInput{
+		Query:  c.QueryParam("q"),
+		Sort:   c.QueryParam("sort"),
+		Cursor: c.QueryParam("cursor"),
+	}
+
+	var err error
+	// type can be repeated and each can be a comma-separated list
+	for _, v := range c.QueryParams()["type"] {
+		for _, s := range strings.Split(v, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+			if err != nil || id == 0 {
+				return base.HandleError(c, item.ErrInvalidType)
+			}
+			input.TypeIDs = append(input.TypeIDs, uint(id))
+		}
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		if input.Limit, err = strconv.Atoi(v); err != nil {
+			return base.HandleError(c, item.ErrInvalidLimit)
+		}
+	}
+	if v := c.QueryParam("created_from"); v != "" {
+		if input.CreatedFrom, err = time.Parse(time.RFC3339, v); err != nil {
+			return base.HandleError(c, item.ErrInvalidTime)
+		}
+	}
+	if v := c.QueryParam("created_to"); v != "" {
+		if input.CreatedTo, err = time.Parse(time.RFC3339, v); err != nil {
+			return base.HandleError(c, item.ErrInvalidTime)
+		}
+	}
+
+	output, err := h.usecase.SearchItems(c.Request().Context(), input)
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	response := handler.ItemSearchResponse{Items: make([]handler.ItemSearchResult, len(output.Items))}
+	for i, result := range output.Items {
+		response.Items[i] = handler.ItemSearchResult{
+			Id:          result.ID,
+			TypeId:      int(result.TypeID),
+			Name:        result.Name,
+			Description: result.Description,
+			CreatedAt:   result.CreatedAt,
+			Score:       result.Score,
+			Highlights: handler.ItemHighlights{
+				Name:        result.NameHighlight,
+				Description: result.DescriptionHighlight,
+			},
+		}
+	}
+	if output.NextCursor != "" {
+		response.NextCursor = &output.NextCursor
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
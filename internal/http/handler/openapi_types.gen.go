@@ -4,9 +4,56 @@
 package handler
 
 import (
+	"time"
+
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+const (
+	AdminTokenScopes = "adminToken.Scopes"
+)
+
+// AuditChange Old and new value of a changed field
+type AuditChange struct {
+	// After Value after the change, null when the field was removed
+	After interface{} `json:"after"`
+
+	// Before Value before the change, null when the field was added
+	Before interface{} `json:"before"`
+}
+
+// AuditEventListResponse Page of audit events, newest first
+type AuditEventListResponse struct {
+	Events []AuditEventResponse `json:"events"`
+
+	// NextCursor Cursor of the next page, absent on the last page
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// AuditEventResponse Recorded change of an entity
+type AuditEventResponse struct {
+	// Action What was done to the entity
+	Action string `json:"action"`
+
+	// Actor ID of the user who made the change, `anonymous` or `system`
+	Actor string `json:"actor"`
+
+	// CreatedAt When the change was made
+	CreatedAt time.Time `json:"created_at"`
+
+	// Diff Changed fields by name
+	Diff map[string]AuditChange `json:"diff"`
+
+	// EntityId ID of the changed entity, null for events about several entities such as imports
+	EntityId *openapi_types.UUID `json:"entity_id"`
+
+	// EntityType Type of the changed entity
+	EntityType string `json:"entity_type"`
+
+	// Id Unique identifier of the event
+	Id int64 `json:"id"`
+}
+
 // CreateUserRequest defines model for CreateUserRequest.
 type CreateUserRequest struct {
 	// Email User's email address
@@ -25,11 +72,127 @@ type CreateUserResponse struct {
 	Id openapi_types.UUID `json:"id"`
 }
 
+// CreateWebhookSubscriptionRequest defines model for CreateWebhookSubscriptionRequest.
+type CreateWebhookSubscriptionRequest struct {
+	// EventTypes Event types to send, such as `user.created`, `user.*` or `*`. Every event is sent when empty.
+	EventTypes *[]string `json:"event_types,omitempty"`
+
+	// Secret Secret signing the deliveries, at least 16 characters. Generated when absent.
+	Secret *string `json:"secret,omitempty"`
+
+	// Url Endpoint receiving the deliveries
+	Url string `json:"url"`
+}
+
+// DependencyHealth Health of a dependency
+type DependencyHealth struct {
+	Healthy bool   `json:"healthy"`
+	Name    string `json:"name"`
+}
+
 // ErrorMessage defines model for ErrorMessage.
 type ErrorMessage struct {
 	Message string `json:"message"`
 }
 
+// HealthResponse Health of the API and its dependencies
+type HealthResponse struct {
+	// Dependencies Started dependencies in start order
+	Dependencies []DependencyHealth `json:"dependencies"`
+
+	// Status OK, or UNAVAILABLE while a dependency is unhealthy
+	Status string `json:"status"`
+}
+
+// ItemHighlights HTML excerpts of the name and description, escaped, with the matching words wrapped in <mark> and </mark>. Insert them as HTML, not as text.
+type ItemHighlights struct {
+	// Description Excerpts of the description around the matches, empty when it has none
+	Description string `json:"description"`
+
+	// Name Name with the matching words marked
+	Name string `json:"name"`
+}
+
+// ItemListResponse Page of items
+type ItemListResponse struct {
+	Items []ItemResponse `json:"items"`
+
+	// NextCursor Cursor of the next page, absent on the last page
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// ItemResponse Item representation
+type ItemResponse struct {
+	// Description Description of the item
+	Description string `json:"description"`
+
+	// Id Unique identifier of the item
+	Id openapi_types.UUID `json:"id"`
+
+	// Name Name of the item
+	Name string `json:"name"`
+
+	// TypeId ID of the item type
+	TypeId int `json:"type_id"`
+}
+
+// ItemSearchResponse Page of items matching a search
+type ItemSearchResponse struct {
+	Items []ItemSearchResult `json:"items"`
+
+	// NextCursor Cursor of the next page, absent on the last page
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// ItemSearchResult Item matching a search
+type ItemSearchResult struct {
+	// CreatedAt When the item was created
+	CreatedAt time.Time `json:"created_at"`
+
+	// Description Description of the item
+	Description string `json:"description"`
+
+	// Highlights HTML excerpts of the name and description, escaped, with the matching words wrapped in <mark> and </mark>. Insert them as HTML, not as text.
+	Highlights ItemHighlights `json:"highlights"`
+
+	// Id Unique identifier of the item
+	Id openapi_types.UUID `json:"id"`
+
+	// Name Name of the item
+	Name string `json:"name"`
+
+	// Score Relevance of the item to the query, higher is better
+	Score float32 `json:"score"`
+
+	// TypeId ID of the item type
+	TypeId int `json:"type_id"`
+}
+
+// UpdateUserRequest defines model for UpdateUserRequest.
+type UpdateUserRequest struct {
+	// Name User's full name
+	Name string `json:"name"`
+}
+
+// UpdateWebhookSubscriptionRequest defines model for UpdateWebhookSubscriptionRequest.
+type UpdateWebhookSubscriptionRequest struct {
+	// Enabled Whether deliveries are sent
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// EventTypes Event types to send. Every event is sent when empty.
+	EventTypes *[]string `json:"event_types,omitempty"`
+
+	// Url Endpoint receiving the deliveries
+	Url *string `json:"url,omitempty"`
+}
+
+// UserListResponse Page of users
+type UserListResponse struct {
+	// NextCursor Cursor of the next page, absent on the last page
+	NextCursor *string        `json:"next_cursor,omitempty"`
+	Users      []UserResponse `json:"users"`
+}
+
 // UserResponse User representation
 type UserResponse struct {
 	// Id Unique identifier for the user
@@ -39,9 +202,87 @@ type UserResponse struct {
 	Name string `json:"name"`
 }
 
+// WebhookDeliveryListResponse Page of deliveries, newest first
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+
+	// NextCursor Cursor of the next page, absent on the last page
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// WebhookDeliveryResponse Event sent to a subscription and the outcome of its last attempt
+type WebhookDeliveryResponse struct {
+	Attempts  int32     `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// EventId Identifier of the event, sent as X-Event-ID
+	EventId   int64  `json:"event_id"`
+	EventType string `json:"event_type"`
+
+	// Id Unique identifier of the delivery, sent as X-Webhook-ID
+	Id            int64      `json:"id"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+
+	// LastError Why the last attempt failed
+	LastError *string `json:"last_error,omitempty"`
+
+	// NextAttemptAt When a pending delivery is sent next
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+
+	// ResponseCode HTTP status of the last attempt, null when no response was received
+	ResponseCode *int `json:"response_code"`
+
+	// Status pending, succeeded or failed once out of attempts
+	Status         string             `json:"status"`
+	SubscriptionId openapi_types.UUID `json:"subscription_id"`
+}
+
+// WebhookSubscriptionListResponse Webhook subscriptions, oldest first
+type WebhookSubscriptionListResponse struct {
+	Subscriptions []WebhookSubscriptionResponse `json:"subscriptions"`
+}
+
+// WebhookSubscriptionResponse Endpoint receiving events
+type WebhookSubscriptionResponse struct {
+	// ConsecutiveFailures Failed attempts since the last successful one
+	ConsecutiveFailures int32     `json:"consecutive_failures"`
+	CreatedAt           time.Time `json:"created_at"`
+
+	// DisabledAt When the subscription was disabled after repeated failures
+	DisabledAt *time.Time `json:"disabled_at"`
+
+	// Enabled Whether deliveries are sent
+	Enabled bool `json:"enabled"`
+
+	// EventTypes Event types sent, every event when empty
+	EventTypes []string `json:"event_types"`
+
+	// Id Unique identifier of the subscription
+	Id openapi_types.UUID `json:"id"`
+
+	// Secret Secret signing the deliveries, only returned on creation
+	Secret    *string   `json:"secret,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Url Endpoint receiving the deliveries
+	Url string `json:"url"`
+}
+
+// IdempotencyKey defines model for idempotency_key.
+type IdempotencyKey = string
+
+// IfMatch defines model for if_match.
+type IfMatch = string
+
+// IfNoneMatch defines model for if_none_match.
+type IfNoneMatch = string
+
 // UserId defines model for user_id.
 type UserId = openapi_types.UUID
 
+// WebhookId defines model for webhook_id.
+type WebhookId = openapi_types.UUID
+
 // N400 defines model for 400.
 type N400 = ErrorMessage
 
@@ -51,8 +292,169 @@ type N404 = ErrorMessage
 // N409 defines model for 409.
 type N409 = ErrorMessage
 
+// N412 defines model for 412.
+type N412 = ErrorMessage
+
+// N422 defines model for 422.
+type N422 = ErrorMessage
+
+// N428 defines model for 428.
+type N428 = ErrorMessage
+
 // N500 defines model for 500.
 type N500 = ErrorMessage
 
+// CreateUserParams defines parameters for CreateUser.
+type CreateUserParams struct {
+	// IdempotencyKey Unique key making the request safe to retry. Retries with the same key replay the first response
+	// (marked with an `Idempotent-Replayed: true` header) instead of running the request again.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// ListUsersParams defines parameters for ListUsers.
+type ListUsersParams struct {
+	// Filter Filters as `filter[field][operator]=value`, all of which have to match.
+	// `filter[field]=value` is short for `filter[field][eq]=value` and `in` takes a comma-separated list.
+	// Strings are compared ignoring case by `contains` and `prefix`; times are RFC 3339 timestamps.
+	//
+	// | Field | Operators |
+	// |-------|-----------|
+	// | `id` | `eq`, `ne`, `in` |
+	// | `name` | `eq`, `ne`, `contains`, `prefix`, `in` |
+	// | `created_at`, `updated_at` | `gt`, `gte`, `lt`, `lte` |
+	Filter *map[string]interface{} `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Sort Comma-separated fields to sort by, each prefixed with `-` for a descending order: `name`, `created_at`, `updated_at`.
+	Sort *string `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Cursor next_cursor of the previous page, with the same filters and sort
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of users to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// CreateWebhookSubscriptionParams defines parameters for CreateWebhookSubscription.
+type CreateWebhookSubscriptionParams struct {
+	// IdempotencyKey Unique key making the request safe to retry. Retries with the same key replay the first response
+	// (marked with an `Idempotent-Replayed: true` header) instead of running the request again.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// ListAuditEventsParams defines parameters for ListAuditEvents.
+type ListAuditEventsParams struct {
+	// EntityType Only events of this entity type
+	EntityType *string `form:"entity_type,omitempty" json:"entity_type,omitempty"`
+
+	// EntityId Only events of this entity
+	EntityId *openapi_types.UUID `form:"entity_id,omitempty" json:"entity_id,omitempty"`
+
+	// Actor Only events made by this actor
+	Actor *string `form:"actor,omitempty" json:"actor,omitempty"`
+
+	// Action Only events with this action
+	Action *string `form:"action,omitempty" json:"action,omitempty"`
+
+	// Since Only events at or after this time
+	Since *time.Time `form:"since,omitempty" json:"since,omitempty"`
+
+	// Until Only events before this time
+	Until *time.Time `form:"until,omitempty" json:"until,omitempty"`
+
+	// Cursor next_cursor of the previous page
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of events to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ListItemsParams defines parameters for ListItems.
+type ListItemsParams struct {
+	// Filter Filters as `filter[field][operator]=value`, all of which have to match.
+	// `filter[field]=value` is short for `filter[field][eq]=value` and `in` takes a comma-separated list.
+	// Strings are compared ignoring case by `contains` and `prefix`; times are RFC 3339 timestamps.
+	//
+	// | Field | Operators |
+	// |-------|-----------|
+	// | `id` | `eq`, `ne`, `in` |
+	// | `type_id` | `eq`, `ne`, `in` |
+	// | `name` | `eq`, `ne`, `contains`, `prefix`, `in` |
+	// | `description` | `contains` |
+	// | `created_at`, `updated_at` | `gt`, `gte`, `lt`, `lte` |
+	Filter *map[string]interface{} `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Sort Comma-separated fields to sort by, each prefixed with `-` for a descending order: `name`, `created_at`, `updated_at`.
+	Sort *string `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Cursor next_cursor of the previous page, with the same filters and sort
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of items to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// SearchItemsParams defines parameters for SearchItems.
+type SearchItemsParams struct {
+	// Q Words to find in names and descriptions, in web search syntax
+	Q string `form:"q" json:"q"`
+
+	// Type Only items of these types
+	Type *[]int `form:"type,omitempty" json:"type,omitempty"`
+
+	// CreatedFrom Only items created at or after this time
+	CreatedFrom *time.Time `form:"created_from,omitempty" json:"created_from,omitempty"`
+
+	// CreatedTo Only items created before this time
+	CreatedTo *time.Time `form:"created_to,omitempty" json:"created_to,omitempty"`
+
+	// Sort Order of the results
+	Sort *string `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Cursor next_cursor of the previous page
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of items to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// DeleteUserParams defines parameters for DeleteUser.
+type DeleteUserParams struct {
+	// IfMatch ETag of the version the change is based on, or `*` for any version
+	IfMatch IfMatch `json:"If-Match"`
+}
+
+// GetUserByIdParams defines parameters for GetUserById.
+type GetUserByIdParams struct {
+	// IfNoneMatch ETags the client already has; the server answers 304 when one of them is current
+	IfNoneMatch *IfNoneMatch `json:"If-None-Match,omitempty"`
+}
+
+// UpdateUserParams defines parameters for UpdateUser.
+type UpdateUserParams struct {
+	// IfMatch ETag of the version the change is based on, or `*` for any version
+	IfMatch IfMatch `json:"If-Match"`
+}
+
+// ListWebhookDeliveriesParams defines parameters for ListWebhookDeliveries.
+type ListWebhookDeliveriesParams struct {
+	// Status Only deliveries with this status
+	Status *string `form:"status,omitempty" json:"status,omitempty"`
+
+	// Cursor next_cursor of the previous page
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of deliveries to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
 // CreateUserJSONRequestBody defines body for CreateUser for application/json ContentType.
 type CreateUserJSONRequestBody = CreateUserRequest
+
+// UpdateUserJSONRequestBody defines body for UpdateUser for application/json ContentType.
+type UpdateUserJSONRequestBody = UpdateUserRequest
+
+// CreateWebhookSubscriptionJSONRequestBody defines body for CreateWebhookSubscription for application/json ContentType.
+type CreateWebhookSubscriptionJSONRequestBody = CreateWebhookSubscriptionRequest
+
+// UpdateWebhookSubscriptionJSONRequestBody defines body for UpdateWebhookSubscription for application/json ContentType.
+type UpdateWebhookSubscriptionJSONRequestBody = UpdateWebhookSubscriptionRequest
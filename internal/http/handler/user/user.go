@@ -2,12 +2,15 @@ package user
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	"github.com/SoraDaibu/go-clean-starter/domain"
 	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
 	"github.com/SoraDaibu/go-clean-starter/internal/http/handler"
+	"github.com/SoraDaibu/go-clean-starter/internal/service/user"
 )
 
 func (u *UserHandler) GetUser(c echo.Context) error {
@@ -21,12 +24,50 @@ func (u *UserHandler) GetUser(c echo.Context) error {
 		return base.HandleError(c, err)
 	}
 
+	etag := base.ETag(user.Version)
+	c.Response().Header().Set(base.HeaderETag, etag)
+	if base.NotModified(c, etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
 	return c.JSON(http.StatusOK, handler.UserResponse{
 		Id:   user.ID,
 		Name: user.Name,
 	})
 }
 
+func (u *UserHandler) ListUsers(c echo.Context) error {
+	query, err := base.ParseQuery(c.QueryParams(), domain.UserQuerySchema)
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	input := &user.ListUsersInput{Query: query, Cursor: c.QueryParam("cursor")}
+	if v := c.QueryParam("limit"); v != "" {
+		if input.Limit, err = strconv.Atoi(v); err != nil {
+			return base.HandleError(c, user.ErrInvalidLimit)
+		}
+	}
+
+	output, err := u.usecase.ListUsers(c.Request().Context(), input)
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	response := handler.UserListResponse{Users: make([]handler.UserResponse, len(output.Users))}
+	for i, user := range output.Users {
+		response.Users[i] = handler.UserResponse{
+			Id:   user.ID,
+			Name: user.Name,
+		}
+	}
+	if output.NextCursor != "" {
+		response.NextCursor = &output.NextCursor
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
 func (u *UserHandler) CreateUser(c echo.Context) error {
 	var req handler.CreateUserRequest
 	if err := base.Bind(c, &req); err != nil {
@@ -38,8 +79,57 @@ func (u *UserHandler) CreateUser(c echo.Context) error {
 		return base.HandleError(c, err)
 	}
 
+	c.Response().Header().Set(base.HeaderETag, base.ETag(user.Version))
+
 	return c.JSON(http.StatusCreated, handler.UserResponse{
 		Id:   user.ID,
 		Name: user.Name,
 	})
 }
+
+func (u *UserHandler) UpdateUser(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	versions, err := base.IfMatch(c)
+	if err != nil {
+		return err
+	}
+
+	var req handler.UpdateUserRequest
+	if err := base.Bind(c, &req); err != nil {
+		return err
+	}
+
+	user, err := u.usecase.UpdateUser(c.Request().Context(), req.ToUpdateUserInput(userID, versions))
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	c.Response().Header().Set(base.HeaderETag, base.ETag(user.Version))
+
+	return c.JSON(http.StatusOK, handler.UserResponse{
+		Id:   user.ID,
+		Name: user.Name,
+	})
+}
+
+func (u *UserHandler) DeleteUser(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	versions, err := base.IfMatch(c)
+	if err != nil {
+		return err
+	}
+
+	if err := u.usecase.DeleteUser(c.Request().Context(), &user.DeleteUserInput{ID: userID, Versions: versions}); err != nil {
+		return base.HandleError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -49,9 +49,7 @@ func setupTestDependencies(t *testing.T) (*builder.Dependency, func()) {
 	require.NoError(t, err)
 
 	cleanup := func() {
-		if dependency.DB != nil {
-			dependency.DB.Close()
-		}
+		assert.NoError(t, dependency.Close())
 	}
 
 	return dependency, cleanup
@@ -308,6 +306,62 @@ func TestUserHandler_GetUser(t *testing.T) {
 	}
 }
 
+func TestUserHandler_UpdateUser(t *testing.T) {
+	dependency, cleanup := setupTestDependencies(t)
+	defer cleanup()
+
+	handler := builder.InitializeUserHandler(dependency)
+	e := echo.New()
+
+	createdUser := createTestUser(t, handler, e, map[string]string{
+		"name":     "Versioned User",
+		"email":    fmt.Sprintf("versioned-%s@example.com", uuid.New().String()),
+		"password": "password123",
+	})
+	userID := createdUser["id"].(string)
+
+	update := func(ifMatch string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"name": "Renamed User"})
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(httptest.NewRequest(http.MethodPatch, "/users/"+userID, bytes.NewReader(body)), rec)
+		ctx.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if ifMatch != "" {
+			ctx.Request().Header.Set("If-Match", ifMatch)
+		}
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(userID)
+
+		handler.UpdateUser(ctx)
+		return rec
+	}
+
+	// missing If-Match
+	assert.Equal(t, http.StatusPreconditionRequired, update("").Code)
+
+	// first writer wins and gets the next version
+	rec := update(`"1"`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+	// second writer based on the same version is rejected
+	assert.Equal(t, http.StatusPreconditionFailed, update(`"1"`).Code)
+
+	// a list matches when any of its ETags does
+	rec = update(`"1", "2"`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+	// conditional GET with the current ETag
+	getRec := httptest.NewRecorder()
+	getCtx := e.NewContext(httptest.NewRequest(http.MethodGet, "/users/"+userID, nil), getRec)
+	getCtx.Request().Header.Set("If-None-Match", `"3"`)
+	getCtx.SetParamNames("id")
+	getCtx.SetParamValues(userID)
+
+	require.NoError(t, handler.GetUser(getCtx))
+	assert.Equal(t, http.StatusNotModified, getRec.Code)
+}
+
 func createTestUser(t *testing.T, handler *user.UserHandler, e *echo.Echo, requestBody map[string]string) map[string]interface{} {
 	body, _ := json.Marshal(requestBody)
 
@@ -0,0 +1,17 @@
+package webhook
+
+import (
+	"github.com/SoraDaibu/go-clean-starter/internal/service/webhook"
+)
+
+type WebhookHandler struct {
+	usecase webhook.WebhookUsecase
+}
+
+func NewWebhookHandler(
+	usecase webhook.WebhookUsecase,
+) *WebhookHandler {
+	return &WebhookHandler{
+		usecase: usecase,
+	}
+}
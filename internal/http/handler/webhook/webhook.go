@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+	"github.com/SoraDaibu/go-clean-starter/internal/http/handler"
+	"github.com/SoraDaibu/go-clean-starter/internal/service/webhook"
+)
+
+func (w *WebhookHandler) CreateSubscription(c echo.Context) error {
+	var req handler.CreateWebhookSubscriptionRequest
+	if err := base.Bind(c, &req); err != nil {
+		return err
+	}
+
+	output, err := w.usecase.CreateSubscription(c.Request().Context(), req.ToCreateSubscriptionInput())
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	response := toSubscriptionResponse(output.SubscriptionOutput)
+	response.Secret = &output.Secret
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+func (w *WebhookHandler) ListSubscriptions(c echo.Context) error {
+	outputs, err := w.usecase.ListSubscriptions(c.Request().Context())
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	response := handler.WebhookSubscriptionListResponse{Subscriptions: make([]handler.WebhookSubscriptionResponse, len(outputs))}
+	for i, output := range outputs {
+		response.Subscriptions[i] = toSubscriptionResponse(output)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (w *WebhookHandler) GetSubscription(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	output, err := w.usecase.GetSubscription(c.Request().Context(), id)
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toSubscriptionResponse(output))
+}
+
+func (w *WebhookHandler) UpdateSubscription(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	var req handler.UpdateWebhookSubscriptionRequest
+	if err := base.Bind(c, &req); err != nil {
+		return err
+	}
+
+	output, err := w.usecase.UpdateSubscription(c.Request().Context(), req.ToUpdateSubscriptionInput(id))
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, toSubscriptionResponse(output))
+}
+
+func (w *WebhookHandler) DeleteSubscription(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	if err := w.usecase.DeleteSubscription(c.Request().Context(), id); err != nil {
+		return base.HandleError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (w *WebhookHandler) ListDeliveries(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	input := &webhook.ListDeliveriesInput{
+		SubscriptionID: id,
+		Status:         c.QueryParam("status"),
+		Cursor:         c.QueryParam("cursor"),
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		if input.Limit, err = strconv.Atoi(v); err != nil {
+			return base.HandleError(c, webhook.ErrInvalidLimit)
+		}
+	}
+
+	output, err := w.usecase.ListDeliveries(c.Request().Context(), input)
+	if err != nil {
+		return base.HandleError(c, err)
+	}
+
+	response := handler.WebhookDeliveryListResponse{Deliveries: make([]handler.WebhookDeliveryResponse, len(output.Deliveries))}
+	for i, delivery := range output.Deliveries {
+		response.Deliveries[i] = handler.WebhookDeliveryResponse{
+			Id:             delivery.ID,
+			SubscriptionId: delivery.SubscriptionID,
+			EventId:        delivery.EventID,
+			EventType:      delivery.EventType,
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			NextAttemptAt:  delivery.NextAttemptAt,
+			LastAttemptAt:  delivery.LastAttemptAt,
+			ResponseCode:   delivery.ResponseCode,
+			CreatedAt:      delivery.CreatedAt,
+		}
+		if delivery.LastError != "" {
+			response.Deliveries[i].LastError = &delivery.LastError
+		}
+	}
+	if output.NextCursor != "" {
+		response.NextCursor = &output.NextCursor
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func toSubscriptionResponse(output *webhook.SubscriptionOutput) handler.WebhookSubscriptionResponse {
+	return handler.WebhookSubscriptionResponse{
+		Id:                  output.ID,
+		Url:                 output.URL,
+		EventTypes:          output.EventTypes,
+		Enabled:             output.Enabled,
+		ConsecutiveFailures: output.ConsecutiveFailures,
+		DisabledAt:          output.DisabledAt,
+		CreatedAt:           output.CreatedAt,
+		UpdatedAt:           output.UpdatedAt,
+	}
+}
@@ -0,0 +1,111 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+	"github.com/SoraDaibu/go-clean-starter/internal/http/handler"
+	webhookHandler "github.com/SoraDaibu/go-clean-starter/internal/http/handler/webhook"
+	service "github.com/SoraDaibu/go-clean-starter/internal/service/webhook"
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+)
+
+// fakeRepository keeps subscriptions in memory; only what the handlers below use is implemented
+type fakeRepository struct {
+	webhook.Repository
+	subscriptions map[uuid.UUID]*webhook.Subscription
+}
+
+func (r *fakeRepository) CreateSubscription(_ context.Context, subscription *webhook.Subscription) (*webhook.Subscription, error) {
+	created := *subscription
+	created.Enabled = true
+	r.subscriptions[created.ID] = &created
+	return &created, nil
+}
+
+func (r *fakeRepository) GetSubscription(_ context.Context, id uuid.UUID) (*webhook.Subscription, error) {
+	s, ok := r.subscriptions[id]
+	if !ok {
+		return nil, webhook.ErrSubscriptionNotFound
+	}
+	return s, nil
+}
+
+func newEcho() (*echo.Echo, *fakeRepository) {
+	repo := &fakeRepository{subscriptions: map[uuid.UUID]*webhook.Subscription{}}
+	h := webhookHandler.NewWebhookHandler(service.NewWebhookUsecase(repo, false))
+
+	e := echo.New()
+	e.POST("/webhooks", h.CreateSubscription)
+	e.GET("/webhooks/:id", h.GetSubscription)
+	e.GET("/webhooks/:id/deliveries", h.ListDeliveries)
+
+	return e, repo
+}
+
+func serve(e *echo.Echo, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestWebhookHandler_CreateSubscription(t *testing.T) {
+	t.Run("returns the secret once", func(t *testing.T) {
+		e, repo := newEcho()
+
+		rec := serve(e, http.MethodPost, "/webhooks", `{"url":"https://partner.example.com/hooks","event_types":["user.created"]}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		var created handler.WebhookSubscriptionResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		require.NotNil(t, created.Secret)
+		assert.Equal(t, repo.subscriptions[created.Id].Secret, *created.Secret)
+		assert.Equal(t, []string{"user.created"}, created.EventTypes)
+
+		rec = serve(e, http.MethodGet, "/webhooks/"+created.Id.String(), "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), *created.Secret)
+	})
+
+	t.Run("rejects a private URL", func(t *testing.T) {
+		e, repo := newEcho()
+
+		rec := serve(e, http.MethodPost, "/webhooks", `{"url":"http://169.254.169.254/latest/meta-data"}`)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var res base.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		require.Len(t, res.Details, 1)
+		assert.Equal(t, "url", res.Details[0].Field)
+		assert.Equal(t, service.ErrPrivateURL.Text, res.Details[0].Text)
+		assert.Empty(t, repo.subscriptions)
+	})
+}
+
+func TestWebhookHandler_GetSubscription(t *testing.T) {
+	e, _ := newEcho()
+
+	assert.Equal(t, http.StatusNotFound, serve(e, http.MethodGet, "/webhooks/"+uuid.NewString(), "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(e, http.MethodGet, "/webhooks/not-a-uuid", "").Code)
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	e, _ := newEcho()
+
+	rec := serve(e, http.MethodGet, "/webhooks/"+uuid.NewString()+"/deliveries?limit=ten", "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"field":"limit"`)
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+)
+
+// AuditActor attributes changes made by unauthenticated requests to audit.ActorAnonymous
+// rather than audit.ActorSystem. AddUserID replaces it with the authenticated user.
+func AuditActor() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(audit.WithActor(req.Context(), audit.ActorAnonymous)))
+
+			return next(c)
+		}
+	}
+}
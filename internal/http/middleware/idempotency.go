@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/backoff"
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+	"github.com/SoraDaibu/go-clean-starter/internal/idempotency"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks responses replayed from a previous request
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 200
+)
+
+// replayedHeaders are the headers of a stored response that describe its content, and are replayed with it.
+// Others, such as X-Request-Id or RateLimit-*, belong to the request that produced it.
+var replayedHeaders = []string{
+	echo.HeaderContentType,
+	echo.HeaderContentEncoding,
+	"Content-Language",
+	echo.HeaderLocation,
+	"ETag",
+	echo.HeaderLastModified,
+}
+
+// Idempotency makes requests sent with an Idempotency-Key header safe to retry.
+// The first request with a key claims it for lockTimeout, runs the handler and stores its response for ttl;
+// retries replay it. A retry sent while the first request runs waits for its response, up to lockTimeout or
+// the deadline of the retry, and is rejected with 409 when it gave up; one whose method, path or body differ
+// from the first request is rejected with 422.
+// 4xx responses are stored like 2xx. 5xx responses and errors returned before anything was written are not,
+// so a retry runs the handler again. No connection is held while the handler runs or while a retry waits;
+// a key whose request stopped without a response, e.g. in a crashed instance, is taken over after lockTimeout.
+func Idempotency(store idempotency.Store, ttl, lockTimeout time.Duration) echo.MiddlewareFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return h(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return idempotencyError(c, http.StatusBadRequest, "must be at most 200 characters")
+			}
+
+			// keys are only unique per client
+			if userID, ok := c.Get(ContextKeyUserID).(string); ok && userID != "" {
+				key = userID + ":" + key
+			}
+
+			fingerprint, err := requestFingerprint(c)
+			if err != nil {
+				return err
+			}
+
+			ctx := c.Request().Context()
+			record, claimed, err := claimOrWait(ctx, store, key, fingerprint, ttl, lockTimeout)
+			if err != nil {
+				return err
+			}
+
+			if !claimed {
+				switch {
+				case record.Fingerprint != fingerprint:
+					return idempotencyError(c, http.StatusUnprocessableEntity, "key was already used for a different request")
+				case record.Completed():
+					zerolog.Ctx(ctx).Info().Msg("replaying idempotent response")
+					return replay(c, record)
+				default:
+					c.Response().Header().Set(echo.HeaderRetryAfter, "1")
+					return idempotencyError(c, http.StatusConflict, "a request with this key is in progress")
+				}
+			}
+
+			rec := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+			handlerErr := h(c)
+			c.Response().Writer = rec.ResponseWriter
+
+			// the outcome is recorded even when the client went away, as retries depend on it
+			ctx = context.WithoutCancel(ctx)
+			status := responseStatus(c, handlerErr)
+			if status >= http.StatusInternalServerError || !c.Response().Committed {
+				if err := store.Release(ctx, key); err != nil {
+					zerolog.Ctx(ctx).Error().Err(err).Msg("failed to release idempotency key")
+				}
+				return handlerErr
+			}
+
+			// errors written by the handler, such as validation errors, are replayed like successes
+			if err := store.Save(ctx, key, status, contentHeader(c.Response().Header()), rec.body.Bytes()); err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("failed to save idempotent response")
+			}
+
+			return handlerErr
+		}
+	}
+}
+
+// claimOrWait claims key, or polls it while another request with the same fingerprint holds it,
+// until that request completed, its key was released or taken over, or lockTimeout or ctx ended the wait.
+// The record is returned in progress when the wait ended.
+func claimOrWait(ctx context.Context, store idempotency.Store, key, fingerprint string, ttl, lockTimeout time.Duration) (*idempotency.Record, bool, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		now := time.Now()
+		record, claimed, err := store.Claim(ctx, key, fingerprint, now.Add(lockTimeout), now.Add(ttl))
+		if err != nil || claimed || record.Fingerprint != fingerprint || record.Completed() {
+			return record, claimed, err
+		}
+
+		timer := time.NewTimer(backoff.Delay(attempt, 50*time.Millisecond, time.Second, rand.Float64()))
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+			return record, false, nil
+		case <-timer.C:
+		}
+	}
+}
+
+func idempotencyError(c echo.Context, code int, text string) error {
+	return c.JSON(code, &base.ErrorResponse{
+		Status:  code,
+		Title:   http.StatusText(code),
+		Details: []*base.ErrorDetail{{Field: HeaderIdempotencyKey, Text: text}},
+	})
+}
+
+// requestFingerprint hashes method, path and body, and restores the body for the handler.
+func requestFingerprint(c echo.Context) (string, error) {
+	req := c.Request()
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	h := sha256.New()
+	h.Write([]byte(req.Method + "\n" + req.URL.Path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// contentHeader returns the replayedHeaders of header
+func contentHeader(header http.Header) http.Header {
+	content := http.Header{}
+	for _, k := range replayedHeaders {
+		if v := header.Values(k); len(v) > 0 {
+			content[k] = v
+		}
+	}
+
+	return content
+}
+
+func replay(c echo.Context, record *idempotency.Record) error {
+	header := c.Response().Header()
+	// responses stored with all their headers are filtered too
+	for k, v := range contentHeader(record.Header) {
+		header[k] = v
+	}
+	header.Set(HeaderIdempotentReplayed, "true")
+
+	c.Response().WriteHeader(record.StatusCode)
+	_, err := c.Response().Write(record.Body)
+	return err
+}
+
+// responseRecorder copies the response body while it is written to the client.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
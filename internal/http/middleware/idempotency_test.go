@@ -0,0 +1,287 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/http/middleware"
+	"github.com/SoraDaibu/go-clean-starter/internal/idempotency"
+)
+
+// memoryStore claims keys like the Postgres store, with a clock tests can move forward
+type memoryStore struct {
+	mu      sync.Mutex
+	now     time.Time
+	records map[string]*storedRecord
+}
+
+type storedRecord struct {
+	record      idempotency.Record
+	lockedUntil time.Time
+	expiresAt   time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{now: time.Now(), records: map[string]*storedRecord{}}
+}
+
+func (s *memoryStore) advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+func (s *memoryStore) Claim(_ context.Context, key, fingerprint string, lockedUntil, expiresAt time.Time) (*idempotency.Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[key]
+	if ok && !r.expiresAt.Before(s.now) &&
+		(r.record.Completed() || !r.lockedUntil.Before(s.now) || r.record.Fingerprint != fingerprint) {
+		record := r.record
+		return &record, false, nil
+	}
+
+	s.records[key] = &storedRecord{
+		record:      idempotency.Record{Key: key, Fingerprint: fingerprint},
+		lockedUntil: lockedUntil,
+		expiresAt:   expiresAt,
+	}
+	return &idempotency.Record{Key: key, Fingerprint: fingerprint}, true, nil
+}
+
+func (s *memoryStore) Save(_ context.Context, key string, statusCode int, header http.Header, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[key]; ok && !r.record.Completed() {
+		r.record.StatusCode, r.record.Header, r.record.Body = statusCode, header, body
+	}
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[key]; ok && !r.record.Completed() {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteExpired(context.Context, time.Time) (int64, error) { return 0, nil }
+
+// idempotentServer counts the requests its handler runs; the handler waits for release when it is not nil.
+// The handler returns err after writing its response, like base.HandleError, or abort without writing one.
+type idempotentServer struct {
+	*echo.Echo
+	store   *memoryStore
+	calls   atomic.Int32
+	status  int
+	err     error
+	abort   error
+	release chan struct{}
+}
+
+func newIdempotentServer() *idempotentServer {
+	s := &idempotentServer{Echo: echo.New(), store: newMemoryStore(), status: http.StatusCreated}
+	s.POST("/users", func(c echo.Context) error {
+		n := s.calls.Add(1)
+		if s.release != nil {
+			<-s.release
+		}
+		if s.abort != nil {
+			return s.abort
+		}
+
+		c.Response().Header().Set(echo.HeaderXRequestID, "request-"+strconv.Itoa(int(n)))
+		c.Response().Header().Set("RateLimit-Remaining", "4")
+		c.Response().Header().Set(echo.HeaderLocation, "/users/1")
+		if err := c.JSON(s.status, map[string]int32{"call": n}); err != nil {
+			return err
+		}
+		return s.err
+	}, middleware.Idempotency(s.store, time.Hour, time.Minute))
+
+	return s
+}
+
+func (s *idempotentServer) post(key, body string) *httptest.ResponseRecorder {
+	return s.postWithin(context.Background(), key, body)
+}
+
+// postWithin posts with ctx, whose deadline bounds how long the request waits for a duplicate in progress
+func (s *idempotentServer) postWithin(ctx context.Context, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(middleware.HeaderIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Run("runs the first request", func(t *testing.T) {
+		s := newIdempotentServer()
+
+		rec := s.post("key-1", `{"name":"alice"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"call":1}`, rec.Body.String())
+		assert.Empty(t, rec.Header().Get(middleware.HeaderIdempotentReplayed))
+		assert.Equal(t, int32(1), s.calls.Load())
+	})
+
+	t.Run("replays the response with its content headers only", func(t *testing.T) {
+		s := newIdempotentServer()
+		first := s.post("key-1", `{"name":"alice"}`)
+
+		rec := s.post("key-1", `{"name":"alice"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, first.Body.String(), rec.Body.String())
+		assert.Equal(t, "true", rec.Header().Get(middleware.HeaderIdempotentReplayed))
+		assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "/users/1", rec.Header().Get(echo.HeaderLocation))
+		assert.Empty(t, rec.Header().Get(echo.HeaderXRequestID))
+		assert.Empty(t, rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, int32(1), s.calls.Load())
+
+		// keys are independent
+		assert.JSONEq(t, `{"call":2}`, s.post("key-2", `{"name":"alice"}`).Body.String())
+		// requests without a key always run
+		assert.JSONEq(t, `{"call":3}`, s.post("", `{"name":"alice"}`).Body.String())
+	})
+
+	t.Run("waits for the response of the first request while it runs", func(t *testing.T) {
+		s := newIdempotentServer()
+		s.release = make(chan struct{})
+
+		first := make(chan *httptest.ResponseRecorder)
+		go func() { first <- s.post("key-1", `{"name":"alice"}`) }()
+		require.Eventually(t, func() bool { return s.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+		duplicate := make(chan *httptest.ResponseRecorder)
+		go func() { duplicate <- s.post("key-1", `{"name":"alice"}`) }()
+		time.Sleep(100 * time.Millisecond)
+		close(s.release)
+
+		assert.Equal(t, http.StatusCreated, (<-first).Code)
+		rec := <-duplicate
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"call":1}`, rec.Body.String())
+		assert.Equal(t, "true", rec.Header().Get(middleware.HeaderIdempotentReplayed))
+		assert.Equal(t, int32(1), s.calls.Load())
+	})
+
+	t.Run("rejects a duplicate that gave up waiting", func(t *testing.T) {
+		s := newIdempotentServer()
+		s.release = make(chan struct{})
+		defer close(s.release)
+
+		go s.post("key-1", `{"name":"alice"}`)
+		require.Eventually(t, func() bool { return s.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		rec := s.postWithin(ctx, "key-1", `{"name":"alice"}`)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get(echo.HeaderRetryAfter))
+		assert.Equal(t, int32(1), s.calls.Load())
+	})
+
+	t.Run("rejects a key reused for a different request", func(t *testing.T) {
+		s := newIdempotentServer()
+		s.post("key-1", `{"name":"alice"}`)
+
+		rec := s.post("key-1", `{"name":"bob"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), "key was already used for a different request")
+		assert.Equal(t, int32(1), s.calls.Load())
+	})
+
+	t.Run("runs the request again once the key expired", func(t *testing.T) {
+		s := newIdempotentServer()
+		s.post("key-1", `{"name":"alice"}`)
+
+		s.store.advance(time.Hour + time.Second)
+		rec := s.post("key-1", `{"name":"bob"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"call":2}`, rec.Body.String())
+	})
+
+	t.Run("takes over the key of a request that stopped without a response", func(t *testing.T) {
+		s := newIdempotentServer()
+		_, claimed, err := s.store.Claim(context.Background(), "key-1", "stale", time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.True(t, claimed)
+		s.store.records["key-1"].record.Fingerprint = fingerprintOf(t, `{"name":"alice"}`)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, http.StatusConflict, s.postWithin(ctx, "key-1", `{"name":"alice"}`).Code)
+
+		s.store.advance(time.Minute + time.Second)
+		assert.Equal(t, http.StatusCreated, s.post("key-1", `{"name":"alice"}`).Code)
+	})
+
+	t.Run("does not store server errors", func(t *testing.T) {
+		s := newIdempotentServer()
+		s.status = http.StatusServiceUnavailable
+		assert.Equal(t, http.StatusServiceUnavailable, s.post("key-1", `{"name":"alice"}`).Code)
+
+		s.status = http.StatusCreated
+		rec := s.post("key-1", `{"name":"alice"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(middleware.HeaderIdempotentReplayed))
+		assert.Equal(t, int32(2), s.calls.Load())
+	})
+
+	t.Run("stores client errors written by the handler", func(t *testing.T) {
+		s := newIdempotentServer()
+		s.status, s.err = http.StatusConflict, errors.New("email already exists")
+		assert.Equal(t, http.StatusConflict, s.post("key-1", `{"name":"alice"}`).Code)
+
+		s.status, s.err = http.StatusCreated, nil
+		rec := s.post("key-1", `{"name":"alice"}`)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.JSONEq(t, `{"call":1}`, rec.Body.String())
+		assert.Equal(t, "true", rec.Header().Get(middleware.HeaderIdempotentReplayed))
+		assert.Equal(t, int32(1), s.calls.Load())
+	})
+
+	t.Run("does not store errors returned before anything was written", func(t *testing.T) {
+		s := newIdempotentServer()
+		s.abort = echo.NewHTTPError(http.StatusBadRequest)
+		assert.Equal(t, http.StatusBadRequest, s.post("key-1", `{"name":"alice"}`).Code)
+
+		s.abort = nil
+		rec := s.post("key-1", `{"name":"alice"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(middleware.HeaderIdempotentReplayed))
+		assert.Equal(t, int32(2), s.calls.Load())
+	})
+}
+
+// fingerprintOf returns the fingerprint the middleware stores for a POST /users with body
+func fingerprintOf(t *testing.T, body string) string {
+	t.Helper()
+
+	other := newIdempotentServer()
+	other.post("probe", body)
+	return other.store.records["probe"].record.Fingerprint
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IPExtractor returns how c.RealIP() finds the client IP, which keys rate limits and read-your-writes.
+// Without trusted proxies it is the address of the connection, since clients can send any
+// X-Forwarded-For or X-Real-IP. Otherwise it is the last address of X-Forwarded-For that is
+// not one of the trusted proxies, which must be CIDRs.
+func IPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// only trust the configured ranges, not the private and loopback ones echo trusts by default
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+	"github.com/SoraDaibu/go-clean-starter/internal/logging"
+	"github.com/SoraDaibu/go-clean-starter/internal/redact"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Logger attaches a request-scoped logger carrying the request ID, route and method to the request context,
+// and writes one access log line per request through it.
+// Code below the HTTP layer logs with zerolog.Ctx(ctx) to have these fields on every line.
+// Query parameters are redacted with r. It must run after middleware.RequestID() and Tracing().
+func Logger(r *redact.Redactor) echo.MiddlewareFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+
+			// Ctx lets hooks such as tracing.LogHook and logging.Levels read the request span and route from every event
+			l := log.Logger.With().
+				Ctx(logging.WithRoute(req.Context(), c.Path())).
+				Str("request_id", c.Response().Header().Get(echo.HeaderXRequestID)).
+				Str("method", req.Method).
+				Str("route", c.Path()).
+				Logger()
+			c.SetRequest(req.WithContext(l.WithContext(req.Context())))
+
+			err := h(c)
+
+			status := responseStatus(c, err)
+			ctx := c.Request().Context()
+			// re-read from the context to pick up fields added by AddUserID
+			rl := zerolog.Ctx(ctx)
+
+			var event *zerolog.Event
+			switch {
+			case status >= http.StatusInternalServerError:
+				event = rl.Error()
+			case status >= http.StatusBadRequest:
+				event = rl.Warn()
+			default:
+				event = rl.Info()
+			}
+
+			event.
+				Str("uri", r.URI(req.RequestURI)).
+				Int("status", status).
+				Dur("latency", time.Since(start)).
+				Str("remote_ip", c.RealIP()).
+				Str("user_agent", req.UserAgent()).
+				Int64("bytes_in", req.ContentLength).
+				Int64("bytes_out", c.Response().Size).
+				Err(err).
+				Msg("access")
+
+			return err
+		}
+	}
+}
+
+// ContextKeyUserID is the echo.Context key of the authenticated user's ID.
+const ContextKeyUserID = "user_id"
+
+// AddUserID records the authenticated user's ID on the echo.Context, the request-scoped logger
+// and as the actor of audit events. Authentication middleware calls it once the user is known.
+func AddUserID(c echo.Context, userID string) {
+	c.Set(ContextKeyUserID, userID)
+	c.SetRequest(c.Request().WithContext(audit.WithActor(c.Request().Context(), userID)))
+
+	l := zerolog.Ctx(c.Request().Context())
+	if l == zerolog.DefaultContextLogger || l.GetLevel() == zerolog.Disabled {
+		// no request-scoped logger; never mutate the global one
+		return
+	}
+
+	l.UpdateContext(func(zc zerolog.Context) zerolog.Context {
+		return zc.Str("user_id", userID)
+	})
+}
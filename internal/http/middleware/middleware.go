@@ -1,16 +1,28 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+	"github.com/SoraDaibu/go-clean-starter/internal/redact"
+	"github.com/SoraDaibu/go-clean-starter/internal/tracing"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func Recover() echo.MiddlewareFunc {
@@ -45,7 +57,7 @@ func Recover() echo.MiddlewareFunc {
 				errs = append(errs, errors.New(strings.Join(msgs, "\n")))
 
 				for _, err := range errs {
-					log.Error().Stack().Err(err).Msg("")
+					zerolog.Ctx(c.Request().Context()).Error().Stack().Err(err).Msg("")
 				}
 
 				const code = http.StatusInternalServerError
@@ -55,7 +67,7 @@ func Recover() echo.MiddlewareFunc {
 					Title:  http.StatusText(code),
 				})
 				if err != nil {
-					log.Error().Err(err).Msg("")
+					zerolog.Ctx(c.Request().Context()).Error().Err(err).Msg("")
 				}
 			}()
 
@@ -64,19 +76,27 @@ func Recover() echo.MiddlewareFunc {
 	}
 }
 
-func BodyDump(env string) echo.MiddlewareFunc {
-	return middleware.BodyDump(func(c echo.Context, reqBody, resBody []byte) {
-		if env == "production" {
-			return
-		}
-
-		if c.Request().Header.Get(echo.HeaderContentType) == "application/json" {
-			log.Debug().Str("request_body", string(reqBody)).Msg("Request body")
-		} else {
-			log.Debug().Msg("Request: Binary")
-		}
+// BodyDump logs redacted request and response bodies at debug level outside production.
+// Only a sample of requests is dumped, see redact.Config.BodySampleRate.
+func BodyDump(env string, r *redact.Redactor) echo.MiddlewareFunc {
+	return middleware.BodyDumpWithConfig(middleware.BodyDumpConfig{
+		Skipper: func(c echo.Context) bool {
+			return env == "production" || !r.SampleBody()
+		},
+		Handler: func(c echo.Context, reqBody, resBody []byte) {
+			l := zerolog.Ctx(c.Request().Context())
+
+			if c.Request().Header.Get(echo.HeaderContentType) == "application/json" {
+				l.Debug().
+					Str("request_body", r.Body(reqBody)).
+					Interface("request_headers", r.Headers(c.Request().Header)).
+					Msg("Request body")
+			} else {
+				l.Debug().Msg("Request: Binary")
+			}
 
-		log.Debug().Str("response_body", string(resBody)).Msg("Response body")
+			l.Debug().Str("response_body", r.Body(resBody)).Msg("Response body")
+		},
 	})
 }
 
@@ -91,3 +111,115 @@ func DefaultContentType() echo.MiddlewareFunc {
 		}
 	}
 }
+
+// Metrics records request counts and latency per route template.
+// The route template (e.g. /users/:id) is used instead of the raw path to keep label cardinality bounded.
+func Metrics() echo.MiddlewareFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := h(c)
+
+			status := responseStatus(c, err)
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			method := c.Request().Method
+
+			metrics.HTTPRequestsTotal.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
+
+// Tracing starts a server span per request, continuing the trace from an incoming W3C traceparent header.
+// The span is stored in the request context so use cases, repositories and outgoing calls become its children.
+func Tracing() echo.MiddlewareFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := c.Path()
+			ctx, span := tracing.Tracer().Start(ctx, req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(req.URL.Path),
+					semconv.ClientAddress(c.RealIP()),
+					semconv.UserAgentOriginal(req.UserAgent()),
+				),
+			)
+			defer span.End()
+
+			c.SetRequest(req.WithContext(ctx))
+
+			err := h(c)
+
+			status := responseStatus(c, err)
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			if err != nil {
+				span.RecordError(err)
+			}
+
+			return err
+		}
+	}
+}
+
+// responseStatus returns the status code the client receives.
+// Errors not yet written are rendered later by echo's HTTPErrorHandler, so their code is derived from err.
+func responseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+
+	return http.StatusInternalServerError
+}
+
+// RequestTimeout cancels the context of requests running longer than the timeout in effect, which may change
+// when the configuration is reloaded. A zero timeout disables the limit.
+func RequestTimeout(timeout func() time.Duration) echo.MiddlewareFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			d := timeout()
+			if d <= 0 {
+				return h(c)
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), d)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return h(c)
+		}
+	}
+}
+
+// CORS allows browsers on the origins in effect to call the API; they may change when the configuration is reloaded.
+// "*" allows every origin. No origin is allowed while the list is empty.
+func CORS(origins func() []string) echo.MiddlewareFunc {
+	return middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOriginFunc: func(origin string) (bool, error) {
+			allowed := origins()
+			return slices.Contains(allowed, "*") || slices.Contains(allowed, origin), nil
+		},
+		AllowHeaders:  []string{echo.HeaderContentType, echo.HeaderAuthorization, base.HeaderIfMatch, HeaderIdempotencyKey},
+		ExposeHeaders: []string{base.HeaderETag, echo.HeaderXRequestID, HeaderRateLimitLimit, HeaderRateLimitRemaining, HeaderRateLimitReset},
+	})
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/http/base"
+	"github.com/SoraDaibu/go-clean-starter/internal/ratelimit"
+)
+
+// Rate limit response headers, see https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// RateLimitKeyFunc returns the bucket key of a request. An empty key skips rate limiting.
+type RateLimitKeyFunc func(c echo.Context) string
+
+// RateLimitByIP keys buckets by client IP.
+func RateLimitByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// RateLimitByUser keys buckets by authenticated user and skips anonymous requests.
+func RateLimitByUser(c echo.Context) string {
+	if userID, ok := c.Get(ContextKeyUserID).(string); ok && userID != "" {
+		return "user:" + userID
+	}
+
+	return ""
+}
+
+// RateLimitByClient keys buckets by authenticated user, falling back to client IP.
+func RateLimitByClient(c echo.Context) string {
+	if key := RateLimitByUser(c); key != "" {
+		return key
+	}
+
+	return RateLimitByIP(c)
+}
+
+// RateLimitFunc returns the limit in effect, which may change when the configuration is reloaded
+type RateLimitFunc func() ratelimit.Limit
+
+// RateLimit responds 429 Too Many Requests once the bucket of the request key is empty.
+// scope namespaces the buckets, so the same client has independent buckets per limiter (e.g. per route group).
+// Requests pass while the limit is disabled.
+// Store errors are logged and the request is let through: an unavailable store must not take the API down.
+func RateLimit(store ratelimit.Store, limitFunc RateLimitFunc, scope string, key RateLimitKeyFunc) echo.MiddlewareFunc {
+	return func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			limit := limitFunc()
+			if !limit.Enabled() {
+				return h(c)
+			}
+
+			k := key(c)
+			if k == "" {
+				return h(c)
+			}
+
+			ctx := c.Request().Context()
+			res, err := store.Take(ctx, fmt.Sprintf("%s:%s", scope, k), limit)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Str("scope", scope).Msg("rate limit store failed, allowing request")
+				return h(c)
+			}
+
+			setRateLimitHeaders(c, res)
+
+			if !res.Allowed {
+				zerolog.Ctx(ctx).Warn().Str("scope", scope).Str("key", k).Msg("rate limit exceeded")
+
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(res.RetryAfter.Seconds())))
+
+				const code = http.StatusTooManyRequests
+				return c.JSON(code, &base.ErrorResponse{
+					Status: code,
+					Title:  http.StatusText(code),
+				})
+			}
+
+			return h(c)
+		}
+	}
+}
+
+// setRateLimitHeaders writes the RateLimit-* headers, keeping those of the most restrictive limiter
+// when several limiters apply to the same request.
+func setRateLimitHeaders(c echo.Context, res ratelimit.Result) {
+	header := c.Response().Header()
+
+	if v := header.Get(HeaderRateLimitRemaining); v != "" {
+		if remaining, err := strconv.Atoi(v); err == nil && remaining <= res.Remaining && res.Allowed {
+			return
+		}
+	}
+
+	header.Set(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+	header.Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+	header.Set(HeaderRateLimitReset, strconv.Itoa(int(res.Reset.Seconds())))
+}
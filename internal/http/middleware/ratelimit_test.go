@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/http/middleware"
+	"github.com/SoraDaibu/go-clean-starter/internal/ratelimit"
+)
+
+// newRateLimited returns a server allowing one request per client IP, found with trustedProxies
+func newRateLimited(t *testing.T, trustedProxies []string) *echo.Echo {
+	t.Helper()
+
+	e := echo.New()
+	extractor, err := middleware.IPExtractor(trustedProxies)
+	require.NoError(t, err)
+	e.IPExtractor = extractor
+
+	limit := func() ratelimit.Limit { return ratelimit.PerMinute(1, 1) }
+	e.Use(middleware.RateLimit(ratelimit.NewMemoryStore(), limit, "ip", middleware.RateLimitByIP))
+	e.GET("/", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	return e
+}
+
+func get(e *echo.Echo, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	t.Run("ignores forwarded headers without trusted proxies", func(t *testing.T) {
+		e := newRateLimited(t, nil)
+
+		assert.Equal(t, http.StatusOK, get(e, "203.0.113.1:1234", map[string]string{echo.HeaderXForwardedFor: "198.51.100.1"}).Code)
+		// a client rotating spoofed headers still has a single bucket
+		assert.Equal(t, http.StatusTooManyRequests, get(e, "203.0.113.1:1234", map[string]string{echo.HeaderXForwardedFor: "198.51.100.2"}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, get(e, "203.0.113.1:1234", map[string]string{echo.HeaderXRealIP: "198.51.100.3"}).Code)
+		assert.Equal(t, http.StatusOK, get(e, "203.0.113.2:1234", nil).Code)
+	})
+
+	t.Run("keys by the client behind trusted proxies", func(t *testing.T) {
+		e := newRateLimited(t, []string{"10.0.0.0/8"})
+
+		assert.Equal(t, http.StatusOK, get(e, "10.0.0.1:1234", map[string]string{echo.HeaderXForwardedFor: "198.51.100.1"}).Code)
+		assert.Equal(t, http.StatusOK, get(e, "10.0.0.1:1234", map[string]string{echo.HeaderXForwardedFor: "198.51.100.2"}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, get(e, "10.0.0.2:1234", map[string]string{echo.HeaderXForwardedFor: "198.51.100.1"}).Code)
+
+		// addresses a client prepends are skipped: the proxy appends the one it saw
+		assert.Equal(t, http.StatusTooManyRequests, get(e, "10.0.0.1:1234", map[string]string{echo.HeaderXForwardedFor: "192.0.2.9, 198.51.100.2"}).Code)
+
+		// untrusted peers can't claim to forward for someone else
+		assert.Equal(t, http.StatusOK, get(e, "203.0.113.1:1234", map[string]string{echo.HeaderXForwardedFor: "198.51.100.3"}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, get(e, "203.0.113.1:1234", map[string]string{echo.HeaderXForwardedFor: "198.51.100.4"}).Code)
+	})
+
+	t.Run("rejects invalid trusted proxies", func(t *testing.T) {
+		_, err := middleware.IPExtractor([]string{"10.0.0.1"})
+		assert.Error(t, err)
+	})
+}
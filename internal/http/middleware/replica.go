@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+)
+
+// ReadYourWrites identifies the client of a request to the repositories, so that a client
+// reads from the primary rather than a lagging replica for a while after it wrote.
+// Clients are keyed like rate limits, by authenticated user or client IP; register it after
+// authentication middleware to key by user.
+func ReadYourWrites() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(repository.WithClient(req.Context(), RateLimitByClient(c))))
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/http/middleware"
+)
+
+// newSpanRecorder installs a global TracerProvider recording ended spans, and the W3C propagator, for the test
+func newSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return recorder
+}
+
+func TestTracing(t *testing.T) {
+	recorder := newSpanRecorder(t)
+
+	var handlerSpan trace.SpanContext
+	e := echo.New()
+	e.Use(middleware.Tracing())
+	e.GET("/users/:id", func(c echo.Context) error {
+		handlerSpan = trace.SpanContextFromContext(c.Request().Context())
+		if c.Param("id") == "broken" {
+			return errors.New("database is down")
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	const traceID, parentID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+
+	t.Run("continues the trace of the caller", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+		req.Header.Set("User-Agent", "test")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		span := spans[0]
+		assert.Equal(t, "GET /users/:id", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+		assert.Equal(t, parentID, span.Parent().SpanID().String())
+		assert.True(t, span.Parent().IsRemote())
+		// the handler runs in the span, so its queries and calls become children
+		assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID())
+
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, kv := range span.Attributes() {
+			attrs[kv.Key] = kv.Value
+		}
+		assert.Equal(t, "GET", attrs["http.request.method"].AsString())
+		assert.Equal(t, "/users/:id", attrs["http.route"].AsString())
+		assert.Equal(t, "/users/42", attrs["url.path"].AsString())
+		assert.Equal(t, "test", attrs["user_agent.original"].AsString())
+		assert.Equal(t, int64(http.StatusNoContent), attrs["http.response.status_code"].AsInt64())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	})
+
+	t.Run("starts a trace without a caller", func(t *testing.T) {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+		span := recorder.Ended()[1]
+		assert.False(t, span.Parent().IsValid())
+		assert.NotEqual(t, traceID, span.SpanContext().TraceID().String())
+	})
+
+	t.Run("records errors", func(t *testing.T) {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/broken", nil))
+
+		span := recorder.Ended()[2]
+		assert.Equal(t, codes.Error, span.Status().Code)
+		require.Len(t, span.Events(), 1)
+		assert.Equal(t, "exception", span.Events()[0].Name)
+	})
+}
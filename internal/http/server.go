@@ -1,10 +1,15 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -12,59 +17,137 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/SoraDaibu/go-clean-starter/builder"
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/http/handler"
 	imiddleware "github.com/SoraDaibu/go-clean-starter/internal/http/middleware"
+	"github.com/SoraDaibu/go-clean-starter/internal/logging"
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+	"github.com/SoraDaibu/go-clean-starter/internal/ratelimit"
+	"github.com/SoraDaibu/go-clean-starter/internal/redact"
+	"github.com/SoraDaibu/go-clean-starter/internal/tracing"
 )
 
 type Server struct {
-	closer func() error
-	echo   *echo.Echo
-	port   uint16
+	closer   func() error
+	echo     *echo.Echo
+	admin    *adminServer
+	port     uint16
+	reloader *config.Reloader
+	hangup   chan os.Signal
+	// shutdownTimeout bounds how long Run waits for in-flight requests once stopped
+	shutdownTimeout time.Duration
 }
 
 func NewServer(d *builder.Dependency) *Server {
-	s := &Server{port: d.Config.App.ListenPort}
+	s := &Server{
+		port:            d.Config.App.ListenPort,
+		reloader:        config.NewReloader(d.Config),
+		hangup:          make(chan os.Signal, 1),
+		shutdownTimeout: time.Duration(d.Config.App.ShutdownTimeoutSeconds) * time.Second,
+	}
+
+	redactor := builder.InitializeRedactor(d)
+	levels := setupLogger(d, redactor)
+	s.reloader.OnReload(func(prev, next *config.Config) {
+		if next.App.LogLevel == prev.App.LogLevel {
+			return
+		}
+		// validated by config.Load
+		if level, err := logging.ParseLevel(next.App.LogLevel); err == nil {
+			levels.SetDefault(level)
+		}
+	})
 
 	s.closer = func() error {
-		d.DB.Close()
-		return nil
+		signal.Stop(s.hangup)
+		close(s.hangup)
+		if s.admin != nil {
+			if err := s.admin.close(); err != nil {
+				log.Error().Err(err).Msg("failed to close admin server")
+			}
+		}
+		return d.Close()
 	}
 
-	s.echo = setup(d)
+	s.echo = setup(d, s.reloader, redactor)
+
+	controls := newAdminControls(d, s.reloader, levels)
+	if d.Config.Admin.ListenPort != 0 {
+		s.admin = newAdminServer(d, controls, newDebugHandler(d, s.echo, s.reloader))
+	}
+
+	if s.admin == nil {
+		s.echo.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+		s.echo.Any("/admin/*", echo.WrapHandler(controls))
+	}
 
 	return s
 }
 
+// Close stops the admin server and the dependencies, in reverse order of their start. Call it once Run returned.
 func (s *Server) Close() error {
 	return s.closer()
 }
 
-func (s *Server) Run() {
+// Run serves until ctx is done or the process receives SIGINT or SIGTERM, then stops accepting connections
+// and waits up to app.shutdown_timeout_seconds for in-flight requests. It returns nil on such a stop.
+func (s *Server) Run(ctx context.Context) error {
 	if zerolog.GlobalLevel() == zerolog.DebugLevel {
 		//nolint:errchkjson
 		data, _ := json.MarshalIndent(s.echo.Routes(), "", "  ")
 		fmt.Println(string(data))
 	}
 
-	s.echo.Logger.Fatal(s.echo.Start(fmt.Sprintf(":%d", s.port)))
+	if s.admin != nil {
+		go s.admin.run()
+	}
+
+	// reload the configuration on SIGHUP, e.g. `kill -HUP <pid>` after editing the config file
+	signal.Notify(s.hangup, syscall.SIGHUP)
+	go s.reloadOnHangup()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.echo.Start(fmt.Sprintf(":%d", s.port)) }()
+
+	select {
+	case err := <-stopped:
+		// failed to listen
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info().Dur("timeout", s.shutdownTimeout).Msg("shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := s.echo.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	if err := <-stopped; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
 }
 
-func setup(d *builder.Dependency) *echo.Echo {
-	e := echo.New()
+// reloadOnHangup reloads the configuration on every SIGHUP until Close
+func (s *Server) reloadOnHangup() {
+	for range s.hangup {
+		if _, err := s.reloader.Reload("SIGHUP"); err != nil {
+			log.Error().Err(err).Msg("failed to reload config, keeping the current one")
+		}
+	}
+}
 
-	var level zerolog.Level
-	switch d.Config.App.LogLevel {
-	case "debug":
-		level = zerolog.DebugLevel
-	case "info":
-		level = zerolog.InfoLevel
-	case "warning", "warn":
-		level = zerolog.WarnLevel
-	case "error":
-		level = zerolog.ErrorLevel
-	default:
+// setupLogger configures the global logger and returns the log levels, which can change at runtime
+func setupLogger(d *builder.Dependency, redactor *redact.Redactor) *logging.Levels {
+	level, err := logging.ParseLevel(d.Config.App.LogLevel)
+	if err != nil {
 		level = zerolog.InfoLevel
 	}
-	zerolog.SetGlobalLevel(level)
+	levels := logging.NewLevels(level)
 
 	// To show file:line where log was called
 	zerolog.CallerSkipFrameCount = 2
@@ -77,38 +160,166 @@ func setup(d *builder.Dependency) *echo.Echo {
 		With().
 		Timestamp(). // Add ISO timestamp
 		Caller().    // Show file:line where log was called
-		Logger()
+		Logger().
+		Hook(tracing.LogHook{}). // Add trace_id/span_id to lines logged with a request context
+		Hook(levels)             // Apply the levels of packages and routes
+
+	// zerolog.Ctx(ctx) falls back to the global logger outside of a request
+	zerolog.DefaultContextLogger = &log.Logger
+
+	// Mask PII such as e-mail addresses in every logged error, e.g. duplicate key details from Postgres
+	zerolog.ErrorMarshalFunc = func(err error) interface{} {
+		if err == nil {
+			return nil
+		}
+		return redactor.String(err.Error())
+	}
 
 	log.Info().Str("level", level.String()).Msg("Zerolog configured")
 
+	return levels
+}
+
+func setup(d *builder.Dependency, reloader *config.Reloader, redactor *redact.Redactor) *echo.Echo {
+	e := echo.New()
+
+	// trusted proxies are validated by config.Load; ignoring headers is the safe fallback anyway
+	ipExtractor, err := imiddleware.IPExtractor(d.Config.HTTP.TrustedProxies)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid trusted proxies, using the address of the connection as client IP")
+		ipExtractor = echo.ExtractIPDirect()
+	}
+	e.IPExtractor = ipExtractor
+
 	e.Pre(middleware.RemoveTrailingSlash())
 
 	e.Use(
 		imiddleware.Recover(),
-		middleware.Logger(),
 		middleware.RequestID(),
+		imiddleware.Tracing(),
+		imiddleware.Logger(redactor),
+		imiddleware.Metrics(),
+		imiddleware.AuditActor(),
+		imiddleware.ReadYourWrites(),
 		middleware.Secure(),
 		imiddleware.DefaultContentType(),
-		imiddleware.BodyDump(d.Config.App.Env),
+		imiddleware.BodyDump(d.Config.App.Env, redactor),
+		imiddleware.CORS(func() []string { return reloader.Current().HTTP.CORSOrigins }),
+		imiddleware.RequestTimeout(func() time.Duration {
+			return time.Duration(reloader.Current().HTTP.RequestTimeoutSeconds) * time.Second
+		}),
 	)
 
-	registerRoutes(d, e)
+	var rateLimitStore ratelimit.Store
+	if d.Config.RateLimit.Enabled {
+		rateLimitStore = builder.InitializeRateLimitStore(d)
+
+		// per-user limits need authentication middleware registered before them to know the user
+		e.Use(
+			imiddleware.RateLimit(rateLimitStore, rateLimit(reloader, func(c *config.Config) config.RateLimitRule { return c.RateLimit.IP }), "ip", imiddleware.RateLimitByIP),
+			imiddleware.RateLimit(rateLimitStore, rateLimit(reloader, func(c *config.Config) config.RateLimitRule { return c.RateLimit.User }), "user", imiddleware.RateLimitByUser),
+		)
+	}
+
+	registerRoutes(d, e, reloader, rateLimitStore)
 
 	return e
 }
 
-func registerRoutes(d *builder.Dependency, e *echo.Echo) {
-	// health check
+// rateLimit returns the limit of the rule in effect, which changes when the configuration is reloaded
+func rateLimit(reloader *config.Reloader, rule func(c *config.Config) config.RateLimitRule) imiddleware.RateLimitFunc {
+	return func() ratelimit.Limit {
+		r := rule(reloader.Current())
+		return ratelimit.PerMinute(r.PerMinute, r.Burst)
+	}
+}
+
+// groupRateLimit returns the rate limit middleware of a route group when rate limiting is enabled.
+// The group is not limited while no rule is configured for it.
+func groupRateLimit(reloader *config.Reloader, store ratelimit.Store, group string) []echo.MiddlewareFunc {
+	if store == nil {
+		return nil
+	}
+
+	limit := rateLimit(reloader, func(c *config.Config) config.RateLimitRule { return c.RateLimit.Groups[group] })
+	return []echo.MiddlewareFunc{
+		imiddleware.RateLimit(store, limit, "group:"+group, imiddleware.RateLimitByClient),
+	}
+}
+
+// adminOnly restricts API routes to operators with the bearer token admin.token, see requireAdminToken
+func adminOnly(c *config.Config) echo.MiddlewareFunc {
+	return echo.WrapMiddleware(func(h http.Handler) http.Handler {
+		return requireAdminToken(c, h)
+	})
+}
+
+func registerRoutes(d *builder.Dependency, e *echo.Echo, reloader *config.Reloader, rateLimitStore ratelimit.Store) {
+	// health check, failing while a dependency such as the database is unhealthy.
+	// Errors are only served on the admin port, see /debug/health, as they may reveal hosts and credentials.
 	e.GET("/health", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]string{"status": "OK"})
+		res := handler.HealthResponse{Status: "OK", Dependencies: []handler.DependencyHealth{}}
+		for _, dep := range d.Health(c.Request().Context()) {
+			if !dep.Healthy {
+				res.Status = "UNAVAILABLE"
+			}
+			res.Dependencies = append(res.Dependencies, handler.DependencyHealth{Name: dep.Name, Healthy: dep.Healthy})
+		}
+
+		if res.Status != "OK" {
+			return c.JSON(http.StatusServiceUnavailable, res)
+		}
+		return c.JSON(http.StatusOK, res)
 	})
 
+	// honors Idempotency-Key on POST endpoints
+	idempotent := imiddleware.Idempotency(
+		builder.InitializeIdempotencyStore(d),
+		time.Duration(d.Config.Idempotency.TTLHours)*time.Hour,
+		time.Duration(d.Config.Idempotency.LockTimeoutSeconds)*time.Second,
+	)
+
 	{
 		// users
-		user := e.Group("/users")
+		user := e.Group("/users", groupRateLimit(reloader, rateLimitStore, "users")...)
 		userHandler := builder.InitializeUserHandler(d)
 
+		user.GET("", userHandler.ListUsers)
 		user.GET("/:id", userHandler.GetUser)
-		user.POST("", userHandler.CreateUser)
+		user.POST("", userHandler.CreateUser, idempotent)
+		user.PATCH("/:id", userHandler.UpdateUser)
+		user.DELETE("/:id", userHandler.DeleteUser)
+	}
+
+	{
+		// items
+		items := e.Group("/items", groupRateLimit(reloader, rateLimitStore, "items")...)
+		itemHandler := builder.InitializeItemHandler(d)
+
+		items.GET("", itemHandler.ListItems)
+		items.GET("/search", itemHandler.SearchItems)
+	}
+
+	{
+		// audit events, read by operators only as their diffs hold personal data such as emails
+		auditEvents := e.Group("/audit-events", groupRateLimit(reloader, rateLimitStore, "audit-events")...)
+		auditEvents.Use(adminOnly(d.Config))
+		auditHandler := builder.InitializeAuditHandler(d)
+
+		auditEvents.GET("", auditHandler.ListAuditEvents)
+	}
+
+	{
+		// webhook subscriptions, managed by operators as they make the server send requests to any URL
+		webhooks := e.Group("/webhooks", groupRateLimit(reloader, rateLimitStore, "webhooks")...)
+		webhooks.Use(adminOnly(d.Config))
+		webhookHandler := builder.InitializeWebhookHandler(d)
+
+		webhooks.GET("", webhookHandler.ListSubscriptions)
+		webhooks.POST("", webhookHandler.CreateSubscription, idempotent)
+		webhooks.GET("/:id", webhookHandler.GetSubscription)
+		webhooks.PATCH("/:id", webhookHandler.UpdateSubscription)
+		webhooks.DELETE("/:id", webhookHandler.DeleteSubscription)
+		webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
 	}
 }
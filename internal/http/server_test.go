@@ -0,0 +1,234 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/builder"
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/logging"
+	"github.com/SoraDaibu/go-clean-starter/internal/redact"
+)
+
+const testAdminToken = "test-admin-token"
+
+// newTestEcho sets up the routes of the API without connecting to the database,
+// for the requests that are answered before reaching a repository
+func newTestEcho(t *testing.T, adminToken string) *echo.Echo {
+	t.Helper()
+
+	// secrets are read when the configuration is loaded
+	t.Setenv("ADMIN_TOKEN", adminToken)
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	return setup(&builder.Dependency{Config: cfg}, config.NewReloader(cfg), redact.New(redact.Config{}))
+}
+
+func serve(e http.Handler, method, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestAdminRoutesRequireAdminToken(t *testing.T) {
+	routes := []struct{ method, target string }{
+		{http.MethodGet, "/audit-events"},
+		{http.MethodGet, "/webhooks"},
+		{http.MethodPost, "/webhooks"},
+		{http.MethodGet, "/webhooks/0b7a4a4e-8a43-4d53-9a8b-3c3f0e2b9a11"},
+		{http.MethodPatch, "/webhooks/0b7a4a4e-8a43-4d53-9a8b-3c3f0e2b9a11"},
+		{http.MethodDelete, "/webhooks/0b7a4a4e-8a43-4d53-9a8b-3c3f0e2b9a11"},
+		{http.MethodGet, "/webhooks/0b7a4a4e-8a43-4d53-9a8b-3c3f0e2b9a11/deliveries"},
+	}
+
+	t.Run("not found without a configured token", func(t *testing.T) {
+		e := newTestEcho(t, "")
+		for _, r := range routes {
+			assert.Equal(t, http.StatusNotFound, serve(e, r.method, r.target, "anything").Code, "%s %s", r.method, r.target)
+		}
+	})
+
+	t.Run("unauthorized without the token", func(t *testing.T) {
+		e := newTestEcho(t, testAdminToken)
+		for _, r := range routes {
+			for _, token := range []string{"", "wrong"} {
+				rec := serve(e, r.method, r.target, token)
+				assert.Equal(t, http.StatusUnauthorized, rec.Code, "%s %s", r.method, r.target)
+				assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+			}
+		}
+	})
+}
+
+func TestMetricsAfterRequest(t *testing.T) {
+	e := newTestEcho(t, testAdminToken)
+	admin := newAdminServer(&builder.Dependency{Config: &config.Config{}}, http.NotFoundHandler(), http.NotFoundHandler())
+
+	require.Equal(t, http.StatusUnauthorized, serve(e, http.MethodGet, "/webhooks/0b7a4a4e-8a43-4d53-9a8b-3c3f0e2b9a11", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(e, http.MethodGet, "/no-such-route", "").Code)
+
+	rec := serve(admin.srv.Handler, http.MethodGet, "/metrics", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+
+	// routes are labelled by template, not by path
+	assert.Contains(t, body, `go_clean_starter_http_request_duration_seconds_count{method="GET",route="/webhooks/:id"}`)
+	assert.Contains(t, body, `go_clean_starter_http_request_duration_seconds_bucket{method="GET",route="/webhooks/:id",le="+Inf"}`)
+	assert.Contains(t, body, `go_clean_starter_http_requests_total{method="GET",route="/webhooks/:id",status="401"}`)
+	assert.Contains(t, body, `go_clean_starter_http_requests_total{method="GET",route="unmatched",status="404"}`)
+	assert.NotContains(t, body, "0b7a4a4e-8a43-4d53-9a8b-3c3f0e2b9a11")
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Setenv("RATE_LIMIT_ENABLED", "true")
+	t.Setenv("RATE_LIMIT_STORE", "memory")
+	t.Setenv("RATE_LIMIT_IP", "60:5")
+	t.Setenv("RATE_LIMIT_GROUPS", "webhooks=6:2")
+	e := newTestEcho(t, testAdminToken)
+
+	t.Run("headers of the most restrictive limit", func(t *testing.T) {
+		for remaining := 1; remaining >= 0; remaining-- {
+			rec := serve(e, http.MethodGet, "/webhooks", "")
+			require.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+			assert.Equal(t, strconv.Itoa(remaining), rec.Header().Get("RateLimit-Remaining"))
+			// 6 per minute refill a token every 10s
+			assert.Equal(t, strconv.Itoa(10*(2-remaining)), rec.Header().Get("RateLimit-Reset"))
+			assert.Empty(t, rec.Header().Get(echo.HeaderRetryAfter))
+		}
+	})
+
+	t.Run("429 once the group limit is used up", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/webhooks", "")
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "10", rec.Header().Get(echo.HeaderRetryAfter))
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+		assert.JSONEq(t, `{"status":429,"title":"Too Many Requests"}`, rec.Body.String())
+	})
+
+	t.Run("other groups only count against the IP limit", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/audit-events", "")
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "5", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+
+		serve(e, http.MethodGet, "/audit-events", "")
+		rec = serve(e, http.MethodGet, "/audit-events", "")
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		// 60 per minute refill a token every second
+		assert.Equal(t, "1", rec.Header().Get(echo.HeaderRetryAfter))
+	})
+}
+
+func TestHealth(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", testAdminToken)
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	const reason = "failed to connect to `user=app database=app`: 10.0.0.5:5432: password authentication failed"
+	lifecycle := &builder.Lifecycle{}
+	lifecycle.Append(builder.Hook{Name: "db", Health: func(context.Context) error { return errors.New(reason) }})
+	lifecycle.Append(builder.Hook{Name: "cache"})
+	require.NoError(t, lifecycle.Start(context.Background()))
+
+	d := &builder.Dependency{Config: cfg, Lifecycle: lifecycle}
+	reloader := config.NewReloader(cfg)
+	e := setup(d, reloader, redact.New(redact.Config{}))
+	admin := newAdminServer(d, newAdminControls(d, reloader, logging.NewLevels(zerolog.InfoLevel)), newDebugHandler(d, e, reloader))
+
+	t.Run("public health hides why a dependency is unhealthy", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/health", "")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.JSONEq(t, `{"status":"UNAVAILABLE","dependencies":[{"name":"db","healthy":false},{"name":"cache","healthy":true}]}`, rec.Body.String())
+	})
+
+	t.Run("admin port tells why", func(t *testing.T) {
+		rec := serve(admin.srv.Handler, http.MethodGet, "/debug/health", testAdminToken)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"data":[{"name":"db","healthy":false,"error":`+strconv.Quote(reason)+`},{"name":"cache","healthy":true}]}`, rec.Body.String())
+	})
+}
+
+func TestServerRun(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	t.Setenv("APP_LISTEN_PORT", strconv.Itoa(port))
+	t.Setenv("ADMIN_LISTEN_PORT", "0")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	// NewServer replaces the global logger
+	logger := log.Logger
+	t.Cleanup(func() { log.Logger = logger })
+
+	var stopped []string
+	lifecycle := &builder.Lifecycle{}
+	lifecycle.Append(builder.Hook{Name: "db", Stop: func(context.Context) error {
+		stopped = append(stopped, "db")
+		return nil
+	}})
+	require.NoError(t, lifecycle.Start(context.Background()))
+
+	s := NewServer(&builder.Dependency{Config: cfg, Lifecycle: lifecycle})
+	started := make(chan struct{})
+	s.echo.GET("/slow", func(c echo.Context) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return c.String(http.StatusOK, "done")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan error, 1)
+	go func() { ran <- s.Run(ctx) }()
+	require.Eventually(t, func() bool { return s.echo.ListenerAddr() != nil }, 5*time.Second, 10*time.Millisecond)
+
+	type result struct {
+		code int
+		err  error
+	}
+	requested := make(chan result, 1)
+	go func() {
+		res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/slow", port))
+		if err != nil {
+			requested <- result{err: err}
+			return
+		}
+		res.Body.Close()
+		requested <- result{code: res.StatusCode}
+	}()
+	<-started
+
+	// stopping waits for the request in flight
+	cancel()
+	res := <-requested
+	require.NoError(t, res.err)
+	assert.Equal(t, http.StatusOK, res.code)
+	require.NoError(t, <-ran)
+	assert.Empty(t, stopped, "dependencies are stopped by Close, after the requests drained")
+
+	require.NoError(t, s.Close())
+	assert.Equal(t, []string{"db"}, stopped)
+}
@@ -0,0 +1,159 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+)
+
+// ErrCircuitOpen is returned for requests to a host that failed too often recently
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+type circuitState int
+
+const (
+	// closed lets every request through
+	closed circuitState = iota
+	// halfOpen lets a single probe through after the cooldown
+	halfOpen
+	// open fails every request until the cooldown passed
+	open
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case halfOpen:
+		return "half-open"
+	case open:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+type outcome int
+
+const (
+	succeeded outcome = iota
+	failed
+	// abandoned requests were canceled by the caller and say nothing about the host
+	abandoned
+)
+
+// outcomeOf classifies a response for the circuit breaker: network errors and 5xx responses are failures
+func outcomeOf(ctx context.Context, res *http.Response, err error) outcome {
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return abandoned
+	case err != nil, res.StatusCode >= http.StatusInternalServerError:
+		return failed
+	default:
+		return succeeded
+	}
+}
+
+type circuit struct {
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+// breakers holds a circuit per host
+type breakers struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+func newBreakers(threshold int, cooldown time.Duration) *breakers {
+	return &breakers{threshold: threshold, cooldown: cooldown, now: time.Now, hosts: map[string]*circuit{}}
+}
+
+// allow returns ErrCircuitOpen when the circuit of host is open, or half-open with a probe in flight
+func (b *breakers) allow(host string) error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(host)
+	switch c.state {
+	case open:
+		if b.now().Sub(c.openedAt) < b.cooldown {
+			return fmt.Errorf("%s: %w", host, ErrCircuitOpen)
+		}
+		// this request is the probe
+		b.transition(host, c, halfOpen)
+	case halfOpen:
+		return fmt.Errorf("%s: %w", host, ErrCircuitOpen)
+	}
+
+	return nil
+}
+
+// record updates the circuit of host with the outcome of a request allowed through
+func (b *breakers) record(host string, o outcome) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(host)
+	switch {
+	case c.state == halfOpen && o == abandoned:
+		// probe again with the next request
+		c.state = open
+	case o == abandoned:
+	case o == succeeded:
+		c.failures = 0
+		if c.state != closed {
+			b.transition(host, c, closed)
+		}
+	case c.state == halfOpen:
+		c.openedAt = b.now()
+		b.transition(host, c, open)
+	default:
+		c.failures++
+		if c.failures >= b.threshold {
+			c.openedAt = b.now()
+			b.transition(host, c, open)
+		}
+	}
+}
+
+// circuit returns the circuit of host. b.mu must be held.
+func (b *breakers) circuit(host string) *circuit {
+	c, ok := b.hosts[host]
+	if !ok {
+		c = &circuit{}
+		b.hosts[host] = c
+	}
+
+	return c
+}
+
+// transition changes the state of a circuit. b.mu must be held.
+func (b *breakers) transition(host string, c *circuit, state circuitState) {
+	c.state = state
+	metrics.HTTPClientCircuitState.WithLabelValues(host).Set(float64(state))
+
+	event := log.Info()
+	if state == open {
+		event = log.Warn()
+	}
+	event.Str("host", host).Str("state", state.String()).Msg("circuit breaker state changed")
+}
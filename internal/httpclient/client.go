@@ -0,0 +1,280 @@
+// Package httpclient sends requests to other services, such as partner APIs and webhook endpoints,
+// with retries, a circuit breaker per host, logging and metrics.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/backoff"
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+	"github.com/SoraDaibu/go-clean-starter/internal/redact"
+	"github.com/SoraDaibu/go-clean-starter/internal/tracing"
+)
+
+// HeaderIdempotencyKey marks a request as safe to retry whatever its method
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// Config configures a client
+type Config struct {
+	// Timeout limits a request including its retries. 0 disables the limit.
+	Timeout time.Duration
+	// MaxAttempts is how often a request is sent at most. 1 disables retries.
+	MaxAttempts int
+	// RetryBaseDelay is the delay before the first retry, doubling with each further one up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// BreakerThreshold consecutive failures of a host open its circuit for BreakerCooldown. 0 disables circuit breaking.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost 0 is unlimited
+	MaxConnsPerHost int
+	IdleConnTimeout time.Duration
+
+	// LogBodies logs redacted request and response bodies at debug level
+	LogBodies bool
+
+	// DenyPrivateNetworks fails connections to loopback, private and link-local addresses with ErrPrivateAddress,
+	// for requests to URLs given by users such as webhook endpoints. Proxies are not used then, so that the
+	// address checked is the one of the host.
+	DenyPrivateNetworks bool
+}
+
+// New creates an http.Client sending requests through a Transport over a pooled, traced http.Transport
+func New(cfg Config, redactor *redact.Redactor) *http.Client {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.MaxIdleConns = cfg.MaxIdleConns
+	base.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	base.MaxConnsPerHost = cfg.MaxConnsPerHost
+	base.IdleConnTimeout = cfg.IdleConnTimeout
+	if cfg.DenyPrivateNetworks {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: denyPrivate}
+		base.DialContext = dialer.DialContext
+		base.Proxy = nil
+	}
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: NewTransport(cfg, redactor, tracing.NewTransport(base)),
+	}
+}
+
+// Transport is an http.RoundTripper retrying failed requests with exponential backoff and jitter,
+// and failing requests to hosts with an open circuit fast with ErrCircuitOpen.
+// Every attempt is logged, with redacted URL and headers, and recorded in metrics.
+//
+// A request is retried on network errors and on 429, 502, 503 and 504 responses when its method is idempotent
+// or it has an Idempotency-Key header, and its body can be sent again (see http.Request.GetBody).
+type Transport struct {
+	cfg      Config
+	base     http.RoundTripper
+	redactor *redact.Redactor
+	breakers *breakers
+	jitter   func() float64
+}
+
+// NewTransport creates a Transport sending requests with base
+func NewTransport(cfg Config, redactor *redact.Redactor, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		cfg:      cfg,
+		base:     base,
+		redactor: redactor,
+		breakers: newBreakers(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		jitter:   rand.Float64,
+	}
+}
+
+// CloseIdleConnections closes the idle connections of the underlying transport, see http.Client.CloseIdleConnections
+func (t *Transport) CloseIdleConnections() {
+	type closeIdler interface{ CloseIdleConnections() }
+	if ci, ok := t.base.(closeIdler); ok {
+		ci.CloseIdleConnections()
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+	retryable := t.cfg.MaxAttempts > 1 && replayable(req) &&
+		(idempotent(req.Method) || req.Header.Get(HeaderIdempotencyKey) != "")
+
+	for attempt := 1; ; attempt++ {
+		if err := t.breakers.allow(host); err != nil {
+			return nil, err
+		}
+
+		r, err := attemptRequest(req, attempt)
+		if err != nil {
+			// nothing was sent, so a probe of a half-open circuit is left to the next request
+			t.breakers.record(host, abandoned)
+			return nil, err
+		}
+
+		res, err := t.send(r, attempt)
+		t.breakers.record(host, outcomeOf(ctx, res, err))
+
+		if !retryable || attempt >= t.cfg.MaxAttempts || !shouldRetry(ctx, res, err) {
+			return res, err
+		}
+
+		delay := backoff.Delay(attempt, t.cfg.RetryBaseDelay, t.cfg.RetryMaxDelay, t.jitter())
+		if after := retryAfter(res); after > delay {
+			delay = min(after, t.cfg.RetryMaxDelay)
+		}
+		if res != nil {
+			// let the connection be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+		}
+		metrics.HTTPClientRetriesTotal.WithLabelValues(host).Inc()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attemptRequest returns the request to send as the given attempt: req itself first, then clones with a new body
+func attemptRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 {
+		return req, nil
+	}
+
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	return r, nil
+}
+
+// send sends one attempt, logging it and recording its metrics
+func (t *Transport) send(req *http.Request, attempt int) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(res.StatusCode)
+	}
+	metrics.HTTPClientRequestsTotal.WithLabelValues(req.URL.Host, req.Method, status).Inc()
+	metrics.HTTPClientRequestDuration.WithLabelValues(req.URL.Host, req.Method).Observe(latency.Seconds())
+
+	logger := zerolog.Ctx(req.Context())
+	var event *zerolog.Event
+	if err != nil || res.StatusCode >= http.StatusInternalServerError {
+		event = logger.Warn()
+	} else {
+		event = logger.Debug()
+	}
+	if event == nil {
+		return res, err
+	}
+
+	event = event.
+		Str("method", req.Method).
+		Str("url", t.redactor.URI(req.URL.Redacted())).
+		Int("attempt", attempt).
+		Dur("latency", latency).
+		Interface("request_headers", t.redactor.Headers(req.Header))
+	if t.cfg.LogBodies && req.GetBody != nil && t.redactor.SampleBody() {
+		if body, err := req.GetBody(); err == nil {
+			b, _ := io.ReadAll(body)
+			body.Close()
+			event = event.Str("request_body", t.redactor.Body(b))
+		}
+	}
+
+	if err != nil {
+		event.Err(err).Msg("outbound request failed")
+		return res, err
+	}
+
+	event = event.
+		Int("status", res.StatusCode).
+		Interface("response_headers", t.redactor.Headers(res.Header))
+	if t.cfg.LogBodies && t.redactor.SampleBody() {
+		event = event.Str("response_body", t.redactor.Body(peekBody(res)))
+	}
+	event.Msg("outbound request")
+
+	return res, nil
+}
+
+// peekBody returns the start of the body of res, leaving the body readable in full
+func peekBody(res *http.Response) []byte {
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), res.Body), res.Body}
+
+	return b
+}
+
+// idempotent reports whether requests with method can be sent twice with the effect of once, see RFC 9110
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// replayable reports whether the body of req can be sent again
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if err != nil {
+		// canceled or timed out by the caller
+		return ctx.Err() == nil
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the delay asked for by the Retry-After header of res in seconds, if any
+func retryAfter(res *http.Response) time.Duration {
+	if res == nil {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,278 @@
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/httpclient"
+	"github.com/SoraDaibu/go-clean-starter/internal/redact"
+)
+
+var testConfig = httpclient.Config{
+	Timeout:          5 * time.Second,
+	MaxAttempts:      3,
+	RetryBaseDelay:   time.Millisecond,
+	RetryMaxDelay:    5 * time.Millisecond,
+	BreakerThreshold: 0,
+	BreakerCooldown:  time.Minute,
+}
+
+// server responds with the given status codes in turn, then 200, recording the bodies it received
+func server(t *testing.T, codes ...int) (*httptest.Server, *atomic.Int32, *[]string) {
+	t.Helper()
+
+	var calls atomic.Int32
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if n <= len(codes) {
+			w.WriteHeader(codes[n-1])
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls, &bodies
+}
+
+func newClient(cfg httpclient.Config) *http.Client {
+	return httpclient.New(cfg, redact.New(redact.Config{}))
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		key       string
+		codes     []int
+		wantCode  int
+		wantCalls int32
+	}{
+		{"GET is retried on 503", http.MethodGet, "", []int{503, 502}, 200, 3},
+		{"GET gives up after MaxAttempts", http.MethodGet, "", []int{503, 503, 503, 503}, 503, 3},
+		{"GET is not retried on 500", http.MethodGet, "", []int{500}, 500, 1},
+		{"GET is not retried on 4xx", http.MethodGet, "", []int{404}, 404, 1},
+		{"GET is retried on 429", http.MethodGet, "", []int{429}, 200, 2},
+		{"POST is not retried", http.MethodPost, "", []int{503}, 503, 1},
+		{"POST with an idempotency key is retried", http.MethodPost, "key-1", []int{503}, 200, 2},
+		{"PUT is retried", http.MethodPut, "", []int{504}, 200, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls, bodies := server(t, tt.codes...)
+
+			req, err := http.NewRequest(tt.method, srv.URL, strings.NewReader(`{"name":"a"}`))
+			require.NoError(t, err)
+			if tt.key != "" {
+				req.Header.Set(httpclient.HeaderIdempotencyKey, tt.key)
+			}
+
+			res, err := newClient(testConfig).Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.wantCode, res.StatusCode)
+			assert.Equal(t, tt.wantCalls, calls.Load())
+			// the body is sent again with every attempt
+			for _, body := range *bodies {
+				assert.Equal(t, `{"name":"a"}`, body)
+			}
+		})
+	}
+}
+
+func TestClient_RetriesNetworkErrors(t *testing.T) {
+	srv, _, _ := server(t)
+	url := srv.URL
+	srv.Close()
+
+	var attempts atomic.Int32
+	transport := httpclient.NewTransport(testConfig, redact.New(redact.Config{}), roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts.Add(1)
+		return http.DefaultTransport.RoundTrip(req)
+	}))
+
+	_, err := (&http.Client{Transport: transport}).Get(url)
+	require.Error(t, err)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestClient_StopsRetryingWhenCanceled(t *testing.T) {
+	srv, calls, _ := server(t, 503, 503, 503)
+
+	cfg := testConfig
+	cfg.RetryBaseDelay, cfg.RetryMaxDelay = time.Minute, time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	_, err = newClient(cfg).Do(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := testConfig
+	cfg.BreakerThreshold = 2
+	cfg.BreakerCooldown = 50 * time.Millisecond
+	client := newClient(cfg)
+
+	for range 2 {
+		res, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		res.Body.Close()
+	}
+
+	// open: fails fast without reaching the host
+	_, err := client.Get(srv.URL)
+	require.ErrorIs(t, err, httpclient.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// half-open after the cooldown: a failed probe opens the circuit again
+	time.Sleep(cfg.BreakerCooldown)
+	res, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	_, err = client.Get(srv.URL)
+	require.ErrorIs(t, err, httpclient.ErrCircuitOpen)
+
+	// a successful probe closes it
+	failing.Store(false)
+	time.Sleep(cfg.BreakerCooldown)
+	for range 3 {
+		res, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+func TestClient_CircuitBreakerProbeNotSent(t *testing.T) {
+	srv, calls, _ := server(t, http.StatusServiceUnavailable)
+
+	cfg := testConfig
+	cfg.BreakerThreshold = 1
+	cfg.BreakerCooldown = time.Millisecond
+	cfg.RetryBaseDelay = 10 * time.Millisecond
+	cfg.RetryMaxDelay = 10 * time.Millisecond
+	client := newClient(cfg)
+
+	// the retry after the cooldown is the probe, but its body cannot be sent again
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{}`))
+	require.NoError(t, err)
+	req.Header.Set(httpclient.HeaderIdempotencyKey, "key")
+	req.GetBody = func() (io.ReadCloser, error) { return nil, errors.New("body gone") }
+	_, err = client.Do(req)
+	require.ErrorContains(t, err, "body gone")
+	assert.Equal(t, int32(1), calls.Load())
+
+	// the circuit is not stuck half-open: the next request probes
+	res, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestClient_LogsRedacted(t *testing.T) {
+	srv, _, _ := server(t)
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Level(zerolog.DebugLevel)
+	cfg := testConfig
+	cfg.LogBodies = true
+	client := httpclient.New(cfg, redact.New(redact.Config{BodySampleRate: 1}))
+
+	req, err := http.NewRequestWithContext(logger.WithContext(context.Background()), http.MethodPut,
+		srv.URL+"/users?access_token=abc&page=2", strings.NewReader(`{"password":"hunter2","name":"a"}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer abc")
+
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	// the body is still readable in full after logging it
+	assert.Equal(t, `{"ok":true}`, string(body))
+
+	line := buf.String()
+	assert.Contains(t, line, `"message":"outbound request"`)
+	assert.Contains(t, line, `"status":200`)
+	assert.Contains(t, line, `access_token=%5BREDACTED%5D`)
+	assert.Contains(t, line, `"Authorization":"[REDACTED]"`)
+	assert.Contains(t, line, `\"password\":\"[REDACTED]\"`)
+	assert.NotContains(t, line, "abc")
+	assert.NotContains(t, line, "hunter2")
+}
+
+func TestClient_DenyPrivateNetworks(t *testing.T) {
+	srv, calls, _ := server(t)
+
+	cfg := testConfig
+	cfg.DenyPrivateNetworks = true
+	_, err := newClient(cfg).Get(srv.URL)
+	require.ErrorIs(t, err, httpclient.ErrPrivateAddress)
+	assert.Equal(t, int32(0), calls.Load())
+
+	// a host name resolving to a loopback address is refused as well
+	_, err = newClient(cfg).Get(strings.Replace(srv.URL, "127.0.0.1", "localhost", 1))
+	require.ErrorIs(t, err, httpclient.ErrPrivateAddress)
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+func TestIsPrivate(t *testing.T) {
+	for addr, private := range map[string]bool{
+		"127.0.0.1":        true,
+		"::1":              true,
+		"10.1.2.3":         true,
+		"172.16.0.1":       true,
+		"192.168.1.1":      true,
+		"169.254.169.254":  true,
+		"100.100.100.200":  true,
+		"0.0.0.0":          true,
+		"::ffff:127.0.0.1": true,
+		"fd00::1":          true,
+		"fe80::1":          true,
+		"93.184.215.14":    false,
+		"2606:4700::1111":  false,
+	} {
+		assert.Equal(t, private, httpclient.IsPrivate(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,49 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// ErrPrivateAddress fails connections of clients with Config.DenyPrivateNetworks to addresses IsPrivate reports
+var ErrPrivateAddress = errors.New("destination is a loopback, private or link-local address")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which some clouds use for internal services
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPrivate reports whether addr is a loopback, private, link-local or unspecified address,
+// i.e. one that URLs given by partners must not reach, such as 127.0.0.1, 10.0.0.1 or the metadata
+// service of clouds at 169.254.169.254.
+func IsPrivate(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsUnspecified() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// denyPrivate is a net.Dialer.Control refusing to connect to private addresses.
+// It runs after the host name is resolved, so names resolving, or later rebinding, to such an address are refused too.
+func denyPrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	if IsPrivate(addr) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, addr)
+	}
+
+	return nil
+}
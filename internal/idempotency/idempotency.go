@@ -0,0 +1,36 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Record is a stored request fingerprint and, once completed, its response.
+type Record struct {
+	Key         string
+	Fingerprint string
+	// StatusCode is 0 until the response is saved
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Completed reports whether the response of the record is stored and can be replayed.
+func (r *Record) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// Store persists idempotency records.
+// Each method runs on its own, so that no connection is held while the request runs.
+type Store interface {
+	// Claim inserts the key, or takes it over when it expired or the request holding it with the same fingerprint
+	// stopped before lockedUntil without saving a response. claimed reports whether the caller holds the key;
+	// otherwise record is the one of the request that holds it, or whose response was saved.
+	Claim(ctx context.Context, key, fingerprint string, lockedUntil, expiresAt time.Time) (record *Record, claimed bool, err error)
+	// Save stores the response of the request holding the key
+	Save(ctx context.Context, key string, statusCode int, header http.Header, body []byte) error
+	// Release deletes the key of a request whose response is not saved, so that a retry runs it again
+	Release(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
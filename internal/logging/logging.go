@@ -0,0 +1,200 @@
+// Package logging controls the log level at runtime, by default and for single packages or routes,
+// so that production can be debugged without a redeploy.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// ParseLevel parses the levels accepted by the configuration: debug, info, warn (or warning) and error
+func ParseLevel(s string) (zerolog.Level, error) {
+	switch s {
+	case "debug":
+		return zerolog.DebugLevel, nil
+	case "info":
+		return zerolog.InfoLevel, nil
+	case "warning", "warn":
+		return zerolog.WarnLevel, nil
+	case "error":
+		return zerolog.ErrorLevel, nil
+	default:
+		return zerolog.NoLevel, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", s)
+	}
+}
+
+type routeKey struct{}
+
+// WithRoute records the route of a request in ctx, so that Levels can apply the level of the route
+// to events of loggers carrying ctx
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// Levels is a zerolog.Hook that drops events below the level in effect for them:
+// the level of their route or package if one is set, else the default level.
+// When both are set, the more verbose one wins.
+//
+// It lowers zerolog.GlobalLevel to the most verbose level set, so that it sees every event that may pass.
+type Levels struct {
+	mu       sync.RWMutex
+	def      zerolog.Level
+	packages map[string]zerolog.Level
+	routes   map[string]zerolog.Level
+}
+
+// NewLevels creates levels with a default level and sets zerolog.GlobalLevel accordingly
+func NewLevels(def zerolog.Level) *Levels {
+	l := &Levels{def: def, packages: map[string]zerolog.Level{}, routes: map[string]zerolog.Level{}}
+	l.apply()
+
+	return l
+}
+
+// Snapshot is the state of Levels
+type Snapshot struct {
+	Default string `json:"default"`
+	// Packages maps package paths, or their suffixes such as internal/webhook, to levels
+	Packages map[string]string `json:"packages"`
+	// Routes maps route patterns such as /users/:id to levels
+	Routes map[string]string `json:"routes"`
+}
+
+// Snapshot returns the levels in effect
+func (l *Levels) Snapshot() Snapshot {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	s := Snapshot{Default: l.def.String(), Packages: map[string]string{}, Routes: map[string]string{}}
+	for pkg, level := range l.packages {
+		s.Packages[pkg] = level.String()
+	}
+	for route, level := range l.routes {
+		s.Routes[route] = level.String()
+	}
+
+	return s
+}
+
+// SetDefault changes the default level
+func (l *Levels) SetDefault(level zerolog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.def = level
+	l.apply()
+}
+
+// SetPackage sets the level of a package. zerolog.NoLevel removes it.
+func (l *Levels) SetPackage(pkg string, level zerolog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	set(l.packages, pkg, level)
+	l.apply()
+}
+
+// SetRoute sets the level of a route. zerolog.NoLevel removes it.
+func (l *Levels) SetRoute(route string, level zerolog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	set(l.routes, route, level)
+	l.apply()
+}
+
+func set(m map[string]zerolog.Level, scope string, level zerolog.Level) {
+	if level == zerolog.NoLevel {
+		delete(m, scope)
+		return
+	}
+
+	m[scope] = level
+}
+
+// apply lowers the global level to the most verbose level set. l.mu must be held.
+func (l *Levels) apply() {
+	lowest := l.def
+	for level := range maps.Values(l.packages) {
+		lowest = min(lowest, level)
+	}
+	for level := range maps.Values(l.routes) {
+		lowest = min(lowest, level)
+	}
+
+	zerolog.SetGlobalLevel(lowest)
+}
+
+// Run implements zerolog.Hook
+func (l *Levels) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level == zerolog.NoLevel {
+		return
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	threshold, scoped := zerolog.NoLevel, false
+	if len(l.routes) > 0 {
+		if route, ok := e.GetCtx().Value(routeKey{}).(string); ok {
+			if routeLevel, ok := l.routes[route]; ok {
+				threshold, scoped = routeLevel, true
+			}
+		}
+	}
+	if len(l.packages) > 0 {
+		if pkgLevel, ok := l.packageLevel(callerPackage()); ok && (!scoped || pkgLevel < threshold) {
+			threshold, scoped = pkgLevel, true
+		}
+	}
+	if !scoped {
+		threshold = l.def
+	}
+
+	if level < threshold {
+		e.Discard()
+	}
+}
+
+// packageLevel returns the level set for pkg or a suffix of it. l.mu must be held.
+func (l *Levels) packageLevel(pkg string) (zerolog.Level, bool) {
+	for scope, level := range l.packages {
+		if pkg == scope || strings.HasSuffix(pkg, "/"+scope) {
+			return level, true
+		}
+	}
+
+	return zerolog.NoLevel, false
+}
+
+// callerPackage returns the package of the code that logged the event, skipping zerolog and this package
+func callerPackage() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		pkg := packageOf(frame.Function)
+		if pkg != "github.com/rs/zerolog" && pkg != "github.com/rs/zerolog/log" && !strings.HasSuffix(pkg, "/internal/logging") {
+			return pkg
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// packageOf returns the package path of a function name such as example.com/a/b.(*T).Method
+func packageOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+
+	return function
+}
@@ -0,0 +1,81 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/logging"
+)
+
+func TestParseLevel(t *testing.T) {
+	for s, want := range map[string]zerolog.Level{
+		"debug":   zerolog.DebugLevel,
+		"info":    zerolog.InfoLevel,
+		"warn":    zerolog.WarnLevel,
+		"warning": zerolog.WarnLevel,
+		"error":   zerolog.ErrorLevel,
+	} {
+		level, err := logging.ParseLevel(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, level, s)
+	}
+
+	_, err := logging.ParseLevel("trace")
+	assert.Error(t, err)
+}
+
+func TestLevels(t *testing.T) {
+	t.Cleanup(func() { zerolog.SetGlobalLevel(zerolog.TraceLevel) })
+
+	var buf bytes.Buffer
+	levels := logging.NewLevels(zerolog.InfoLevel)
+	logger := zerolog.New(&buf).Hook(levels)
+	usersCtx := logging.WithRoute(context.Background(), "/users/:id")
+
+	logged := func(ctx context.Context) bool {
+		buf.Reset()
+		logger.Debug().Ctx(ctx).Msg("debug")
+		return buf.Len() > 0
+	}
+
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	assert.False(t, logged(usersCtx))
+
+	t.Run("route", func(t *testing.T) {
+		levels.SetRoute("/users/:id", zerolog.DebugLevel)
+		assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+		assert.True(t, logged(usersCtx))
+		assert.False(t, logged(logging.WithRoute(context.Background(), "/webhooks")))
+		assert.False(t, logged(context.Background()))
+
+		levels.SetRoute("/users/:id", zerolog.NoLevel)
+		assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+		assert.False(t, logged(usersCtx))
+	})
+
+	t.Run("package", func(t *testing.T) {
+		levels.SetPackage("internal/logging_test", zerolog.DebugLevel)
+		assert.True(t, logged(context.Background()))
+
+		levels.SetPackage("internal/webhook", zerolog.DebugLevel)
+		levels.SetPackage("internal/logging_test", zerolog.NoLevel)
+		assert.False(t, logged(context.Background()))
+		levels.SetPackage("internal/webhook", zerolog.NoLevel)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		levels.SetDefault(zerolog.ErrorLevel)
+		buf.Reset()
+		logger.Warn().Msg("warn")
+		assert.Zero(t, buf.Len())
+
+		levels.SetDefault(zerolog.DebugLevel)
+		assert.True(t, logged(context.Background()))
+		assert.Equal(t, logging.Snapshot{Default: "debug", Packages: map[string]string{}, Routes: map[string]string{}}, levels.Snapshot())
+	})
+}
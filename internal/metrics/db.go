@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QueryTracer implements pgx.QueryTracer and records DBQueryDuration for every statement.
+type QueryTracer struct{}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	name  string
+	start time.Time
+}
+
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{}
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, &queryStart{name: QueryName(data.SQL), start: time.Now()})
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	qs, ok := ctx.Value(queryStartKey{}).(*queryStart)
+	if !ok {
+		return
+	}
+
+	status := "ok"
+	if data.Err != nil {
+		status = "error"
+	}
+
+	DBQueryDuration.WithLabelValues(qs.name, status).Observe(time.Since(qs.start).Seconds())
+}
+
+// QueryName extracts the sqlc query name from the "-- name: X :kind" header sqlc prepends to every statement.
+// Statements not generated by sqlc are reported as "other" to keep label cardinality bounded.
+func QueryName(sql string) string {
+	const prefix = "-- name: "
+
+	if !strings.HasPrefix(sql, prefix) {
+		return "other"
+	}
+
+	name, _, _ := strings.Cut(sql[len(prefix):], " ")
+	return name
+}
+
+// poolCollector exposes pgxpool.Stat as Prometheus metrics, read on every scrape.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns *prometheus.Desc
+	idleConns     *prometheus.Desc
+	totalConns    *prometheus.Desc
+	maxConns      *prometheus.Desc
+	acquireCount  *prometheus.Desc
+	acquireWait   *prometheus.Desc
+}
+
+var registeredPool prometheus.Collector
+
+// RegisterDBPool exposes the stats of pool on the registry, replacing any previously registered pool.
+func RegisterDBPool(pool *pgxpool.Pool) {
+	if registeredPool != nil {
+		Registry.Unregister(registeredPool)
+	}
+
+	registeredPool = newPoolCollector(pool)
+	Registry.MustRegister(registeredPool)
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:          pool,
+		acquiredConns: desc("acquired_conns", "Number of currently acquired connections."),
+		idleConns:     desc("idle_conns", "Number of currently idle connections."),
+		totalConns:    desc("total_conns", "Total number of connections in the pool."),
+		maxConns:      desc("max_conns", "Maximum size of the pool."),
+		acquireCount:  desc("acquire_total", "Cumulative count of successful acquires."),
+		acquireWait:   desc("acquire_wait_seconds_total", "Cumulative time spent waiting for a connection because the pool was empty."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.acquireWait
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, s.EmptyAcquireWaitTime().Seconds())
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/SoraDaibu/go-clean-starter/config"
+)
+
+// ExportTask exports the collectors of a task, which ends before Prometheus could scrape it:
+// it pushes them to metrics.pushgateway_url as job, replacing those of its previous run,
+// and writes them to metrics.textfile_path for node-exporter. Both are optional; without either it does nothing.
+func ExportTask(ctx context.Context, c *config.Config, job string, collectors ...prometheus.Collector) error {
+	var errs []error
+
+	if url := c.Metrics.PushgatewayURL; url != "" {
+		pusher := push.New(url, job)
+		for _, collector := range collectors {
+			pusher.Collector(collector)
+		}
+		if err := pusher.PushContext(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to push metrics to the Pushgateway: %w", err))
+		}
+	}
+
+	if path := c.Metrics.TextfilePath; path != "" {
+		registry := prometheus.NewRegistry()
+		for _, collector := range collectors {
+			if err := registry.Register(collector); err != nil {
+				return err
+			}
+		}
+		// written to a temporary file and renamed, so node-exporter never reads a partial file
+		if err := prometheus.WriteToTextfile(path, registry); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write metrics to %s: %w", path, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
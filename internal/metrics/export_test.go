@@ -0,0 +1,72 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+)
+
+func TestExportTask(t *testing.T) {
+	ctx := context.Background()
+	newCounter := func() *prometheus.CounterVec {
+		counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_items_total", Help: "Test."}, []string{"result"})
+		counter.WithLabelValues("created").Add(3)
+		return counter
+	}
+
+	t.Run("pushes to the Pushgateway", func(t *testing.T) {
+		var method, path string
+		var body []byte
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method, path = r.Method, r.URL.Path
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer gateway.Close()
+
+		var cfg config.Config
+		cfg.Metrics.PushgatewayURL = gateway.URL
+		require.NoError(t, metrics.ExportTask(ctx, &cfg, "import", newCounter()))
+
+		// PUT replaces the metrics of the previous run of the job
+		assert.Equal(t, http.MethodPut, method)
+		assert.Equal(t, "/metrics/job/import", path)
+		assert.Contains(t, string(body), "test_items_total")
+	})
+
+	t.Run("reports a failing Pushgateway", func(t *testing.T) {
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer gateway.Close()
+
+		var cfg config.Config
+		cfg.Metrics.PushgatewayURL = gateway.URL
+		require.ErrorContains(t, metrics.ExportTask(ctx, &cfg, "import", newCounter()), "failed to push metrics")
+	})
+
+	t.Run("writes the textfile of node-exporter", func(t *testing.T) {
+		var cfg config.Config
+		cfg.Metrics.TextfilePath = filepath.Join(t.TempDir(), "import.prom")
+		require.NoError(t, metrics.ExportTask(ctx, &cfg, "import", newCounter()))
+
+		b, err := os.ReadFile(cfg.Metrics.TextfilePath)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `test_items_total{result="created"} 3`)
+	})
+
+	t.Run("does nothing unless configured", func(t *testing.T) {
+		require.NoError(t, metrics.ExportTask(ctx, &config.Config{}, "import", newCounter()))
+	})
+}
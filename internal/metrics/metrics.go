@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "go_clean_starter"
+
+// Registry holds every collector exposed on /metrics.
+// A dedicated registry keeps tests and multiple servers in one process from clashing on the global one.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestsTotal counts handled requests per route template, method and status code.
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests handled.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes request latency per route template and method.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// DBQueryDuration observes SQL statement latency per sqlc query name.
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of SQL statements.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"query", "status"})
+
+	// HTTPClientRequestsTotal counts requests to other services per host, method and status code, one per attempt.
+	HTTPClientRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http_client",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests sent to other services, by status code or error.",
+	}, []string{"host", "method", "status"})
+
+	// HTTPClientRequestDuration observes the latency of requests to other services per host and method.
+	HTTPClientRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http_client",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests sent to other services.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"host", "method"})
+
+	// HTTPClientRetriesTotal counts retried requests to other services per host.
+	HTTPClientRetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http_client",
+		Name:      "retries_total",
+		Help:      "Number of retries of HTTP requests sent to other services.",
+	}, []string{"host"})
+
+	// HTTPClientCircuitState is the circuit breaker state per host: 0 closed, 1 half-open, 2 open.
+	HTTPClientCircuitState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http_client",
+		Name:      "circuit_state",
+		Help:      "Circuit breaker state of other services: 0 closed, 1 half-open, 2 open.",
+	}, []string{"host"})
+
+	// CacheRequestsTotal counts cache lookups of the repositories per entity and result: hit, miss or error.
+	CacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Number of cache lookups by result.",
+	}, []string{"entity", "result"})
+
+	// ImportItemsTotal counts rows processed by the item import task by outcome. Exported by ExportTask.
+	ImportItemsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "import",
+		Name:      "items_total",
+		Help:      "Number of imported item rows by result (created, skipped, error).",
+	}, []string{"result", "dry_run"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		DBQueryDuration,
+		HTTPClientRequestsTotal,
+		HTTPClientRequestDuration,
+		HTTPClientRetriesTotal,
+		HTTPClientCircuitState,
+		CacheRequestsTotal,
+		ImportItemsTotal,
+	)
+}
+
+// Handler returns an http.Handler serving the registry in Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
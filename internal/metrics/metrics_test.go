@@ -0,0 +1,117 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+)
+
+// gather returns the metrics of the family name in the registry
+func gather(t *testing.T, name string) []*dto.Metric {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() == name {
+			return f.GetMetric()
+		}
+	}
+	return nil
+}
+
+// histogramCount returns the number of observations of the histogram of family name with labels
+func histogramCount(t *testing.T, name string, labels map[string]string) uint64 {
+	t.Helper()
+
+	for _, m := range gather(t, name) {
+		got := map[string]string{}
+		for _, l := range m.GetLabel() {
+			got[l.GetName()] = l.GetValue()
+		}
+		if assert.ObjectsAreEqual(labels, got) {
+			return m.GetHistogram().GetSampleCount()
+		}
+	}
+	return 0
+}
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{sql: "-- name: GetUser :one\nSELECT * FROM users WHERE id = $1", expected: "GetUser"},
+		{sql: "-- name: DeleteExpiredIdempotencyKeys :execrows\nDELETE FROM idempotency_keys", expected: "DeleteExpiredIdempotencyKeys"},
+		{sql: "SELECT 1", expected: "other"},
+		{sql: "  -- name: GetUser :one", expected: "other"},
+		{sql: "", expected: "other"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, metrics.QueryName(tt.sql), tt.sql)
+	}
+}
+
+func TestQueryTracer(t *testing.T) {
+	tracer := metrics.NewQueryTracer()
+	ok := map[string]string{"query": "TestQueryTracer", "status": "ok"}
+	failed := map[string]string{"query": "TestQueryTracer", "status": "error"}
+	before := histogramCount(t, "go_clean_starter_db_query_duration_seconds", ok)
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "-- name: TestQueryTracer :one\nSELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "-- name: TestQueryTracer :one\nSELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+
+	assert.Equal(t, before+1, histogramCount(t, "go_clean_starter_db_query_duration_seconds", ok))
+	assert.Equal(t, uint64(1), histogramCount(t, "go_clean_starter_db_query_duration_seconds", failed))
+
+	// queries that did not start through the tracer are not recorded
+	tracer.TraceQueryEnd(context.Background(), nil, pgx.TraceQueryEndData{})
+	assert.Equal(t, before+1, histogramCount(t, "go_clean_starter_db_query_duration_seconds", ok))
+}
+
+func TestRegisterDBPool(t *testing.T) {
+	newPool := func(maxConns string) *pgxpool.Pool {
+		// the pool connects lazily, so no database is needed to read its stats
+		pool, err := pgxpool.New(context.Background(), "postgres://db:5432/app?pool_max_conns="+maxConns)
+		require.NoError(t, err)
+		t.Cleanup(pool.Close)
+		return pool
+	}
+
+	metrics.RegisterDBPool(newPool("4"))
+	// replaces the previous pool rather than failing to register twice
+	metrics.RegisterDBPool(newPool("7"))
+
+	maxConns := gather(t, "go_clean_starter_db_pool_max_conns")
+	require.Len(t, maxConns, 1)
+	assert.Equal(t, float64(7), maxConns[0].GetGauge().GetValue())
+	assert.Len(t, gather(t, "go_clean_starter_db_pool_acquire_wait_seconds_total"), 1)
+}
+
+func TestHandler(t *testing.T) {
+	metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/metrics-test", "200").Inc()
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `go_clean_starter_http_requests_total{method="GET",route="/metrics-test",status="200"} 1`)
+	// runtime and process metrics are exposed too
+	assert.Contains(t, string(body), "go_goroutines ")
+}
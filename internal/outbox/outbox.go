@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message is a domain event written to the outbox
+type Message struct {
+	// ID increases in the order events were written and identifies the event to consumers for deduplication
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	// Attempts counts failed publications so far
+	Attempts int32 `json:"-"`
+}
+
+// aggregateKey identifies the aggregate whose events must be published in order
+func (m Message) aggregateKey() string {
+	return m.AggregateType + "/" + m.AggregateID.String()
+}
+
+// Sink publishes messages to consumers.
+// Publish returns nil only once the message is accepted; it may be called again for the same message.
+type Sink interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// Store reads and updates the outbox.
+// TryLock and ClaimPending must be called within the same repository.Transaction, which holds the relay lock;
+// the other methods are called outside of it, while events are published.
+type Store interface {
+	// TryLock reports whether the relay lock was taken; it is released when the transaction ends
+	TryLock(ctx context.Context) (bool, error)
+	// ClaimPending lists up to limit pending events, in the order they were written, of the aggregates whose earliest
+	// pending event is due. Their next attempt is delayed until claimedUntil, so that other relays skip their aggregates meanwhile.
+	ClaimPending(ctx context.Context, limit int, claimedUntil time.Time) ([]Message, error)
+	MarkPublished(ctx context.Context, id int64) error
+	// RecordFailure counts a failed attempt and delays the next one until nextAttemptAt
+	RecordFailure(ctx context.Context, id int64, reason string, nextAttemptAt time.Time) error
+	// DeadLetter counts a failed attempt and gives up on the event, letting the later events of its aggregate be published
+	DeadLetter(ctx context.Context, id int64, reason string) error
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
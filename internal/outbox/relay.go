@@ -0,0 +1,177 @@
+package outbox
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/backoff"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+)
+
+// Config tunes the relay
+type Config struct {
+	// BatchSize is the maximum number of events claimed at once
+	BatchSize int
+	// PollInterval is how long to wait for new events once the outbox is drained
+	PollInterval time.Duration
+	// Retention is how long published events are kept before being deleted
+	Retention time.Duration
+	// MaxAttempts is how many times an event is published before it is dead-lettered
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; later retries double it up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// ClaimTimeout is how long a relay may publish the events it claimed before other relays claim them again
+	ClaimTimeout time.Duration
+}
+
+// Relay publishes pending outbox events to a Sink.
+//
+// Events are marked as published only after the sink accepted them, so they are delivered at least once.
+// Events of the same aggregate are published in the order they were written: when one fails,
+// the later events of its aggregate wait until it succeeds, while the other aggregates go on.
+// Writes to an aggregate lock its row, so an event is never committed after a later event of the same aggregate.
+// An event failing Config.MaxAttempts times is dead-lettered: it is kept with its last error but no longer published,
+// and the later events of its aggregate go on.
+type Relay struct {
+	tx     repository.Transaction
+	store  Store
+	sink   Sink
+	cfg    Config
+	now    func() time.Time
+	jitter func() float64
+}
+
+// NewRelay creates a new relay
+// Following DIP: depends on the store and sink interfaces, not concrete implementations
+func NewRelay(tx repository.Transaction, store Store, sink Sink, cfg Config) *Relay {
+	return &Relay{tx: tx, store: store, sink: sink, cfg: cfg, now: time.Now, jitter: rand.Float64}
+}
+
+// Run relays events until ctx is cancelled.
+// Several relays may run at the same time; each publishes the events of the aggregates it claimed.
+func (r *Relay) Run(ctx context.Context) error {
+	logger := zerolog.Ctx(ctx)
+	poll := time.NewTicker(r.cfg.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	r.cleanup(ctx)
+	for {
+		published, err := r.RelayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error().Err(err).Msg("failed to relay outbox events")
+		}
+
+		// keep going while the outbox has a backlog
+		if err == nil && published == r.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-cleanup.C:
+			r.cleanup(ctx)
+		case <-poll.C:
+		}
+	}
+}
+
+// RelayBatch publishes up to Config.BatchSize pending events and returns how many were published.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	claimedUntil := r.now().Add(r.cfg.ClaimTimeout)
+	messages, err := r.claim(ctx, claimedUntil)
+	if err != nil {
+		return 0, err
+	}
+
+	// stop before the claim expires, when other relays may publish the remaining events
+	publishCtx, cancel := context.WithDeadline(ctx, claimedUntil)
+	defer cancel()
+
+	published := 0
+	blocked := map[string]bool{}
+	for _, msg := range messages {
+		key := msg.aggregateKey()
+		if blocked[key] {
+			continue
+		}
+
+		if err := r.sink.Publish(publishCtx, msg); err != nil {
+			// the remaining events are claimed again once the claim expired
+			if publishCtx.Err() != nil {
+				break
+			}
+
+			blocked[key] = true
+			if err := r.recordFailure(ctx, msg, err); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := r.store.MarkPublished(ctx, msg.ID); err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	if published > 0 {
+		zerolog.Ctx(ctx).Info().Int("published", published).Msg("outbox events published")
+	}
+
+	return published, nil
+}
+
+// claim takes the events to publish in a transaction that ends before they are published,
+// so that no connection is held while waiting for the sink
+func (r *Relay) claim(ctx context.Context, claimedUntil time.Time) ([]Message, error) {
+	var messages []Message
+
+	// not retried, as a failed claim is taken again by the next poll
+	err := r.tx.Do(ctx, func(ctx context.Context) error {
+		locked, err := r.store.TryLock(ctx)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			zerolog.Ctx(ctx).Debug().Msg("another relay is claiming outbox events")
+			return nil
+		}
+
+		messages, err = r.store.ClaimPending(ctx, r.cfg.BatchSize, claimedUntil)
+		return err
+	}, repository.WithoutRetry())
+
+	return messages, err
+}
+
+// recordFailure delays the next attempt of msg, or dead-letters it after Config.MaxAttempts
+func (r *Relay) recordFailure(ctx context.Context, msg Message, publishErr error) error {
+	attempts := int(msg.Attempts) + 1
+	logger := zerolog.Ctx(ctx).With().Int64("event_id", msg.ID).Str("event_type", msg.Type).Int("attempts", attempts).Logger()
+
+	if attempts >= r.cfg.MaxAttempts {
+		logger.Error().Err(publishErr).Msg("outbox event dead-lettered")
+		return r.store.DeadLetter(ctx, msg.ID, publishErr.Error())
+	}
+
+	logger.Warn().Err(publishErr).Msg("failed to publish outbox event")
+	nextAttemptAt := r.now().Add(backoff.Delay(attempts, r.cfg.BaseDelay, r.cfg.MaxDelay, r.jitter()))
+	return r.store.RecordFailure(ctx, msg.ID, publishErr.Error(), nextAttemptAt)
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.store.DeletePublished(ctx, time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to delete published outbox events")
+		return
+	}
+
+	zerolog.Ctx(ctx).Debug().Int64("deleted", deleted).Msg("published outbox events deleted")
+}
@@ -0,0 +1,212 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/outbox"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+)
+
+type fakeTransaction struct{}
+
+func (fakeTransaction) Do(ctx context.Context, fn func(ctx context.Context) error, _ ...repository.TxOption) error {
+	return fn(ctx)
+}
+
+// fakeStore claims events like the Postgres store: an aggregate is skipped while its earliest pending event is not due at now
+type fakeStore struct {
+	locked        bool
+	now           time.Time
+	messages      []outbox.Message
+	published     map[int64]bool
+	deadLettered  map[int64]bool
+	failures      map[int64]int
+	nextAttemptAt map[int64]time.Time
+}
+
+func newFakeStore(messages ...outbox.Message) *fakeStore {
+	return &fakeStore{
+		now:           time.Now(),
+		messages:      messages,
+		published:     map[int64]bool{},
+		deadLettered:  map[int64]bool{},
+		failures:      map[int64]int{},
+		nextAttemptAt: map[int64]time.Time{},
+	}
+}
+
+func (s *fakeStore) TryLock(context.Context) (bool, error) { return !s.locked, nil }
+
+func (s *fakeStore) pending(m outbox.Message) bool {
+	return !s.published[m.ID] && !s.deadLettered[m.ID]
+}
+
+func (s *fakeStore) ClaimPending(_ context.Context, limit int, claimedUntil time.Time) ([]outbox.Message, error) {
+	due := map[uuid.UUID]bool{}
+	for _, m := range s.messages {
+		if _, seen := due[m.AggregateID]; !seen && s.pending(m) {
+			due[m.AggregateID] = !s.nextAttemptAt[m.ID].After(s.now)
+		}
+	}
+
+	var claimed []outbox.Message
+	for _, m := range s.messages {
+		if s.pending(m) && due[m.AggregateID] && len(claimed) < limit {
+			m.Attempts = int32(s.failures[m.ID])
+			s.nextAttemptAt[m.ID] = claimedUntil
+			claimed = append(claimed, m)
+		}
+	}
+	return claimed, nil
+}
+
+func (s *fakeStore) MarkPublished(_ context.Context, id int64) error {
+	s.published[id] = true
+	return nil
+}
+
+func (s *fakeStore) RecordFailure(_ context.Context, id int64, _ string, nextAttemptAt time.Time) error {
+	s.failures[id]++
+	s.nextAttemptAt[id] = nextAttemptAt
+	return nil
+}
+
+func (s *fakeStore) DeadLetter(_ context.Context, id int64, _ string) error {
+	s.failures[id]++
+	s.deadLettered[id] = true
+	return nil
+}
+
+func (s *fakeStore) DeletePublished(context.Context, time.Time) (int64, error) { return 0, nil }
+
+// recordingSink fails the first attempt of the events in failOnce, and every attempt of those in failAlways
+type recordingSink struct {
+	failOnce   map[int64]bool
+	failAlways map[int64]bool
+	published  []int64
+}
+
+func (s *recordingSink) Publish(_ context.Context, msg outbox.Message) error {
+	if s.failOnce[msg.ID] || s.failAlways[msg.ID] {
+		delete(s.failOnce, msg.ID)
+		return errors.New("unavailable")
+	}
+	s.published = append(s.published, msg.ID)
+	return nil
+}
+
+// blockingSink waits for the context of the publication to end
+type blockingSink struct{}
+
+func (blockingSink) Publish(ctx context.Context, _ outbox.Message) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRelay_RelayBatch(t *testing.T) {
+	ctx := context.Background()
+	userA, userB := uuid.New(), uuid.New()
+	message := func(id int64, aggregateID uuid.UUID) outbox.Message {
+		return outbox.Message{ID: id, Type: "user.updated", AggregateType: "user", AggregateID: aggregateID}
+	}
+	cfg := outbox.Config{BatchSize: 10, MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour, ClaimTimeout: time.Minute}
+
+	t.Run("publishes in order and marks events published", func(t *testing.T) {
+		store := newFakeStore(message(1, userA), message(2, userB), message(3, userA))
+		sink := &recordingSink{}
+		relay := outbox.NewRelay(fakeTransaction{}, store, sink, cfg)
+
+		published, err := relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, published)
+		assert.Equal(t, []int64{1, 2, 3}, sink.published)
+		assert.Len(t, store.published, 3)
+	})
+
+	t.Run("holds back later events of an aggregate whose event failed until its retry", func(t *testing.T) {
+		store := newFakeStore(message(1, userA), message(2, userB), message(3, userA))
+		sink := &recordingSink{failOnce: map[int64]bool{1: true}}
+		relay := outbox.NewRelay(fakeTransaction{}, store, sink, cfg)
+
+		published, err := relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, []int64{2}, sink.published)
+		assert.Equal(t, 1, store.failures[1])
+		assert.WithinRange(t, store.nextAttemptAt[1], time.Now().Add(cfg.BaseDelay/2-time.Second), time.Now().Add(cfg.BaseDelay))
+
+		published, err = relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, published, "the retry is not due yet")
+
+		store.now = store.now.Add(cfg.BaseDelay)
+		published, err = relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+		assert.Equal(t, []int64{2, 1, 3}, sink.published)
+	})
+
+	t.Run("does not let a failing aggregate hold back the others", func(t *testing.T) {
+		store := newFakeStore(message(1, userA), message(2, userA), message(3, userA), message(4, userB))
+		sink := &recordingSink{failAlways: map[int64]bool{1: true}}
+		relay := outbox.NewRelay(fakeTransaction{}, store, sink, outbox.Config{BatchSize: 2, MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour, ClaimTimeout: time.Minute})
+
+		published, err := relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, published)
+
+		published, err = relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, []int64{4}, sink.published)
+	})
+
+	t.Run("dead-letters events after the maximum attempts", func(t *testing.T) {
+		store := newFakeStore(message(1, userA), message(2, userA))
+		sink := &recordingSink{failAlways: map[int64]bool{1: true}}
+		relay := outbox.NewRelay(fakeTransaction{}, store, sink, cfg)
+
+		for range cfg.MaxAttempts {
+			published, err := relay.RelayBatch(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, published)
+			store.now = store.now.Add(cfg.MaxDelay)
+		}
+		assert.True(t, store.deadLettered[1])
+		assert.Equal(t, cfg.MaxAttempts, store.failures[1])
+
+		published, err := relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, []int64{2}, sink.published)
+	})
+
+	t.Run("stops publishing when the claim expires", func(t *testing.T) {
+		store := newFakeStore(message(1, userA), message(2, userB))
+		relay := outbox.NewRelay(fakeTransaction{}, store, blockingSink{}, outbox.Config{BatchSize: 10, MaxAttempts: 3, ClaimTimeout: 50 * time.Millisecond})
+
+		published, err := relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, published)
+		assert.Empty(t, store.failures, "events not published in time are claimed again without counting an attempt")
+	})
+
+	t.Run("does nothing while another relay holds the lock", func(t *testing.T) {
+		store := newFakeStore(message(1, userA))
+		store.locked = true
+		sink := &recordingSink{}
+		relay := outbox.NewRelay(fakeTransaction{}, store, sink, cfg)
+
+		published, err := relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, published)
+		assert.Empty(t, sink.published)
+	})
+}
@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Sink names accepted by config.Config.Outbox.Sink
+const (
+	SinkStdout  = "stdout"
+	SinkFile    = "file"
+	SinkWebhook = "webhook"
+	SinkNATS    = "nats"
+	// SinkWebhooks creates deliveries for webhook subscriptions
+	SinkWebhooks = "webhooks"
+)
+
+// Headers sent with each published message so that consumers can route and deduplicate it
+const (
+	HeaderEventID   = "X-Event-ID"
+	HeaderEventType = "X-Event-Type"
+)
+
+// MultiSink publishes each message to every sink in order.
+// It fails as soon as one sink fails, and the message is published again to all of them later.
+type MultiSink []Sink
+
+// Publish implements Sink
+func (s MultiSink) Publish(ctx context.Context, msg Message) error {
+	for _, sink := range s {
+		if err := sink.Publish(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriterSink writes messages as JSON lines, e.g. to stdout or a file for local testing
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink writing to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Publish implements Sink
+func (s *WriterSink) Publish(_ context.Context, msg Message) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// WebhookSink POSTs each message as JSON to a URL.
+// Any response other than 2xx is a failure and the message is published again later.
+type WebhookSink struct {
+	client *http.Client
+	url    string
+}
+
+// NewWebhookSink creates a sink posting to url with client
+func NewWebhookSink(client *http.Client, url string) *WebhookSink {
+	return &WebhookSink{client: client, url: url}
+}
+
+// Publish implements Sink
+func (s *WebhookSink) Publish(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, strconv.FormatInt(msg.ID, 10))
+	req.Header.Set(HeaderEventType, msg.Type)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", res.Status)
+	}
+
+	return nil
+}
+
+// NATSSink publishes messages to JetStream on subject "<prefix>.<event type>", e.g. events.user.created.
+// A stream must capture the subjects; the event ID is sent as Nats-Msg-Id so that JetStream drops duplicates.
+type NATSSink struct {
+	js     jetstream.JetStream
+	prefix string
+}
+
+// NewNATSSink creates a sink publishing with js
+func NewNATSSink(js jetstream.JetStream, subjectPrefix string) *NATSSink {
+	return &NATSSink{js: js, prefix: subjectPrefix}
+}
+
+// Publish implements Sink
+func (s *NATSSink) Publish(ctx context.Context, msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	m := nats.NewMsg(s.prefix + "." + msg.Type)
+	m.Data = data
+	m.Header.Set(HeaderEventType, msg.Type)
+
+	_, err = s.js.PublishMsg(ctx, m, jetstream.WithMsgID(strconv.FormatInt(msg.ID, 10)))
+	return err
+}
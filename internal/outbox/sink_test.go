@@ -0,0 +1,85 @@
+package outbox_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/outbox"
+)
+
+func newMessage() outbox.Message {
+	return outbox.Message{
+		ID:            42,
+		Type:          "user.created",
+		AggregateType: "user",
+		AggregateID:   uuid.New(),
+		Payload:       json.RawMessage(`{"name":"John"}`),
+		OccurredAt:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestWriterSink_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	sink := outbox.NewWriterSink(&buf)
+	msg := newMessage()
+
+	require.NoError(t, sink.Publish(context.Background(), msg))
+	require.NoError(t, sink.Publish(context.Background(), msg))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var got outbox.Message
+	require.NoError(t, json.Unmarshal(lines[0], &got))
+	assert.Equal(t, msg, got)
+}
+
+func TestWebhookSink_Publish(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "server error is retried later", status: http.StatusServiceUnavailable, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := newMessage()
+			var header http.Header
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := outbox.NewWebhookSink(server.Client(), server.URL).Publish(context.Background(), msg)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, "42", header.Get(outbox.HeaderEventID))
+			assert.Equal(t, "user.created", header.Get(outbox.HeaderEventType))
+			assert.Equal(t, "application/json", header.Get("Content-Type"))
+
+			var got outbox.Message
+			require.NoError(t, json.Unmarshal(body, &got))
+			assert.Equal(t, msg, got)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	limit := PerMinute(60, 2) // 1 token per second, burst of 2
+
+	res, err := store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Limit)
+	assert.Equal(t, 1, res.Remaining)
+
+	res, err = store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 2*time.Second, res.Reset)
+
+	// bucket is empty
+	res, err = store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	// other keys have their own bucket
+	res, err = store.Take(ctx, "ip:5.6.7.8", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	// refilled after a second
+	now = now.Add(time.Second)
+	res, err = store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+}
+
+func TestMemoryStore_SweepsFullBuckets(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	_, err := store.Take(context.Background(), "ip:1.2.3.4", PerMinute(60, 2))
+	require.NoError(t, err)
+
+	now = now.Add(2 * sweepInterval)
+	_, err = store.Take(context.Background(), "ip:5.6.7.8", PerMinute(60, 2))
+	require.NoError(t, err)
+
+	assert.Len(t, store.buckets, 1)
+}
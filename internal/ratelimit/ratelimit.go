@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit configures a token bucket: Burst tokens at most, refilled at Rate tokens per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute returns a Limit allowing n requests per minute with the given burst.
+func PerMinute(n, burst int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: burst}
+}
+
+// Enabled reports whether the limit restricts anything.
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result is the outcome of taking a token, used to build RateLimit-* response headers.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets by key.
+// Implementations must make Take atomic per key, also across processes when shared by replicas.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// NewResult builds a Result from the tokens left in a bucket after a take.
+func NewResult(allowed bool, tokens float64, limit Limit) Result {
+	r := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     secondsToDuration((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+
+	if !allowed {
+		r.RetryAfter = secondsToDuration((1 - tokens) / limit.Rate)
+	}
+
+	return r
+}
+
+func secondsToDuration(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil(s)) * time.Second
+}
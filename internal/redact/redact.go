@@ -0,0 +1,227 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces every redacted value.
+const Mask = "[REDACTED]"
+
+// DefaultFieldNames are always redacted, in addition to Config.FieldNames.
+var DefaultFieldNames = []string{"password", "token", "secret", "authorization"}
+
+// DefaultHeaders are always redacted, in addition to Config.Headers.
+var DefaultHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization", "X-Api-Key"}
+
+var emailPattern = regexp.MustCompile(`([A-Za-z0-9._%+\-])[A-Za-z0-9._%+\-]*@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+type Config struct {
+	// FieldNames are matched case-insensitively as substrings of JSON keys and query parameter names at any depth,
+	// so "token" also covers "access_token".
+	FieldNames []string
+	// JSONPaths are dot-separated key paths from the document root, e.g. "user.profile.phone".
+	// "*" matches any single key. Array elements do not add a segment.
+	JSONPaths []string
+	// Headers are matched case-insensitively.
+	Headers []string
+	// MaskEmails replaces e-mail addresses in values with "j***@example.com".
+	MaskEmails bool
+	// MaxBodyBytes truncates bodies after redaction. 0 disables the cap.
+	MaxBodyBytes int
+	// BodySampleRate is the fraction (0..1) of requests whose bodies are logged.
+	BodySampleRate float64
+}
+
+// Redactor removes secrets and PII from data before it is logged.
+// It is safe for concurrent use.
+type Redactor struct {
+	fieldNames   []string
+	jsonPaths    [][]string
+	headers      map[string]struct{}
+	maskEmails   bool
+	maxBodyBytes int
+	sampleRate   float64
+}
+
+func New(c Config) *Redactor {
+	r := &Redactor{
+		headers:      map[string]struct{}{},
+		maskEmails:   c.MaskEmails,
+		maxBodyBytes: c.MaxBodyBytes,
+		sampleRate:   c.BodySampleRate,
+	}
+
+	for _, f := range append(append([]string{}, DefaultFieldNames...), c.FieldNames...) {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			r.fieldNames = append(r.fieldNames, f)
+		}
+	}
+
+	for _, p := range c.JSONPaths {
+		if p = strings.TrimSpace(p); p != "" {
+			r.jsonPaths = append(r.jsonPaths, strings.Split(strings.TrimPrefix(p, "$."), "."))
+		}
+	}
+
+	for _, h := range append(append([]string{}, DefaultHeaders...), c.Headers...) {
+		if h = strings.TrimSpace(h); h != "" {
+			r.headers[http.CanonicalHeaderKey(h)] = struct{}{}
+		}
+	}
+
+	return r
+}
+
+// SampleBody reports whether the body of the current request should be logged.
+func (r *Redactor) SampleBody() bool {
+	if r.sampleRate >= 1 {
+		return true
+	}
+	if r.sampleRate <= 0 {
+		return false
+	}
+
+	return rand.Float64() < r.sampleRate
+}
+
+// Body redacts a request or response body and caps its size.
+// JSON bodies have denied fields replaced, other bodies only get e-mail masking.
+func (r *Redactor) Body(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	out := r.JSON(body)
+
+	if r.maxBodyBytes > 0 && len(out) > r.maxBodyBytes {
+		return fmt.Sprintf("%s...(truncated %d bytes)", out[:r.maxBodyBytes], len(out)-r.maxBodyBytes)
+	}
+
+	return out
+}
+
+// JSON redacts denied fields and paths in a JSON document.
+// Invalid JSON is treated as plain text.
+func (r *Redactor) JSON(body []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return r.String(string(body))
+	}
+
+	b, err := json.Marshal(r.walk(v, nil))
+	if err != nil {
+		return Mask
+	}
+
+	return string(b)
+}
+
+// String masks e-mail addresses in free text such as error messages.
+func (r *Redactor) String(s string) string {
+	if !r.maskEmails {
+		return s
+	}
+
+	return emailPattern.ReplaceAllString(s, "${1}***@${2}")
+}
+
+// Headers returns a copy of h with denied headers masked.
+func (r *Redactor) Headers(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if _, ok := r.headers[http.CanonicalHeaderKey(k)]; ok {
+			out[k] = Mask
+			continue
+		}
+		out[k] = r.String(strings.Join(v, ", "))
+	}
+
+	return out
+}
+
+// URI masks query parameter values whose names are denied.
+func (r *Redactor) URI(uri string) string {
+	path, rawQuery, ok := strings.Cut(uri, "?")
+	if !ok {
+		return r.String(uri)
+	}
+
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return r.String(path) + "?" + Mask
+	}
+
+	for k := range q {
+		if r.deniedField(k) {
+			q[k] = []string{Mask}
+		}
+	}
+
+	return r.String(path + "?" + q.Encode())
+}
+
+func (r *Redactor) walk(v any, path []string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			childPath := append(path[:len(path):len(path)], k)
+			if r.deniedField(k) || r.deniedPath(childPath) {
+				t[k] = Mask
+				continue
+			}
+			t[k] = r.walk(child, childPath)
+		}
+		return t
+	case []any:
+		for i, child := range t {
+			t[i] = r.walk(child, path)
+		}
+		return t
+	case string:
+		return r.String(t)
+	default:
+		return v
+	}
+}
+
+func (r *Redactor) deniedField(name string) bool {
+	name = strings.ToLower(name)
+	for _, f := range r.fieldNames {
+		if strings.Contains(name, f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Redactor) deniedPath(path []string) bool {
+	for _, p := range r.jsonPaths {
+		if len(p) != len(path) {
+			continue
+		}
+
+		matched := true
+		for i := range p {
+			if p[i] != "*" && p[i] != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
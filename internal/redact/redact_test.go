@@ -0,0 +1,89 @@
+package redact_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/redact"
+)
+
+func TestRedactor_Body(t *testing.T) {
+	r := redact.New(redact.Config{
+		FieldNames: []string{"phone"},
+		JSONPaths:  []string{"profile.*.street"},
+		MaskEmails: true,
+	})
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "password and email in create user request",
+			body:     `{"name":"John Doe","email":"john.doe@example.com","password":"password123"}`,
+			expected: `{"email":"j***@example.com","name":"John Doe","password":"[REDACTED]"}`,
+		},
+		{
+			name:     "field names match as case-insensitive substrings at any depth",
+			body:     `{"data":[{"Access_Token":"abc","mobilePhone":"123"}]}`,
+			expected: `{"data":[{"Access_Token":"[REDACTED]","mobilePhone":"[REDACTED]"}]}`,
+		},
+		{
+			name:     "json paths with wildcard",
+			body:     `{"profile":{"home":{"street":"Main St","city":"Tokyo"}},"street":"kept"}`,
+			expected: `{"profile":{"home":{"city":"Tokyo","street":"[REDACTED]"}},"street":"kept"}`,
+		},
+		{
+			name:     "invalid json only masks emails",
+			body:     `{invalid json from jane@example.com`,
+			expected: `{invalid json from j***@example.com`,
+		},
+		{
+			name:     "empty body",
+			body:     ``,
+			expected: ``,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, r.Body([]byte(tt.body)))
+		})
+	}
+}
+
+func TestRedactor_BodyTruncates(t *testing.T) {
+	r := redact.New(redact.Config{MaxBodyBytes: 10})
+
+	assert.Equal(t, `{"name":"J...(truncated 16 bytes)`, r.Body([]byte(`{"name":"John Doe Junior"}`)))
+}
+
+func TestRedactor_Headers(t *testing.T) {
+	r := redact.New(redact.Config{Headers: []string{"x-session-id"}})
+
+	h := http.Header{}
+	h.Set("Authorization", "Bearer abc")
+	h.Set("X-Session-Id", "123")
+	h.Set("Content-Type", "application/json")
+
+	assert.Equal(t, map[string]string{
+		"Authorization": redact.Mask,
+		"X-Session-Id":  redact.Mask,
+		"Content-Type":  "application/json",
+	}, r.Headers(h))
+}
+
+func TestRedactor_URI(t *testing.T) {
+	r := redact.New(redact.Config{})
+
+	assert.Equal(t, "/users?page=1&token=%5BREDACTED%5D", r.URI("/users?token=abc&page=1"))
+	assert.Equal(t, "/users/1", r.URI("/users/1"))
+}
+
+func TestRedactor_SampleBody(t *testing.T) {
+	assert.True(t, redact.New(redact.Config{BodySampleRate: 1}).SampleBody())
+	assert.False(t, redact.New(redact.Config{BodySampleRate: 0}).SampleBody())
+}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+)
+
+// auditRepository implements audit.Repository
+// Following composition: uses BaseRepository for common functionality
+type auditRepository struct {
+	*repository.BaseRepository
+}
+
+// NewAuditRepository creates a new audit repository implementation
+// Following DIP: returns the audit interface, not concrete type
+func NewAuditRepository(pool *pgxpool.Pool) audit.Repository {
+	return &auditRepository{
+		BaseRepository: repository.NewBaseRepository(pool),
+	}
+}
+
+// CreateEvent implements audit.Repository
+func (r *auditRepository) CreateEvent(ctx context.Context, event *audit.Event) (*audit.Event, error) {
+	diff, err := json.Marshal(event.Diff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal diff: %w", err)
+	}
+
+	e, err := r.GetQueries(ctx).CreateAuditEvent(ctx, sqlc.CreateAuditEventParams{
+		Actor:      event.Actor,
+		EntityType: event.EntityType,
+		EntityID:   optionalUUID(event.EntityID),
+		Action:     string(event.Action),
+		Diff:       diff,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toEvent(e)
+}
+
+// ListEvents implements audit.Repository
+func (r *auditRepository) ListEvents(ctx context.Context, filter audit.Filter) ([]*audit.Event, error) {
+	params := sqlc.ListAuditEventsParams{
+		EntityType: optionalString(filter.EntityType),
+		EntityID:   optionalUUID(filter.EntityID),
+		Actor:      optionalString(filter.Actor),
+		Action:     optionalString(string(filter.Action)),
+		PageSize:   int32(filter.Limit),
+	}
+	if !filter.Since.IsZero() {
+		params.Since = common.TimeToPgtype(filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		params.Until = common.TimeToPgtype(filter.Until)
+	}
+	if filter.AfterID != 0 {
+		params.AfterID = &filter.AfterID
+	}
+
+	events, err := r.GetQueries(ctx).ListAuditEvents(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*audit.Event, len(events))
+	for i, e := range events {
+		event, err := toEvent(e)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = event
+	}
+
+	return result, nil
+}
+
+func toEvent(e sqlc.AuditEvent) (*audit.Event, error) {
+	diff := map[string]audit.Change{}
+	if err := json.Unmarshal(e.Diff, &diff); err != nil {
+		return nil, fmt.Errorf("invalid diff of audit event %d: %w", e.ID, err)
+	}
+
+	var entityID uuid.UUID
+	if e.EntityID.Valid {
+		entityID = e.EntityID.Bytes
+	}
+
+	return &audit.Event{
+		ID:         e.ID,
+		Actor:      e.Actor,
+		EntityType: e.EntityType,
+		EntityID:   entityID,
+		Action:     audit.Action(e.Action),
+		Diff:       diff,
+		CreatedAt:  e.CreatedAt.Time,
+	}, nil
+}
+
+func optionalUUID(id uuid.UUID) pgtype.UUID {
+	if id == uuid.Nil {
+		return pgtype.UUID{}
+	}
+	return common.UUIDToPgtype(id)
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -2,8 +2,13 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
 	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,6 +17,8 @@ import (
 type BaseRepository struct {
 	// pgxpool.Pool is safe to use from multiple goroutines simultaneously
 	pool *pgxpool.Pool
+	// replicas serve GetReaderQueries; nil reads from pool
+	replicas *Replicas
 }
 
 // NewBaseRepository creates a new base repository
@@ -19,14 +26,69 @@ func NewBaseRepository(pool *pgxpool.Pool) *BaseRepository {
 	return &BaseRepository{pool: pool}
 }
 
-// GetQueries returns sqlc queries with proper session handling
+// NewBaseRepositoryWithReplicas creates a new base repository that reads from replicas
+func NewBaseRepositoryWithReplicas(pool *pgxpool.Pool, replicas *Replicas) *BaseRepository {
+	return &BaseRepository{pool: pool, replicas: replicas}
+}
+
+// GetQueries returns sqlc queries on the primary with proper session handling.
 // Following DRY: centralizes query creation logic
 func (r *BaseRepository) GetQueries(ctx context.Context) *sqlc.Queries {
 	return GetQueriesWithSession(ctx, r.pool)
 }
 
+// GetWriterQueries returns GetQueries for writes of entities that readers may serve from replicas.
+// It starts the read-your-writes window of the client of ctx, so its next reads go to the primary.
+func (r *BaseRepository) GetWriterQueries(ctx context.Context) *sqlc.Queries {
+	r.replicas.markWrite(ctx)
+	return r.GetQueries(ctx)
+}
+
+// GetReaderQueries returns sqlc queries for readers.
+// They run in the current transaction if any, otherwise on a healthy replica unless
+// the client of ctx wrote recently or ctx was marked WithPrimary, otherwise on the primary.
+func (r *BaseRepository) GetReaderQueries(ctx context.Context) *sqlc.Queries {
+	return sqlc.New(r.GetReader(ctx))
+}
+
+// GetReader returns the session GetReaderQueries runs on, for statements sqlc can't generate
+func (r *BaseRepository) GetReader(ctx context.Context) sqlc.DBTX {
+	if _, ok := ctx.Value(_contextKeyTx).(pgx.Tx); !ok {
+		if replica := r.replicas.pool(ctx); replica != nil {
+			return replica
+		}
+	}
+
+	return GetSessionOr(ctx, r.pool)
+}
+
 // GetPool returns the connection pool
 // Following encapsulation: provides controlled access to connection pool
 func (r *BaseRepository) GetPool() *pgxpool.Pool {
 	return r.pool
 }
+
+// SaveEvents writes domain events to the outbox for the relay to publish.
+// Call it in the session of the change that raised them, i.e. inside Transaction.Do,
+// so that events are committed or rolled back together with the change.
+func (r *BaseRepository) SaveEvents(ctx context.Context, events []domain.Event) error {
+	queries := r.GetQueries(ctx)
+	for _, e := range events {
+		payload, err := json.Marshal(e.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s event payload: %w", e.Type, err)
+		}
+
+		if err := queries.InsertOutboxEvent(ctx, sqlc.InsertOutboxEventParams{
+			AggregateType: e.AggregateType,
+			AggregateID:   common.UUIDToPgtype(e.AggregateID),
+			EventType:     e.Type,
+			Payload:       payload,
+			OccurredAt:    common.TimeToPgtype(e.OccurredAt),
+		}); err != nil {
+			return fmt.Errorf("failed to save %s event: %w", e.Type, err)
+		}
+	}
+
+	return nil
+}
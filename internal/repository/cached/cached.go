@@ -0,0 +1,129 @@
+// Package cached decorates repositories with a read-through cache.
+//
+// Entities read by id outside of a transaction are served from the cache until their TTL passes.
+// Misses are read from the primary, as a lagging replica would cache an old version for the whole TTL.
+// Concurrent misses of the same entity share one read of the repository, so that an entry expiring
+// under load does not send every waiting request to the database at once.
+//
+// Writers delete the entries they changed once the transaction committed, see repository.AfterCommit,
+// so a rolled back write neither evicts nor replaces them. A read that began before the commit may still
+// cache the old version after that delete, so the entries are deleted again once such reads timed out.
+//
+// Deletes only reach the cache of the process that wrote: with cache.LRU every replica of the API keeps
+// serving the entities others changed until their TTL passes. Use cache.Redis with several replicas.
+package cached
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/cache"
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+)
+
+// defaultLoadTimeout bounds the read of a miss, which outlives the request that started it
+const defaultLoadTimeout = 5 * time.Second
+
+// readThrough caches entities of one kind by id, encoded by encode and decode
+type readThrough[T any] struct {
+	entity string
+	cache  cache.Cache
+	ttl    time.Duration
+	// loadTimeout bounds a shared read of a miss, and delays the second delete of invalidate
+	loadTimeout time.Duration
+	group       singleflight.Group
+	encode      func(*T) ([]byte, error)
+	decode      func([]byte) (*T, error)
+}
+
+// key of an entity in the cache. The version of the encoding lets deployments with different encodings share a cache.
+func (r *readThrough[T]) key(id uuid.UUID) string {
+	return r.entity + ":v1:" + id.String()
+}
+
+// get returns the entity from the cache, or loads it from the primary and caches it.
+// Within a transaction the cache is bypassed, so that fn sees its own writes and takes its locks.
+// Errors of the cache are logged and the entity is loaded instead; errors of load, such as not found, are not cached.
+func (r *readThrough[T]) get(ctx context.Context, id uuid.UUID, load func(context.Context, uuid.UUID) (*T, error)) (*T, error) {
+	if repository.InTransaction(ctx) {
+		return load(ctx, id)
+	}
+
+	key := r.key(id)
+	b, ok, err := r.cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		metrics.CacheRequestsTotal.WithLabelValues(r.entity, "error").Inc()
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("failed to read cache")
+	case ok:
+		if entity, err := r.decode(b); err == nil {
+			metrics.CacheRequestsTotal.WithLabelValues(r.entity, "hit").Inc()
+			return entity, nil
+		}
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("failed to decode cached entity")
+	default:
+		metrics.CacheRequestsTotal.WithLabelValues(r.entity, "miss").Inc()
+	}
+
+	// shared by the callers waiting for the same key, so not canceled with the request of the first one
+	results := r.group.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.loadTimeout)
+		defer cancel()
+
+		entity, err := load(repository.WithPrimary(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		b, err := r.encode(entity)
+		if err != nil {
+			return nil, err
+		}
+		// past the timeout invalidate may have deleted the key for the last time, so the entity may be old
+		if ctx.Err() != nil {
+			return b, nil
+		}
+		if err := r.cache.Set(ctx, key, b, r.ttl); err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("failed to write cache")
+		}
+		return b, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-results:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		// every caller decodes its own copy, as entities are mutable
+		return r.decode(res.Val.([]byte))
+	}
+}
+
+// invalidate deletes the entities from the cache once the transaction of ctx committed
+func (r *readThrough[T]) invalidate(ctx context.Context, ids ...uuid.UUID) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = r.key(id)
+	}
+
+	repository.AfterCommit(ctx, func(ctx context.Context) {
+		// the write took effect, so delete even when the request was canceled meanwhile
+		ctx = context.WithoutCancel(ctx)
+		r.delete(ctx, keys)
+
+		// reads of misses that began before the commit may cache the old version until they time out
+		time.AfterFunc(r.loadTimeout, func() { r.delete(ctx, keys) })
+	})
+}
+
+func (r *readThrough[T]) delete(ctx context.Context, keys []string) {
+	if err := r.cache.Delete(ctx, keys...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Strs("keys", keys).Msg("failed to invalidate cache")
+	}
+}
@@ -0,0 +1,254 @@
+package cached_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/cache"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/cached"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/memory"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/repositorytest"
+)
+
+func TestUserRepository(t *testing.T) {
+	repositorytest.TestUserRepository(t, cached.NewUserRepository(memory.NewUserRepository(memory.NewDB()), cache.NewLRU(100), time.Minute))
+}
+
+func TestItemRepository(t *testing.T) {
+	repositorytest.TestItemRepository(t, cached.NewItemRepository(memory.NewItemRepository(memory.NewDB()), cache.NewLRU(100), time.Minute), 1)
+}
+
+func TestUserRepository_Redis(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	repositorytest.TestUserRepository(t, cached.NewUserRepository(memory.NewUserRepository(memory.NewDB()), cache.NewRedis(client, "test:"), time.Minute))
+}
+
+func TestTransaction(t *testing.T) {
+	db := memory.NewDB()
+	repo := cached.NewUserRepository(memory.NewUserRepository(db), cache.NewLRU(100), time.Minute)
+	repositorytest.TestTransaction(t, memory.NewTransaction(db), repo)
+}
+
+// countingUsers counts the reads of GetUser, optionally returning them only once release is closed
+type countingUsers struct {
+	domain.UserRepository
+	gets    atomic.Int32
+	release chan struct{}
+	// primary and canceled tell whether the last read went to the primary, and whether its context was canceled
+	primary  atomic.Bool
+	canceled atomic.Bool
+}
+
+func (r *countingUsers) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	r.gets.Add(1)
+	r.primary.Store(repository.ReadsFromPrimary(ctx))
+
+	// read before waiting, like a query whose result arrives late
+	user, err := r.UserRepository.GetUser(ctx, id)
+	if r.release != nil {
+		<-r.release
+	}
+	r.canceled.Store(ctx.Err() != nil)
+
+	return user, err
+}
+
+func setup(t *testing.T) (*memory.DB, *countingUsers, domain.UserRepository, *domain.User) {
+	t.Helper()
+
+	db := memory.NewDB()
+	inner := &countingUsers{UserRepository: memory.NewUserRepository(db)}
+	repo := cached.NewUserRepository(inner, cache.NewLRU(100), time.Minute)
+
+	user, err := domain.NewUser("alice", "alice@example.com", "password")
+	require.NoError(t, err)
+	user, err = repo.CreateUser(context.Background(), user)
+	require.NoError(t, err)
+
+	return db, inner, repo, user
+}
+
+func TestGetUser_ReadThrough(t *testing.T) {
+	_, inner, repo, user := setup(t)
+	ctx := context.Background()
+
+	for range 3 {
+		got, err := repo.GetUser(ctx, user.ID())
+		require.NoError(t, err)
+		assert.Equal(t, "alice", got.Name())
+		assert.Equal(t, user.Version(), got.Version())
+	}
+	assert.Equal(t, int32(1), inner.gets.Load())
+	assert.True(t, inner.primary.Load(), "misses are read from the primary")
+
+	// not found is not cached
+	for range 2 {
+		_, err := repo.GetUser(ctx, uuid.New())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	}
+	assert.Equal(t, int32(3), inner.gets.Load())
+}
+
+func TestGetUser_SharesConcurrentMisses(t *testing.T) {
+	_, inner, repo, user := setup(t)
+	inner.release = make(chan struct{})
+
+	var wg sync.WaitGroup
+	users := make(chan *domain.User, 10)
+	for range cap(users) {
+		wg.Go(func() {
+			got, err := repo.GetUser(context.Background(), user.ID())
+			assert.NoError(t, err)
+			users <- got
+		})
+	}
+
+	require.Eventually(t, func() bool { return inner.gets.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+	close(users)
+
+	assert.Equal(t, int32(1), inner.gets.Load())
+	// every caller gets its own copy
+	seen := map[*domain.User]bool{}
+	for u := range users {
+		assert.False(t, seen[u])
+		seen[u] = true
+	}
+}
+
+func TestGetUser_OutlivesCanceledCaller(t *testing.T) {
+	_, inner, repo, user := setup(t)
+	inner.release = make(chan struct{})
+
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := repo.GetUser(first, user.ID())
+		firstErr <- err
+	}()
+	require.Eventually(t, func() bool { return inner.gets.Load() == 1 }, time.Second, time.Millisecond)
+
+	second := make(chan *domain.User)
+	go func() {
+		got, err := repo.GetUser(context.Background(), user.ID())
+		assert.NoError(t, err)
+		second <- got
+	}()
+
+	// the first caller gives up without failing the read it started for both
+	cancel()
+	require.ErrorIs(t, <-firstErr, context.Canceled)
+	close(inner.release)
+
+	got := <-second
+	require.NotNil(t, got)
+	assert.Equal(t, "alice", got.Name())
+	assert.False(t, inner.canceled.Load())
+	assert.Equal(t, int32(1), inner.gets.Load())
+}
+
+func TestUpdateUser_InvalidatesAfterCommit(t *testing.T) {
+	db, inner, repo, user := setup(t)
+	tx := memory.NewTransaction(db)
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+
+	rename := func(name string, fnErr error) error {
+		return tx.Do(ctx, func(ctx context.Context) error {
+			u, err := repo.GetUser(ctx, user.ID())
+			if err != nil {
+				return err
+			}
+			u.SetName(name)
+			if _, err := repo.UpdateUser(ctx, u); err != nil {
+				return err
+			}
+
+			// not invalidated before the commit
+			cached, err := repo.GetUser(context.Background(), user.ID())
+			require.NoError(t, err)
+			assert.Equal(t, "alice", cached.Name())
+
+			return fnErr
+		})
+	}
+
+	_, err := repo.GetUser(ctx, user.ID())
+	require.NoError(t, err)
+	gets := inner.gets.Load()
+
+	// a rolled back update keeps the entry
+	require.ErrorIs(t, rename("bob", errRollback), errRollback)
+	got, err := repo.GetUser(ctx, user.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.Name())
+	// the read in the transaction bypassed the cache
+	assert.Equal(t, gets+1, inner.gets.Load())
+
+	// a committed update evicts it
+	require.NoError(t, rename("bob", nil))
+	got, err = repo.GetUser(ctx, user.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "bob", got.Name())
+	assert.Equal(t, gets+3, inner.gets.Load())
+}
+
+func TestDeleteUser_Invalidates(t *testing.T) {
+	_, _, repo, user := setup(t)
+	ctx := context.Background()
+
+	got, err := repo.GetUser(ctx, user.ID())
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteUser(ctx, got))
+	_, err = repo.GetUser(ctx, user.ID())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = repo.RestoreUser(ctx, user.ID())
+	require.NoError(t, err)
+	_, err = repo.GetUser(ctx, user.ID())
+	assert.NoError(t, err)
+}
+
+func TestUpdateUser_DeletesAgainAfterRacingRead(t *testing.T) {
+	_, inner, repo, user := setup(t)
+	cached.SetLoadTimeout(repo, 100*time.Millisecond)
+	inner.release = make(chan struct{})
+	ctx := context.Background()
+
+	// a miss reads alice, and caches her only after the rename committed and deleted the entry
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := repo.GetUser(ctx, user.ID())
+		assert.NoError(t, err)
+	}()
+	require.Eventually(t, func() bool { return inner.gets.Load() == 1 }, time.Second, time.Millisecond)
+
+	user.SetName("bob")
+	_, err := repo.UpdateUser(ctx, user)
+	require.NoError(t, err)
+	close(inner.release)
+	<-done
+
+	require.Eventually(t, func() bool {
+		got, err := repo.GetUser(ctx, user.ID())
+		return err == nil && got.Name() == "bob"
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,13 @@
+package cached
+
+import (
+	"time"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+)
+
+// SetLoadTimeout changes how long repo, made by NewUserRepository, waits for the read of a miss
+// and delays the second delete of an invalidation
+func SetLoadTimeout(repo domain.UserRepository, d time.Duration) {
+	repo.(*userRepository).users.loadTimeout = d
+}
@@ -0,0 +1,90 @@
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/cache"
+)
+
+// itemRepository implements domain.ItemRepository, caching GetItem.
+// CreateItem and PurgeDeletedItems need no invalidation: items not found, which includes soft deleted ones, are never cached.
+type itemRepository struct {
+	domain.ItemRepository
+	items *readThrough[domain.Item]
+}
+
+// cachedItem is how an item is stored in the cache
+type cachedItem struct {
+	ID          uuid.UUID  `json:"id"`
+	TypeID      uint       `json:"type_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Version     int32      `json:"version"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// NewItemRepository caches the items read by repo in c for ttl
+// Following DIP: returns domain interface, not concrete type
+func NewItemRepository(repo domain.ItemRepository, c cache.Cache, ttl time.Duration) domain.ItemRepository {
+	return &itemRepository{
+		ItemRepository: repo,
+		items: &readThrough[domain.Item]{
+			entity:      "item",
+			cache:       c,
+			ttl:         ttl,
+			loadTimeout: defaultLoadTimeout,
+			encode: func(i *domain.Item) ([]byte, error) {
+				return json.Marshal(cachedItem{ID: i.ID(), TypeID: i.TypeID(), Name: i.Name(), Description: i.Description(), Version: i.Version(), DeletedAt: i.DeletedAt()})
+			},
+			decode: func(b []byte) (*domain.Item, error) {
+				var i cachedItem
+				if err := json.Unmarshal(b, &i); err != nil {
+					return nil, err
+				}
+				return domain.ItemFromSource(i.ID, i.TypeID, i.Name, i.Description, i.Version, i.DeletedAt), nil
+			},
+		},
+	}
+}
+
+// GetItem implements domain.ItemReader
+func (r *itemRepository) GetItem(ctx context.Context, id uuid.UUID) (*domain.Item, error) {
+	return r.items.get(ctx, id, r.ItemRepository.GetItem)
+}
+
+// UpdateItem implements domain.ItemWriter
+func (r *itemRepository) UpdateItem(ctx context.Context, item *domain.Item) (*domain.Item, error) {
+	updated, err := r.ItemRepository.UpdateItem(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	r.items.invalidate(ctx, item.ID())
+
+	return updated, nil
+}
+
+// DeleteItem implements domain.ItemWriter
+func (r *itemRepository) DeleteItem(ctx context.Context, item *domain.Item) error {
+	if err := r.ItemRepository.DeleteItem(ctx, item); err != nil {
+		return err
+	}
+	r.items.invalidate(ctx, item.ID())
+
+	return nil
+}
+
+// RestoreItem implements domain.ItemWriter
+func (r *itemRepository) RestoreItem(ctx context.Context, id uuid.UUID) (*domain.Item, error) {
+	restored, err := r.ItemRepository.RestoreItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.items.invalidate(ctx, id)
+
+	return restored, nil
+}
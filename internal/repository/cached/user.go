@@ -0,0 +1,89 @@
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/cache"
+)
+
+// userRepository implements domain.UserRepository, caching GetUser.
+// CreateUser and PurgeDeletedUsers need no invalidation: users not found, which includes soft deleted ones, are never cached.
+type userRepository struct {
+	domain.UserRepository
+	users *readThrough[domain.User]
+}
+
+// cachedUser is how a user is stored in the cache. The password hash is not read by GetUser and not cached.
+type cachedUser struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Version   int32      `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// NewUserRepository caches the users read by repo in c for ttl
+// Following DIP: returns domain interface, not concrete type
+func NewUserRepository(repo domain.UserRepository, c cache.Cache, ttl time.Duration) domain.UserRepository {
+	return &userRepository{
+		UserRepository: repo,
+		users: &readThrough[domain.User]{
+			entity:      "user",
+			cache:       c,
+			ttl:         ttl,
+			loadTimeout: defaultLoadTimeout,
+			encode: func(u *domain.User) ([]byte, error) {
+				return json.Marshal(cachedUser{ID: u.ID(), Name: u.Name(), Email: u.Email(), Version: u.Version(), DeletedAt: u.DeletedAt()})
+			},
+			decode: func(b []byte) (*domain.User, error) {
+				var u cachedUser
+				if err := json.Unmarshal(b, &u); err != nil {
+					return nil, err
+				}
+				return domain.UserFromSource(u.ID, u.Name, u.Email, u.Version, u.DeletedAt), nil
+			},
+		},
+	}
+}
+
+// GetUser implements domain.UserReader
+func (r *userRepository) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	return r.users.get(ctx, id, r.UserRepository.GetUser)
+}
+
+// UpdateUser implements domain.UserWriter
+func (r *userRepository) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	updated, err := r.UserRepository.UpdateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	r.users.invalidate(ctx, user.ID())
+
+	return updated, nil
+}
+
+// DeleteUser implements domain.UserWriter
+func (r *userRepository) DeleteUser(ctx context.Context, user *domain.User) error {
+	if err := r.UserRepository.DeleteUser(ctx, user); err != nil {
+		return err
+	}
+	r.users.invalidate(ctx, user.ID())
+
+	return nil
+}
+
+// RestoreUser implements domain.UserWriter
+func (r *userRepository) RestoreUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	restored, err := r.UserRepository.RestoreUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.users.invalidate(ctx, id)
+
+	return restored, nil
+}
@@ -2,6 +2,7 @@ package common
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -39,3 +40,32 @@ func UintToInt32Ptr(arg uint) *int32 {
 	val := int32(arg)
 	return &val
 }
+
+// Timestamp conversions
+func TimeToPgtype(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{
+		Time:  t,
+		Valid: true,
+	}
+}
+
+func PgtypeToTimePtr(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// Paginate applies limit and offset to rows for queries that don't support them
+func Paginate[T any](rows []T, limit, offset int) []T {
+	if offset >= len(rows) {
+		return []T{}
+	}
+
+	end := offset + limit
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	return rows[offset:end]
+}
@@ -0,0 +1,429 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	auditRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/audit"
+	itemRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/item"
+	outboxRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/outbox"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/repositorytest"
+	userRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/user"
+	webhookRepo "github.com/SoraDaibu/go-clean-starter/internal/repository/webhook"
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+	"github.com/SoraDaibu/go-clean-starter/migration"
+)
+
+// newPool connects to the database configured like for `serve`, e.g. through docker compose.
+// Tests are skipped without one.
+func newPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Skipf("database is not configured: %v", err)
+	}
+
+	dbURL := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+		cfg.DB.User, cfg.DB.Password, cfg.DB.Host, cfg.DB.Port, cfg.DB.Name, cfg.DB.SSLMode)
+
+	pool, err := pgxpool.New(context.Background(), dbURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	if err := pool.Ping(context.Background()); err != nil {
+		t.Skipf("database is not reachable: %v", err)
+	}
+	require.NoError(t, migration.Up(dbURL))
+
+	return pool
+}
+
+func TestUserRepository(t *testing.T) {
+	pool := newPool(t)
+	repositorytest.TestUserRepository(t, userRepo.NewUserRepository(pool, nil))
+}
+
+// newItemType returns the id of the item type named name, creating it if needed
+func newItemType(t *testing.T, pool *pgxpool.Pool, name string) uint {
+	t.Helper()
+
+	var typeID int32
+	err := pool.QueryRow(context.Background(), `
+		INSERT INTO item_types (name) VALUES ($1)
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id`, name,
+	).Scan(&typeID)
+	require.NoError(t, err)
+
+	return uint(typeID)
+}
+
+func TestItemRepository(t *testing.T) {
+	pool := newPool(t)
+	repositorytest.TestItemRepository(t, itemRepo.NewItemRepository(pool, nil), newItemType(t, pool, "repositorytest"))
+
+	t.Run("rejects a missing item type", func(t *testing.T) {
+		_, err := itemRepo.NewItemRepository(pool, nil).CreateItem(context.Background(), domain.NewItem(math.MaxInt32, "item", ""))
+
+		var invalid *domain.FieldError
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, "type_id", invalid.Field)
+	})
+}
+
+func TestItemSearcher(t *testing.T) {
+	pool := newPool(t)
+	ctx := context.Background()
+	repo := itemRepo.NewItemRepository(pool, nil)
+	searcher := itemRepo.NewItemSearcher(pool, nil)
+	typeID := newItemType(t, pool, "searchtest")
+
+	// a word unlikely to match other rows, so that results only contain the items created here
+	word := "zqxsearch" + uuid.NewString()[:8]
+	items := []*domain.Item{
+		domain.NewItem(typeID, word+" lamp", "a desk lamp"),
+		domain.NewItem(typeID, "desk", "a desk to put a "+word+" on"),
+		domain.NewItem(typeID, "chair", "unrelated"),
+	}
+	for i, item := range items {
+		created, err := repo.CreateItem(ctx, item)
+		require.NoError(t, err)
+		items[i] = created
+	}
+
+	t.Run("matches names before descriptions", func(t *testing.T) {
+		results, err := searcher.SearchItems(ctx, domain.ItemSearch{Text: word, Sort: domain.ItemSortRelevance, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, items[0].ID(), results[0].Item.ID())
+		assert.Equal(t, items[1].ID(), results[1].Item.ID())
+		assert.Greater(t, results[0].Score, results[1].Score)
+		assert.Contains(t, results[0].NameHighlight, "<mark>"+word+"</mark>")
+		assert.Contains(t, results[1].DescriptionHighlight, "<mark>"+word+"</mark>")
+	})
+
+	t.Run("tolerates typos in names", func(t *testing.T) {
+		results, err := searcher.SearchItems(ctx, domain.ItemSearch{Text: word[:len(word)-1] + " lamp", Sort: domain.ItemSortRelevance, Limit: 10})
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, items[0].ID(), results[0].Item.ID())
+	})
+
+	t.Run("pages through results", func(t *testing.T) {
+		search := domain.ItemSearch{Text: word, Sort: domain.ItemSortName, Limit: 1}
+		first, err := searcher.SearchItems(ctx, search)
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+		assert.Equal(t, items[1].ID(), first[0].Item.ID())
+
+		search.After = first[0].Key(search.Sort)
+		second, err := searcher.SearchItems(ctx, search)
+		require.NoError(t, err)
+		require.Len(t, second, 1)
+		assert.Equal(t, items[0].ID(), second[0].Item.ID())
+	})
+
+	t.Run("filters by type", func(t *testing.T) {
+		results, err := searcher.SearchItems(ctx, domain.ItemSearch{Text: word, TypeIDs: []uint{typeID + 1000}, Sort: domain.ItemSortRelevance, Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("escapes highlights", func(t *testing.T) {
+		markup := "markup" + uuid.NewString()[:8]
+		created, err := repo.CreateItem(ctx, domain.NewItem(typeID, markup+` <img src=x onerror="alert(1)">`, `<script>alert('`+markup+`')</script>`))
+		require.NoError(t, err)
+
+		results, err := searcher.SearchItems(ctx, domain.ItemSearch{Text: markup, Sort: domain.ItemSortRelevance, Limit: 10})
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, created.ID(), results[0].Item.ID())
+		assert.NotContains(t, results[0].NameHighlight, "<img")
+		assert.Contains(t, results[0].NameHighlight, "&lt;img")
+		assert.NotContains(t, results[0].DescriptionHighlight, "<script>")
+	})
+}
+
+func TestUserLister(t *testing.T) {
+	pool := newPool(t)
+	ctx := context.Background()
+	repo := userRepo.NewUserRepository(pool, nil)
+	lister := userRepo.NewUserLister(pool, nil)
+
+	// a prefix unlikely to match other rows, so that pages only contain the users created here
+	prefix := "listtest-" + uuid.NewString()[:8]
+	var ids []uuid.UUID
+	for _, name := range []string{"c", "a", "b"} {
+		user, err := domain.NewUser(prefix+"-"+name, uuid.NewString()+"@example.com", "password123")
+		require.NoError(t, err)
+		created, err := repo.CreateUser(ctx, user)
+		require.NoError(t, err)
+		ids = append(ids, created.ID())
+	}
+
+	q := domain.Query{
+		Filters: []domain.Condition{{Field: "name", Op: domain.OpPrefix, Value: prefix}},
+		Sort:    []domain.SortField{{Field: "name"}},
+		Limit:   2,
+	}
+	first, err := lister.QueryUsers(ctx, q)
+	require.NoError(t, err)
+	require.Len(t, first.Items, 2)
+	assert.Equal(t, ids[1], first.Items[0].ID())
+	assert.Equal(t, ids[2], first.Items[1].ID())
+	assert.Equal(t, []any{prefix + "-b", ids[2]}, first.Next)
+
+	q.After = first.Next
+	second, err := lister.QueryUsers(ctx, q)
+	require.NoError(t, err)
+	require.Len(t, second.Items, 1)
+	assert.Equal(t, ids[0], second.Items[0].ID())
+	assert.Nil(t, second.Next)
+}
+
+func TestTransaction(t *testing.T) {
+	pool := newPool(t)
+	repositorytest.TestTransaction(t, repository.NewTransaction(pool), userRepo.NewUserRepository(pool, nil))
+}
+
+func TestWebhookRepository(t *testing.T) {
+	pool := newPool(t)
+	ctx := context.Background()
+	repo := webhookRepo.NewWebhookRepository(pool)
+	tx := repository.NewTransaction(pool)
+
+	created, err := repo.CreateSubscription(ctx, &webhook.Subscription{
+		ID:         uuid.New(),
+		URL:        "https://partner.example.com/hooks",
+		EventTypes: []string{"user.*"},
+		Secret:     "whsec_0123456789abcdef",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.DeleteSubscription(ctx, created.ID) })
+	assert.True(t, created.Enabled)
+	assert.Equal(t, []string{"user.*"}, created.EventTypes)
+
+	t.Run("gets and updates subscriptions", func(t *testing.T) {
+		got, err := repo.GetSubscription(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created.Secret, got.Secret)
+
+		got.URL = "https://partner.example.com/v2/hooks"
+		got.EventTypes = nil
+		updated, err := repo.UpdateSubscription(ctx, got)
+		require.NoError(t, err)
+		assert.Equal(t, "https://partner.example.com/v2/hooks", updated.URL)
+		assert.Empty(t, updated.EventTypes)
+
+		_, err = repo.GetSubscription(ctx, uuid.New())
+		require.ErrorIs(t, err, domain.ErrNotFound)
+		require.ErrorIs(t, repo.DeleteSubscription(ctx, uuid.New()), domain.ErrNotFound)
+	})
+
+	t.Run("counts failures", func(t *testing.T) {
+		for want := int32(1); want <= 2; want++ {
+			s, err := repo.IncrementFailures(ctx, created.ID)
+			require.NoError(t, err)
+			assert.Equal(t, want, s.ConsecutiveFailures)
+		}
+
+		require.NoError(t, repo.ResetFailures(ctx, created.ID))
+		s, err := repo.GetSubscription(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Zero(t, s.ConsecutiveFailures)
+	})
+
+	t.Run("creates, claims and records deliveries", func(t *testing.T) {
+		delivery := &webhook.Delivery{SubscriptionID: created.ID, EventID: 1, EventType: "user.created", Payload: []byte(`{}`)}
+		require.NoError(t, repo.CreateDelivery(ctx, delivery))
+		// the same event is delivered once
+		require.NoError(t, repo.CreateDelivery(ctx, delivery))
+
+		missing := &webhook.Delivery{SubscriptionID: uuid.New(), EventID: 1, EventType: "user.created", Payload: []byte(`{}`)}
+		require.ErrorIs(t, repo.CreateDelivery(ctx, missing), webhook.ErrSubscriptionNotFound)
+
+		deliveries, err := repo.ListDeliveries(ctx, webhook.DeliveryFilter{SubscriptionID: created.ID, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, webhook.DeliveryPending, deliveries[0].Status)
+
+		require.NoError(t, tx.Do(ctx, func(ctx context.Context) error {
+			claimed, err := repo.ClaimDueDeliveries(ctx, time.Now(), 1000)
+			require.NoError(t, err)
+			for _, d := range claimed {
+				if d.ID != deliveries[0].ID {
+					continue
+				}
+				code := 204
+				d.Status, d.ResponseCode = webhook.DeliverySucceeded, &code
+				return repo.RecordAttempt(ctx, d)
+			}
+			return fmt.Errorf("delivery %d was not claimed", deliveries[0].ID)
+		}))
+
+		succeeded, err := repo.ListDeliveries(ctx, webhook.DeliveryFilter{SubscriptionID: created.ID, Status: webhook.DeliverySucceeded, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, succeeded, 1)
+		assert.Equal(t, int32(1), succeeded[0].Attempts)
+		require.NotNil(t, succeeded[0].ResponseCode)
+		assert.Equal(t, 204, *succeeded[0].ResponseCode)
+	})
+
+	t.Run("disables subscriptions", func(t *testing.T) {
+		require.NoError(t, repo.DisableSubscription(ctx, created.ID))
+
+		enabled, err := repo.ListEnabledSubscriptions(ctx)
+		require.NoError(t, err)
+		for _, s := range enabled {
+			assert.NotEqual(t, created.ID, s.ID)
+		}
+	})
+}
+
+func TestAuditRepository(t *testing.T) {
+	pool := newPool(t)
+	ctx := context.Background()
+	repo := auditRepo.NewAuditRepository(pool)
+
+	// a unique actor keeps the events of other runs out of the lists
+	actor := "test-" + uuid.NewString()
+	userID := uuid.New()
+	entries := []*audit.Event{
+		{Actor: actor, EntityType: "user", EntityID: userID, Action: audit.ActionCreate,
+			Diff: map[string]audit.Change{"email": {Before: nil, After: "alice@example.com"}}},
+		{Actor: actor, EntityType: "user", EntityID: userID, Action: audit.ActionUpdate,
+			Diff: map[string]audit.Change{"name": {Before: "alice", After: "Alice"}}},
+		{Actor: actor, EntityType: "item", Action: audit.ActionImport,
+			Diff: map[string]audit.Change{"count": {Before: nil, After: float64(3)}}},
+	}
+	var created []*audit.Event
+	for _, e := range entries {
+		event, err := repo.CreateEvent(ctx, e)
+		require.NoError(t, err)
+		assert.NotZero(t, event.ID)
+		assert.False(t, event.CreatedAt.IsZero())
+		assert.Equal(t, e.Diff, event.Diff)
+		created = append(created, event)
+	}
+
+	ids := func(events []*audit.Event) []int64 {
+		var ids []int64
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	t.Run("lists newest first", func(t *testing.T) {
+		events, err := repo.ListEvents(ctx, audit.Filter{Actor: actor, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{created[2].ID, created[1].ID, created[0].ID}, ids(events))
+
+		// events about several entities have no entity
+		assert.Equal(t, uuid.Nil, events[0].EntityID)
+		assert.Equal(t, userID, events[1].EntityID)
+		assert.Equal(t, created[1].Diff, events[1].Diff)
+	})
+
+	t.Run("pages after an id", func(t *testing.T) {
+		events, err := repo.ListEvents(ctx, audit.Filter{Actor: actor, AfterID: created[2].ID, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{created[1].ID}, ids(events))
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			filter   audit.Filter
+			expected []int64
+		}{
+			{name: "entity type", filter: audit.Filter{EntityType: "item"}, expected: []int64{created[2].ID}},
+			{name: "entity", filter: audit.Filter{EntityID: userID}, expected: []int64{created[1].ID, created[0].ID}},
+			{name: "action", filter: audit.Filter{Action: audit.ActionUpdate}, expected: []int64{created[1].ID}},
+			{name: "since", filter: audit.Filter{Since: created[0].CreatedAt.Add(-time.Minute)}, expected: []int64{created[2].ID, created[1].ID, created[0].ID}},
+			{name: "until", filter: audit.Filter{Until: created[0].CreatedAt.Add(-time.Minute)}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				filter := tt.filter
+				filter.Actor, filter.Limit = actor, 10
+				events, err := repo.ListEvents(ctx, filter)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, ids(events))
+			})
+		}
+	})
+}
+
+func TestOutboxStore(t *testing.T) {
+	pool := newPool(t)
+	ctx := context.Background()
+	store := outboxRepo.NewOutboxRepository(pool)
+	tx := repository.NewTransaction(pool)
+
+	userA, userB := uuid.New(), uuid.New()
+	events := []domain.Event{
+		{Type: "user.created", AggregateType: "user", AggregateID: userA, Payload: map[string]string{}, OccurredAt: time.Now()},
+		{Type: "user.updated", AggregateType: "user", AggregateID: userA, Payload: map[string]string{}, OccurredAt: time.Now()},
+		{Type: "user.created", AggregateType: "user", AggregateID: userB, Payload: map[string]string{}, OccurredAt: time.Now()},
+	}
+	require.NoError(t, repository.NewBaseRepository(pool).SaveEvents(ctx, events))
+
+	// claim returns the ids of the claimed events of userA and userB, ignoring those left by other tests
+	claim := func(t *testing.T) []int64 {
+		t.Helper()
+
+		var ids []int64
+		require.NoError(t, tx.Do(ctx, func(ctx context.Context) error {
+			locked, err := store.TryLock(ctx)
+			require.NoError(t, err)
+			require.True(t, locked)
+
+			messages, err := store.ClaimPending(ctx, 1000, time.Now().Add(time.Minute))
+			for _, m := range messages {
+				if m.AggregateID == userA || m.AggregateID == userB {
+					ids = append(ids, m.ID)
+				}
+			}
+			return err
+		}))
+		return ids
+	}
+
+	claimed := claim(t)
+	require.Len(t, claimed, 3)
+	a1, a2, b1 := claimed[0], claimed[1], claimed[2]
+
+	t.Run("claimed aggregates are skipped", func(t *testing.T) {
+		assert.Empty(t, claim(t))
+	})
+
+	t.Run("aggregates are claimed again once their earliest event is due", func(t *testing.T) {
+		require.NoError(t, store.RecordFailure(ctx, a1, "unavailable", time.Now().Add(-time.Second)))
+		assert.Equal(t, []int64{a1, a2}, claim(t))
+	})
+
+	t.Run("dead-lettered events let the later ones go", func(t *testing.T) {
+		require.NoError(t, store.DeadLetter(ctx, a1, "unavailable"))
+		require.NoError(t, store.RecordFailure(ctx, a2, "unavailable", time.Now().Add(-time.Second)))
+		assert.Equal(t, []int64{a2}, claim(t))
+
+		require.NoError(t, store.MarkPublished(ctx, a2))
+		require.NoError(t, store.MarkPublished(ctx, b1))
+		assert.Empty(t, claim(t))
+	})
+}
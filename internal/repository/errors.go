@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE codes translated by TranslateError
+const (
+	sqlStateUniqueViolation     = "23505"
+	sqlStateForeignKeyViolation = "23503"
+)
+
+// constraint is what a database constraint protects
+type constraint struct {
+	entity string
+	field  string
+}
+
+// constraints maps constraint names to the entity and field they protect.
+// Add the constraints of new tables here; unknown constraints are reported by table name.
+var constraints = map[string]constraint{
+	"users_pkey":             {entity: "user", field: "id"},
+	"users_email_active_key": {entity: "user", field: "email"},
+	"items_pkey":             {entity: "item", field: "id"},
+	// foreign keys name the referenced entity and the referencing field of the request
+	"items_type_id_fkey":         {entity: "item type", field: "type_id"},
+	"webhook_subscriptions_pkey": {entity: "webhook subscription", field: "id"},
+}
+
+// TranslateError translates constraint violations into domain errors: unique violations into
+// a domain.ConflictError and foreign key violations into a domain.FieldError of the referencing field,
+// as the request named an entity that does not exist. Other errors are returned as is.
+func TranslateError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	c, ok := constraints[pgErr.ConstraintName]
+
+	switch pgErr.Code {
+	case sqlStateUniqueViolation:
+		if !ok {
+			c = constraint{entity: pgErr.TableName}
+		}
+		return &domain.ConflictError{Entity: c.entity, Field: c.field}
+	case sqlStateForeignKeyViolation:
+		if !ok {
+			c = constraint{entity: "row"}
+		}
+		return &domain.FieldError{Field: c.field, Text: "does not reference an existing " + c.entity}
+	default:
+		return err
+	}
+}
+
+// IsForeignKeyViolation reports whether err is a foreign key violation,
+// for writes that translate it into an error of their own rather than a domain.FieldError.
+func IsForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == sqlStateForeignKeyViolation
+}
@@ -0,0 +1,66 @@
+package repository_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+)
+
+func TestTranslateError(t *testing.T) {
+	other := errors.New("connection reset")
+	check := &pgconn.PgError{Code: "23514", ConstraintName: "items_type_id_check"}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "duplicate email",
+			err:      &pgconn.PgError{Code: "23505", TableName: "users", ConstraintName: "users_email_active_key"},
+			expected: &domain.ConflictError{Entity: "user", Field: "email"},
+		},
+		{
+			name:     "wrapped duplicate id",
+			err:      fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505", TableName: "items", ConstraintName: "items_pkey"}),
+			expected: &domain.ConflictError{Entity: "item", Field: "id"},
+		},
+		{
+			name:     "missing referenced item type",
+			err:      &pgconn.PgError{Code: "23503", TableName: "items", ConstraintName: "items_type_id_fkey"},
+			expected: &domain.FieldError{Field: "type_id", Text: "does not reference an existing item type"},
+		},
+		{
+			name:     "unknown foreign key",
+			err:      &pgconn.PgError{Code: "23503", TableName: "audit_logs", ConstraintName: "audit_logs_user_id_fkey"},
+			expected: &domain.FieldError{Text: "does not reference an existing row"},
+		},
+		{
+			name:     "unknown constraint falls back to the table",
+			err:      &pgconn.PgError{Code: "23505", TableName: "audit_logs", ConstraintName: "audit_logs_pkey"},
+			expected: &domain.ConflictError{Entity: "audit_logs"},
+		},
+		{name: "other SQLSTATE", err: check, expected: check},
+		{name: "other error", err: other, expected: other},
+		{name: "nil", err: nil, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, repository.TranslateError(tt.err))
+		})
+	}
+}
+
+func TestIsForeignKeyViolation(t *testing.T) {
+	assert.True(t, repository.IsForeignKeyViolation(fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23503"})))
+	assert.False(t, repository.IsForeignKeyViolation(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, repository.IsForeignKeyViolation(errors.New("connection reset")))
+	assert.False(t, repository.IsForeignKeyViolation(nil))
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SetLocal returns the SET LOCAL statements of a transaction started with opts
+func SetLocal(opts ...TxOption) []string {
+	return newTxOptions(opts).setLocal()
+}
+
+// Pool returns the replica reads with ctx go to, or nil for the primary
+func (r *Replicas) Pool(ctx context.Context) *pgxpool.Pool {
+	return r.pool(ctx)
+}
+
+// MarkWrite records a write of the client of ctx, like GetWriterQueries
+func (r *Replicas) MarkWrite(ctx context.Context) {
+	r.markWrite(ctx)
+}
+
+// SetHealthy overrides the health of the i-th replica until the next health check
+func (r *Replicas) SetHealthy(i int, healthy bool) {
+	r.replicas[i].healthy.Store(healthy)
+}
+
+// SetNow replaces the clock of the stickiness windows
+func (r *Replicas) SetNow(now func() time.Time) {
+	r.now = now
+}
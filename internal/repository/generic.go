@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog"
+)
+
+// Entity is implemented by pointers to domain entities stored with Repository, e.g. *domain.User
+type Entity[E any] interface {
+	*E
+	ID() uuid.UUID
+	// Version is checked by Update and Delete for optimistic concurrency control
+	Version() int32
+	// PullEvents returns the events to save to the outbox with a write
+	PullEvents() []domain.Event
+}
+
+// Definition declares how an entity E is stored as sqlc rows R.
+// Query functions take the queries of the current session first, so that sqlc methods can be used
+// as method expressions, e.g. (*sqlc.Queries).GetUser.
+type Definition[E any, R any] struct {
+	// Name of the entity in errors and logs, e.g. "user"
+	Name string
+	// ToEntity maps a row to the entity
+	ToEntity func(row R) (*E, error)
+
+	// Get returns pgx.ErrNoRows when there is no active row with the ID
+	Get func(q *sqlc.Queries, ctx context.Context, id pgtype.UUID) (R, error)
+	// List returns the active rows in list order
+	List func(q *sqlc.Queries, ctx context.Context) ([]R, error)
+	// Create maps the entity to the create params
+	Create func(q *sqlc.Queries, ctx context.Context, entity *E) (R, error)
+	// Update returns pgx.ErrNoRows unless the row is active and still at entity.Version()
+	Update func(q *sqlc.Queries, ctx context.Context, entity *E) (R, error)
+	// Delete returns 0 rows unless the row is active and still at entity.Version()
+	Delete func(q *sqlc.Queries, ctx context.Context, entity *E) (int64, error)
+}
+
+// Repository implements domain.BaseRepository for an entity declared by a Definition.
+// Reads go through GetReaderQueries and writes through GetWriterQueries, so both join the current transaction.
+// Missing entities are reported as domain.NotFoundError and constraint violations are translated by TranslateError.
+// Following OCP: a new entity gets the common operations from a declaration without modifying this code
+type Repository[E any, PE Entity[E], R any] struct {
+	*BaseRepository
+	def Definition[E, R]
+}
+
+// NewRepository creates a new repository for the entity declared by def
+func NewRepository[E any, PE Entity[E], R any](base *BaseRepository, def Definition[E, R]) *Repository[E, PE, R] {
+	return &Repository[E, PE, R]{BaseRepository: base, def: def}
+}
+
+// Get implements domain.BaseReader
+func (r *Repository[E, PE, R]) Get(ctx context.Context, id uuid.UUID) (*E, error) {
+	row, err := r.def.Get(r.GetReaderQueries(ctx), ctx, common.UUIDToPgtype(id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.NotFound(id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", r.def.Name, err)
+	}
+
+	return r.def.ToEntity(row)
+}
+
+// List implements domain.BaseReader
+// Note: sqlc list queries don't support limit/offset, so we apply manual pagination
+func (r *Repository[E, PE, R]) List(ctx context.Context, limit, offset int) ([]*E, error) {
+	rows, err := r.def.List(r.GetReaderQueries(ctx), ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %ss: %w", r.def.Name, err)
+	}
+
+	zerolog.Ctx(ctx).Debug().Str("entity", r.def.Name).Int("total", len(rows)).Int("limit", limit).Int("offset", offset).Msg("paginating in memory")
+
+	return r.ToEntities(common.Paginate(rows, limit, offset))
+}
+
+// Create implements domain.BaseWriter
+func (r *Repository[E, PE, R]) Create(ctx context.Context, entity *E) (*E, error) {
+	row, err := r.def.Create(r.GetWriterQueries(ctx), ctx, entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", r.def.Name, TranslateError(err))
+	}
+	if err := r.SaveEvents(ctx, PE(entity).PullEvents()); err != nil {
+		return nil, err
+	}
+
+	return r.def.ToEntity(row)
+}
+
+// Update implements domain.BaseWriter
+func (r *Repository[E, PE, R]) Update(ctx context.Context, entity *E) (*E, error) {
+	row, err := r.def.Update(r.GetWriterQueries(ctx), ctx, entity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.versionConflict(ctx, PE(entity))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", r.def.Name, TranslateError(err))
+	}
+	if err := r.SaveEvents(ctx, PE(entity).PullEvents()); err != nil {
+		return nil, err
+	}
+
+	return r.def.ToEntity(row)
+}
+
+// Delete implements domain.BaseWriter
+func (r *Repository[E, PE, R]) Delete(ctx context.Context, entity *E) error {
+	deleted, err := r.def.Delete(r.GetWriterQueries(ctx), ctx, entity)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", r.def.Name, TranslateError(err))
+	}
+	if deleted == 0 {
+		return r.versionConflict(ctx, PE(entity))
+	}
+
+	return r.SaveEvents(ctx, PE(entity).PullEvents())
+}
+
+// NotFound returns the error for a missing entity
+func (r *Repository[E, PE, R]) NotFound(id uuid.UUID) error {
+	return &domain.NotFoundError{Entity: r.def.Name, Field: "id", Value: id.String()}
+}
+
+// ToEntities maps rows to entities
+func (r *Repository[E, PE, R]) ToEntities(rows []R) ([]*E, error) {
+	entities := make([]*E, len(rows))
+	for i, row := range rows {
+		entity, err := r.def.ToEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		entities[i] = entity
+	}
+
+	return entities, nil
+}
+
+// versionConflict tells apart a write that matched no row because the entity is gone
+// from one that lost against a concurrent update.
+func (r *Repository[E, PE, R]) versionConflict(ctx context.Context, entity PE) error {
+	_, err := r.def.Get(r.GetQueries(ctx), ctx, common.UUIDToPgtype(entity.ID()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.NotFound(entity.ID())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", r.def.Name, err)
+	}
+
+	return &domain.VersionConflictError{Entity: r.def.Name, ID: entity.ID(), Version: entity.Version()}
+}
@@ -0,0 +1,108 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+)
+
+// rows stands in for a table; the query functions ignore the sqlc queries
+type rows map[uuid.UUID]*domain.User
+
+func (rs rows) definition() repository.Definition[domain.User, *domain.User] {
+	return repository.Definition[domain.User, *domain.User]{
+		Name:     "user",
+		ToEntity: func(u *domain.User) (*domain.User, error) { return u, nil },
+		Get: func(_ *sqlc.Queries, _ context.Context, id pgtype.UUID) (*domain.User, error) {
+			u, ok := rs[id.Bytes]
+			if !ok {
+				return nil, pgx.ErrNoRows
+			}
+			return u, nil
+		},
+		List: func(_ *sqlc.Queries, _ context.Context) ([]*domain.User, error) {
+			var list []*domain.User
+			for _, u := range rs {
+				list = append(list, u)
+			}
+			return list, nil
+		},
+		Update: func(_ *sqlc.Queries, _ context.Context, user *domain.User) (*domain.User, error) {
+			u, ok := rs[user.ID()]
+			if !ok || u.Version() != user.Version() {
+				return nil, pgx.ErrNoRows
+			}
+			rs[user.ID()] = domain.UserFromSource(u.ID(), user.Name(), u.Email(), u.Version()+1, nil)
+			return rs[user.ID()], nil
+		},
+		Delete: func(_ *sqlc.Queries, _ context.Context, user *domain.User) (int64, error) {
+			u, ok := rs[user.ID()]
+			if !ok || u.Version() != user.Version() {
+				return 0, nil
+			}
+			delete(rs, user.ID())
+			return 1, nil
+		},
+	}
+}
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	stored := domain.UserFromSource(uuid.New(), "John", "john@example.com", 1, nil)
+	rs := rows{stored.ID(): stored}
+	repo := repository.NewRepository[domain.User, *domain.User](repository.NewBaseRepository(nil), rs.definition())
+
+	t.Run("not found", func(t *testing.T) {
+		id := uuid.New()
+		_, err := repo.Get(ctx, id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.EqualError(t, err, "user with id "+id.String()+" not found")
+
+		_, err = repo.Update(ctx, domain.UserFromSource(id, "Jane", "jane@example.com", 1, nil))
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("list paginates", func(t *testing.T) {
+		users, err := repo.List(ctx, 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+
+		users, err = repo.List(ctx, 10, 1)
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("stale writes conflict", func(t *testing.T) {
+		stale := domain.UserFromSource(stored.ID(), "Jane", stored.Email(), 2, nil)
+
+		_, err := repo.Update(ctx, stale)
+		var conflict *domain.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, "user", conflict.Entity)
+		assert.Equal(t, int32(2), conflict.Version)
+
+		assert.ErrorIs(t, repo.Delete(ctx, stale), domain.ErrVersionConflict)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		user := domain.UserFromSource(stored.ID(), "Jane", stored.Email(), 1, nil)
+
+		updated, err := repo.Update(ctx, user)
+		require.NoError(t, err)
+		assert.Equal(t, "Jane", updated.Name())
+		assert.Equal(t, int32(2), updated.Version())
+
+		require.NoError(t, repo.Delete(ctx, updated))
+		_, err = repo.Get(ctx, stored.ID())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
@@ -0,0 +1,110 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/idempotency"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+)
+
+// idempotencyRepository implements idempotency.Store
+// Following composition: uses BaseRepository for common functionality
+type idempotencyRepository struct {
+	*repository.BaseRepository
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository implementation
+func NewIdempotencyRepository(pool *pgxpool.Pool) idempotency.Store {
+	return &idempotencyRepository{
+		BaseRepository: repository.NewBaseRepository(pool),
+	}
+}
+
+// Claim implements idempotency.Store
+func (r *idempotencyRepository) Claim(ctx context.Context, key, fingerprint string, lockedUntil, expiresAt time.Time) (*idempotency.Record, bool, error) {
+	queries := r.GetQueries(ctx)
+
+	// the key may be released between the claim and the read, in which case it is claimed again
+	for {
+		k, err := queries.ClaimIdempotencyKey(ctx, sqlc.ClaimIdempotencyKeyParams{
+			Key:         key,
+			Fingerprint: fingerprint,
+			LockedUntil: pgtype.Timestamptz{Time: lockedUntil, Valid: true},
+			ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		})
+		if err == nil {
+			record, err := newRecord(k)
+			return record, true, err
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, err
+		}
+
+		k, err = queries.GetIdempotencyKey(ctx, key)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		record, err := newRecord(k)
+		return record, false, err
+	}
+}
+
+func newRecord(k sqlc.IdempotencyKey) (*idempotency.Record, error) {
+	record := &idempotency.Record{
+		Key:         k.Key,
+		Fingerprint: k.Fingerprint,
+		Body:        k.ResponseBody,
+	}
+
+	if k.StatusCode != nil {
+		record.StatusCode = int(*k.StatusCode)
+	}
+
+	if k.ResponseHeaders != nil {
+		if err := json.Unmarshal(k.ResponseHeaders, &record.Header); err != nil {
+			return nil, fmt.Errorf("invalid response headers for idempotency key %s: %w", k.Key, err)
+		}
+	}
+
+	return record, nil
+}
+
+// Save implements idempotency.Store
+func (r *idempotencyRepository) Save(ctx context.Context, key string, statusCode int, header http.Header, body []byte) error {
+	headers, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	code := int32(statusCode)
+	return r.GetQueries(ctx).SaveIdempotencyResponse(ctx, sqlc.SaveIdempotencyResponseParams{
+		Key:             key,
+		StatusCode:      &code,
+		ResponseHeaders: headers,
+		ResponseBody:    body,
+	})
+}
+
+// Release implements idempotency.Store
+func (r *idempotencyRepository) Release(ctx context.Context, key string) error {
+	return r.GetQueries(ctx).ReleaseIdempotencyKey(ctx, key)
+}
+
+// DeleteExpired implements idempotency.Store
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return r.GetQueries(ctx).DeleteExpiredIdempotencyKeys(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+}
@@ -2,7 +2,9 @@ package item
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -10,130 +12,129 @@ import (
 	"github.com/SoraDaibu/go-clean-starter/internal/repository"
 	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
 	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
 )
 
 // itemRepository implements domain.ItemRepository
 // Following DIP: depends on abstractions (domain interfaces) not concrete implementations
-// Following composition: uses BaseRepository for common functionality
+// Following composition: uses the generic Repository for common functionality
 type itemRepository struct {
-	*repository.BaseRepository
+	*repository.Repository[domain.Item, *domain.Item, sqlc.Item]
+}
+
+var _ domain.BaseRepository[domain.Item] = (*repository.Repository[domain.Item, *domain.Item, sqlc.Item])(nil)
+
+// itemDefinition declares how items are stored
+var itemDefinition = repository.Definition[domain.Item, sqlc.Item]{
+	Name:     "item",
+	ToEntity: toItem,
+	Get:      (*sqlc.Queries).GetItem,
+	List:     (*sqlc.Queries).ListItems,
+	Create: func(q *sqlc.Queries, ctx context.Context, item *domain.Item) (sqlc.Item, error) {
+		return q.CreateItem(ctx, sqlc.CreateItemParams{
+			ID:          common.UUIDToPgtype(item.ID()),
+			TypeID:      common.UintToInt32Ptr(item.TypeID()),
+			Name:        item.Name(),
+			Description: item.Description(),
+		})
+	},
+	Update: func(q *sqlc.Queries, ctx context.Context, item *domain.Item) (sqlc.Item, error) {
+		return q.UpdateItem(ctx, sqlc.UpdateItemParams{
+			ID:      common.UUIDToPgtype(item.ID()),
+			TypeID:  common.UintToInt32Ptr(item.TypeID()),
+			Version: item.Version(),
+		})
+	},
+	Delete: func(q *sqlc.Queries, ctx context.Context, item *domain.Item) (int64, error) {
+		return q.DeleteItem(ctx, sqlc.DeleteItemParams{
+			ID:      common.UUIDToPgtype(item.ID()),
+			Version: item.Version(),
+		})
+	},
 }
 
 // NewItemRepository creates a new item repository implementation
+// Readers use replicas when given; nil replicas read from the primary pool
 // Following DIP: returns domain interface, not concrete type
-func NewItemRepository(pool *pgxpool.Pool) domain.ItemRepository {
+func NewItemRepository(pool *pgxpool.Pool, replicas *repository.Replicas) domain.ItemRepository {
 	return &itemRepository{
-		BaseRepository: repository.NewBaseRepository(pool),
+		Repository: repository.NewRepository[domain.Item, *domain.Item](
+			repository.NewBaseRepositoryWithReplicas(pool, replicas),
+			itemDefinition,
+		),
 	}
 }
 
 // GetItem implements domain.ItemReader
 func (r *itemRepository) GetItem(ctx context.Context, id uuid.UUID) (*domain.Item, error) {
-	queries := r.GetQueries(ctx)
-	item, err := queries.GetItem(ctx, common.UUIDToPgtype(id))
-	if err != nil {
-		return nil, err
-	}
-
-	itemID, err := common.PgtypeToUUID(item.ID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid item ID: %w", err)
-	}
-
-	typeID, err := common.Int32PtrToUint(item.TypeID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid type_id for item %s: %w", id, err)
-	}
-
-	return domain.ItemFromSource(itemID, typeID), nil
+	return r.Get(ctx, id)
 }
 
 // ListItems implements domain.ItemReader
-// Note: The current sqlc query doesn't support limit/offset, so we apply manual pagination
 func (r *itemRepository) ListItems(ctx context.Context, limit, offset int) ([]*domain.Item, error) {
-	queries := r.GetQueries(ctx)
-	items, err := queries.ListItems(ctx)
+	return r.List(ctx, limit, offset)
+}
+
+// ListDeletedItems implements domain.ItemReader
+// Note: The current sqlc query doesn't support limit/offset, so we apply manual pagination
+func (r *itemRepository) ListDeletedItems(ctx context.Context, limit, offset int) ([]*domain.Item, error) {
+	items, err := r.GetReaderQueries(ctx).ListDeletedItems(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply manual pagination since sqlc query doesn't support it
-	start := offset
-	end := offset + limit
-	if start >= len(items) {
-		return []*domain.Item{}, nil
-	}
-	if end > len(items) {
-		end = len(items)
-	}
-
-	result := make([]*domain.Item, end-start)
-	for i, item := range items[start:end] {
-		itemID, err := common.PgtypeToUUID(item.ID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid item ID: %w", err)
-		}
-		typeID, err := common.Int32PtrToUint(item.TypeID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid type_id for item %s: %w", itemID, err)
-		}
-		result[i] = domain.ItemFromSource(itemID, typeID)
-	}
+	zerolog.Ctx(ctx).Debug().Int("total", len(items)).Int("limit", limit).Int("offset", offset).Msg("paginating deleted items in memory")
 
-	return result, nil
+	return r.ToEntities(common.Paginate(items, limit, offset))
 }
 
 // CreateItem implements domain.ItemWriter
 func (r *itemRepository) CreateItem(ctx context.Context, item *domain.Item) (*domain.Item, error) {
-	queries := r.GetQueries(ctx)
-	createdItem, err := queries.CreateItem(ctx, sqlc.CreateItemParams{
-		ID:     common.UUIDToPgtype(item.ID()),
-		TypeID: common.UintToInt32Ptr(item.TypeID()),
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	itemID, err := common.PgtypeToUUID(createdItem.ID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid created item ID: %w", err)
-	}
-
-	typeID, err := common.Int32PtrToUint(createdItem.TypeID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid type_id for created item %s: %w", itemID, err)
-	}
-
-	return domain.ItemFromSource(itemID, typeID), nil
+	return r.Create(ctx, item)
 }
 
 // UpdateItem implements domain.ItemWriter
 func (r *itemRepository) UpdateItem(ctx context.Context, item *domain.Item) (*domain.Item, error) {
-	queries := r.GetQueries(ctx)
-	updatedItem, err := queries.UpdateItem(ctx, sqlc.UpdateItemParams{
-		ID:     common.UUIDToPgtype(item.ID()),
-		TypeID: common.UintToInt32Ptr(item.TypeID()),
-	})
+	return r.Update(ctx, item)
+}
+
+// DeleteItem implements domain.ItemWriter
+func (r *itemRepository) DeleteItem(ctx context.Context, item *domain.Item) error {
+	return r.Delete(ctx, item)
+}
+
+// RestoreItem implements domain.ItemWriter
+func (r *itemRepository) RestoreItem(ctx context.Context, id uuid.UUID) (*domain.Item, error) {
+	queries := r.GetWriterQueries(ctx)
+	restoredItem, err := queries.RestoreItem(ctx, common.UUIDToPgtype(id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.NotFound(id)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	itemID, err := common.PgtypeToUUID(updatedItem.ID)
+	return toItem(restoredItem)
+}
+
+// PurgeDeletedItems implements domain.ItemWriter
+func (r *itemRepository) PurgeDeletedItems(ctx context.Context, before time.Time) (int64, error) {
+	queries := r.GetWriterQueries(ctx)
+	return queries.PurgeDeletedItems(ctx, common.TimeToPgtype(before))
+}
+
+func toItem(item sqlc.Item) (*domain.Item, error) {
+	itemID, err := common.PgtypeToUUID(item.ID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid updated item ID: %w", err)
+		return nil, fmt.Errorf("invalid item ID: %w", err)
 	}
 
-	typeID, err := common.Int32PtrToUint(updatedItem.TypeID)
+	typeID, err := common.Int32PtrToUint(item.TypeID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid type_id for updated item %s: %w", itemID, err)
+		return nil, fmt.Errorf("invalid type_id for item %s: %w", itemID, err)
 	}
 
-	return domain.ItemFromSource(itemID, typeID), nil
-}
-
-// DeleteItem implements domain.ItemWriter
-func (r *itemRepository) DeleteItem(ctx context.Context, id uuid.UUID) error {
-	queries := r.GetQueries(ctx)
-	return queries.DeleteItem(ctx, common.UUIDToPgtype(id))
+	return domain.ItemFromSource(itemID, typeID, item.Name, item.Description, item.Version, common.PgtypeToTimePtr(item.DeletedAt)), nil
 }
@@ -0,0 +1,66 @@
+package item
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+)
+
+// itemColumns are the columns of the fields of domain.ItemQuerySchema
+var itemColumns = repository.Columns{
+	"id":          "id",
+	"type_id":     "type_id",
+	"name":        "name",
+	"description": "description",
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+}
+
+// selectItems lists the columns of sqlc.Item in order, to scan rows by position
+const selectItems = `SELECT id, type_id, created_at, updated_at, version, deleted_at, name, description, search FROM items WHERE deleted_at IS NULL`
+
+// itemLister implements domain.ItemLister with SQL built from the query
+type itemLister struct {
+	*repository.BaseRepository
+}
+
+// NewItemLister creates a new item lister implementation
+// Lists use replicas when given; nil replicas read from the primary pool
+// Following DIP: returns domain interface, not concrete type
+func NewItemLister(pool *pgxpool.Pool, replicas *repository.Replicas) domain.ItemLister {
+	return &itemLister{
+		BaseRepository: repository.NewBaseRepositoryWithReplicas(pool, replicas),
+	}
+}
+
+// QueryItems implements domain.ItemLister
+func (r *itemLister) QueryItems(ctx context.Context, q domain.Query) (*domain.Page[domain.Item], error) {
+	page, err := repository.QueryPage(ctx, r.GetReader(ctx), selectItems, q, itemColumns, itemValue, toItem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+
+	return page, nil
+}
+
+// itemValue returns the value of a sortable field of item
+func itemValue(item sqlc.Item, field string) any {
+	switch field {
+	case "id":
+		return uuid.UUID(item.ID.Bytes)
+	case "name":
+		return item.Name
+	case "created_at":
+		return item.CreatedAt.Time
+	case "updated_at":
+		return item.UpdatedAt.Time
+	}
+
+	return nil
+}
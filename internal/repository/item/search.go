@@ -0,0 +1,96 @@
+package item
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+)
+
+// itemSearcher implements domain.ItemSearcher with PostgreSQL full-text search and trigram similarity
+type itemSearcher struct {
+	*repository.BaseRepository
+}
+
+// NewItemSearcher creates a new item searcher implementation
+// Searches use replicas when given; nil replicas read from the primary pool
+// Following DIP: returns domain interface, not concrete type
+func NewItemSearcher(pool *pgxpool.Pool, replicas *repository.Replicas) domain.ItemSearcher {
+	return &itemSearcher{
+		BaseRepository: repository.NewBaseRepositoryWithReplicas(pool, replicas),
+	}
+}
+
+// SearchItems implements domain.ItemSearcher
+func (r *itemSearcher) SearchItems(ctx context.Context, search domain.ItemSearch) ([]*domain.ItemSearchResult, error) {
+	params := sqlc.SearchItemsParams{
+		Q:        search.Text,
+		SortBy:   string(search.Sort),
+		PageSize: int32(search.Limit),
+	}
+	if len(search.TypeIDs) > 0 {
+		params.TypeIds = make([]int32, len(search.TypeIDs))
+		for i, id := range search.TypeIDs {
+			params.TypeIds[i] = int32(id)
+		}
+	}
+	if !search.CreatedFrom.IsZero() {
+		params.CreatedFrom = common.TimeToPgtype(search.CreatedFrom)
+	}
+	if !search.CreatedTo.IsZero() {
+		params.CreatedTo = common.TimeToPgtype(search.CreatedTo)
+	}
+	if after := search.After; after != nil {
+		params.AfterID = common.UUIDToPgtype(after.ID)
+		switch search.Sort {
+		case domain.ItemSortRelevance:
+			params.AfterScore = &after.Score
+		case domain.ItemSortNewest, domain.ItemSortOldest:
+			params.AfterCreatedAt = common.TimeToPgtype(after.CreatedAt)
+		case domain.ItemSortName:
+			params.AfterName = &after.Name
+		}
+	}
+
+	rows, err := r.GetReaderQueries(ctx).SearchItems(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*domain.ItemSearchResult, len(rows))
+	for i, row := range rows {
+		result, err := toSearchResult(row)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+
+	return results, nil
+}
+
+func toSearchResult(row sqlc.SearchItemsRow) (*domain.ItemSearchResult, error) {
+	itemID, err := common.PgtypeToUUID(row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid item ID: %w", err)
+	}
+
+	typeID, err := common.Int32PtrToUint(row.TypeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type_id for item %s: %w", itemID, err)
+	}
+
+	return &domain.ItemSearchResult{
+		// search only finds active items
+		Item:                 domain.ItemFromSource(itemID, typeID, row.Name, row.Description, row.Version, nil),
+		CreatedAt:            row.CreatedAt.Time,
+		Score:                row.Score,
+		NameHighlight:        row.NameHighlight,
+		DescriptionHighlight: row.DescriptionHighlight,
+	}, nil
+}
@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
+	"github.com/google/uuid"
+)
+
+type itemRow struct {
+	id          uuid.UUID
+	typeID      uint
+	name        string
+	description string
+	version     int32
+	seq         int64
+	deletedAt   *time.Time
+}
+
+func (i itemRow) toItem() *domain.Item {
+	return domain.ItemFromSource(i.id, i.typeID, i.name, i.description, i.version, i.deletedAt)
+}
+
+// itemRepository implements domain.ItemRepository in memory
+type itemRepository struct {
+	db *DB
+}
+
+// NewItemRepository creates a new in-memory item repository
+// Following DIP: returns domain interface, not concrete type
+func NewItemRepository(db *DB) domain.ItemRepository {
+	return &itemRepository{db: db}
+}
+
+// GetItem implements domain.ItemReader
+func (r *itemRepository) GetItem(ctx context.Context, id uuid.UUID) (*domain.Item, error) {
+	var item *domain.Item
+	r.db.read(ctx, func(s *state) {
+		if i, ok := s.items[id]; ok && i.deletedAt == nil {
+			item = i.toItem()
+		}
+	})
+	if item == nil {
+		return nil, &domain.NotFoundError{Entity: "item", Field: "id", Value: id.String()}
+	}
+
+	return item, nil
+}
+
+// ListItems implements domain.ItemReader
+func (r *itemRepository) ListItems(ctx context.Context, limit, offset int) ([]*domain.Item, error) {
+	var rows []itemRow
+	r.db.read(ctx, func(s *state) {
+		for _, i := range s.items {
+			if i.deletedAt == nil {
+				rows = append(rows, i)
+			}
+		}
+	})
+
+	// newest first, like ORDER BY created_at DESC
+	slices.SortFunc(rows, func(a, b itemRow) int { return cmp.Compare(b.seq, a.seq) })
+
+	return toItems(common.Paginate(rows, limit, offset)), nil
+}
+
+// ListDeletedItems implements domain.ItemReader
+func (r *itemRepository) ListDeletedItems(ctx context.Context, limit, offset int) ([]*domain.Item, error) {
+	var rows []itemRow
+	r.db.read(ctx, func(s *state) {
+		for _, i := range s.items {
+			if i.deletedAt != nil {
+				rows = append(rows, i)
+			}
+		}
+	})
+
+	// most recently deleted first, like ORDER BY deleted_at DESC
+	slices.SortFunc(rows, func(a, b itemRow) int {
+		return cmp.Or(b.deletedAt.Compare(*a.deletedAt), cmp.Compare(b.seq, a.seq))
+	})
+
+	return toItems(common.Paginate(rows, limit, offset)), nil
+}
+
+// CreateItem implements domain.ItemWriter
+func (r *itemRepository) CreateItem(ctx context.Context, item *domain.Item) (*domain.Item, error) {
+	row := itemRow{
+		id:          item.ID(),
+		typeID:      item.TypeID(),
+		name:        item.Name(),
+		description: item.Description(),
+		version:     1,
+		seq:         r.db.nextSeq(),
+	}
+
+	err := r.db.write(ctx, func(t *tx) error {
+		if _, ok := t.state.items[row.id]; ok {
+			return &domain.ConflictError{Entity: "item", Field: "id"}
+		}
+
+		t.state.items[row.id] = row
+		t.dirtyItems[row.id] = true
+		t.state.events = append(t.state.events, item.PullEvents()...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return row.toItem(), nil
+}
+
+// UpdateItem implements domain.ItemWriter
+func (r *itemRepository) UpdateItem(ctx context.Context, item *domain.Item) (*domain.Item, error) {
+	var updated itemRow
+	err := r.db.write(ctx, func(t *tx) error {
+		row, err := activeItem(t, item.ID(), item.Version())
+		if err != nil {
+			return err
+		}
+
+		row.typeID = item.TypeID()
+		row.version++
+		t.state.items[row.id] = row
+		t.dirtyItems[row.id] = true
+		t.state.events = append(t.state.events, item.PullEvents()...)
+		updated = row
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated.toItem(), nil
+}
+
+// DeleteItem implements domain.ItemWriter
+func (r *itemRepository) DeleteItem(ctx context.Context, item *domain.Item) error {
+	return r.db.write(ctx, func(t *tx) error {
+		row, err := activeItem(t, item.ID(), item.Version())
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		row.deletedAt = &now
+		row.version++
+		t.state.items[row.id] = row
+		t.dirtyItems[row.id] = true
+		t.state.events = append(t.state.events, item.PullEvents()...)
+		return nil
+	})
+}
+
+// RestoreItem implements domain.ItemWriter
+func (r *itemRepository) RestoreItem(ctx context.Context, id uuid.UUID) (*domain.Item, error) {
+	var restored itemRow
+	err := r.db.write(ctx, func(t *tx) error {
+		row, ok := t.state.items[id]
+		if !ok || row.deletedAt == nil {
+			return &domain.NotFoundError{Entity: "item", Field: "id", Value: id.String()}
+		}
+
+		row.deletedAt = nil
+		row.version++
+		t.state.items[id] = row
+		t.dirtyItems[id] = true
+		restored = row
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored.toItem(), nil
+}
+
+// PurgeDeletedItems implements domain.ItemWriter
+func (r *itemRepository) PurgeDeletedItems(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.write(ctx, func(t *tx) error {
+		for id, row := range t.state.items {
+			if row.deletedAt != nil && row.deletedAt.Before(before) {
+				delete(t.state.items, id)
+				t.dirtyItems[id] = true
+				purged++
+			}
+		}
+		return nil
+	})
+
+	return purged, err
+}
+
+// activeItem returns the item to write, telling apart an item that is gone
+// from one that lost against a concurrent update like the pgx repository.
+func activeItem(t *tx, id uuid.UUID, version int32) (itemRow, error) {
+	row, ok := t.state.items[id]
+	if !ok || row.deletedAt != nil {
+		return itemRow{}, &domain.NotFoundError{Entity: "item", Field: "id", Value: id.String()}
+	}
+	if row.version != version {
+		return itemRow{}, &domain.VersionConflictError{Entity: "item", ID: id, Version: version}
+	}
+
+	return row, nil
+}
+
+func toItems(rows []itemRow) []*domain.Item {
+	items := make([]*domain.Item, len(rows))
+	for i, row := range rows {
+		items[i] = row.toItem()
+	}
+
+	return items
+}
@@ -0,0 +1,214 @@
+// Package memory implements the repositories and repository.Transaction in memory,
+// for fast tests of use cases without Postgres.
+//
+// They behave like the pgx implementations, which is checked by the repositorytest conformance suite:
+// missing rows return a *domain.NotFoundError and unique violations a *domain.ConflictError.
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// DB holds the committed rows shared by the repositories and transactions created from it
+type DB struct {
+	mu    sync.Mutex
+	state state
+	// seq orders rows by creation like the created_at column
+	seq int64
+}
+
+// NewDB creates an empty DB
+func NewDB() *DB {
+	return &DB{state: state{users: map[uuid.UUID]userRow{}, items: map[uuid.UUID]itemRow{}}}
+}
+
+// Events returns the committed events, i.e. what the pgx repositories write to the outbox
+func (db *DB) Events() []domain.Event {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return append([]domain.Event(nil), db.state.events...)
+}
+
+type state struct {
+	users  map[uuid.UUID]userRow
+	items  map[uuid.UUID]itemRow
+	events []domain.Event
+}
+
+func (s *state) clone() state {
+	return state{
+		users:  maps.Clone(s.users),
+		items:  maps.Clone(s.items),
+		events: append([]domain.Event(nil), s.events...),
+	}
+}
+
+// tx works on a copy of the committed state and remembers which rows it wrote,
+// so that commit applies only those and keeps the writes of concurrent transactions.
+type tx struct {
+	db         *DB
+	state      state
+	committed  int
+	dirtyUsers map[uuid.UUID]bool
+	dirtyItems map[uuid.UUID]bool
+}
+
+func (db *DB) begin() *tx {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return &tx{
+		db:         db,
+		state:      db.state.clone(),
+		committed:  len(db.state.events),
+		dirtyUsers: map[uuid.UUID]bool{},
+		dirtyItems: map[uuid.UUID]bool{},
+	}
+}
+
+func (t *tx) clone() *tx {
+	return &tx{
+		db:         t.db,
+		state:      t.state.clone(),
+		committed:  t.committed,
+		dirtyUsers: maps.Clone(t.dirtyUsers),
+		dirtyItems: maps.Clone(t.dirtyItems),
+	}
+}
+
+func (db *DB) commit(t *tx) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	next := db.state.clone()
+	for id := range t.dirtyUsers {
+		if u, ok := t.state.users[id]; ok {
+			next.users[id] = u
+		} else {
+			delete(next.users, id)
+		}
+	}
+	for id := range t.dirtyItems {
+		if i, ok := t.state.items[id]; ok {
+			next.items[id] = i
+		} else {
+			delete(next.items, id)
+		}
+	}
+
+	// a concurrent transaction may have taken an email since this one began
+	for id := range t.dirtyUsers {
+		if u, ok := next.users[id]; ok {
+			if err := checkUniqueEmail(&next, u); err != nil {
+				return err
+			}
+		}
+	}
+
+	next.events = append(next.events, t.state.events[t.committed:]...)
+	db.state = next
+
+	return nil
+}
+
+// nextSeq returns the next creation order
+func (db *DB) nextSeq() int64 {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.seq++
+	return db.seq
+}
+
+type key struct{}
+
+func withTx(ctx context.Context, t *tx) context.Context {
+	return context.WithValue(ctx, key{}, t)
+}
+
+// txFrom returns the transaction of db in ctx, if any
+func (db *DB) txFrom(ctx context.Context) *tx {
+	if t, ok := ctx.Value(key{}).(*tx); ok && t.db == db {
+		return t
+	}
+
+	return nil
+}
+
+// read runs fn on the state seen by ctx
+func (db *DB) read(ctx context.Context, fn func(s *state)) {
+	if t := db.txFrom(ctx); t != nil {
+		fn(&t.state)
+		return
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	fn(&db.state)
+}
+
+// write runs fn in the transaction of ctx, or in its own transaction like a single statement
+func (db *DB) write(ctx context.Context, fn func(t *tx) error) error {
+	if t := db.txFrom(ctx); t != nil {
+		return fn(t)
+	}
+
+	t := db.begin()
+	if err := fn(t); err != nil {
+		return err
+	}
+
+	return db.commit(t)
+}
+
+type transaction struct {
+	db *DB
+}
+
+// NewTransaction creates a Transaction over db.
+// Transactions never conflict, but fn is retried like by the pgx implementation
+// when it returns a serialization failure, so that tests can check use cases survive retries.
+// The other options are accepted for compatibility and ignored.
+func NewTransaction(db *DB) repository.Transaction {
+	return &transaction{db: db}
+}
+
+// Do implements repository.Transaction
+func (tr *transaction) Do(ctx context.Context, fn func(ctx context.Context) error, opts ...repository.TxOption) error {
+	if outer := tr.db.txFrom(ctx); outer != nil {
+		// savepoint: keep the changes of fn only if it succeeds
+		hctx, hooks := repository.WithCommitHooks(ctx)
+		sp := outer.clone()
+		if err := fn(withTx(hctx, sp)); err != nil {
+			return err
+		}
+		*outer = *sp
+		hooks.Committed(ctx)
+		return nil
+	}
+
+	_, err := repository.Retry(ctx, opts, func() error {
+		hctx, hooks := repository.WithCommitHooks(ctx)
+		t := tr.db.begin()
+		if err := fn(withTx(hctx, t)); err != nil {
+			return err
+		}
+
+		if err := tr.db.commit(t); err != nil {
+			return err
+		}
+		hooks.Committed(ctx)
+
+		return nil
+	})
+
+	return err
+}
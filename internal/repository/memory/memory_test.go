@@ -0,0 +1,111 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/memory"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/repositorytest"
+)
+
+func TestUserRepository(t *testing.T) {
+	repositorytest.TestUserRepository(t, memory.NewUserRepository(memory.NewDB()))
+}
+
+func TestItemRepository(t *testing.T) {
+	repositorytest.TestItemRepository(t, memory.NewItemRepository(memory.NewDB()), 1)
+}
+
+func TestTransaction(t *testing.T) {
+	db := memory.NewDB()
+	repositorytest.TestTransaction(t, memory.NewTransaction(db), memory.NewUserRepository(db))
+}
+
+func TestTransaction_Retries(t *testing.T) {
+	ctx := context.Background()
+	serializationFailure := &pgconn.PgError{Code: "40001"}
+
+	// createUser creates a user in fn, failing the first failures attempts with err after the write
+	createUser := func(repo domain.UserRepository, failures int, err error) (func(ctx context.Context) error, *int) {
+		attempts := 0
+		return func(ctx context.Context) error {
+			attempts++
+			user, buildErr := domain.NewUser("John Doe", "john@example.com", "password123")
+			require.NoError(t, buildErr)
+			if _, err := repo.CreateUser(ctx, user); err != nil {
+				return err
+			}
+			if attempts <= failures {
+				return err
+			}
+			return nil
+		}, &attempts
+	}
+
+	t.Run("runs once WithoutRetry", func(t *testing.T) {
+		db := memory.NewDB()
+		fn, attempts := createUser(memory.NewUserRepository(db), 1, serializationFailure)
+
+		err := memory.NewTransaction(db).Do(ctx, fn, repository.WithoutRetry())
+		require.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 1, *attempts)
+		assert.Empty(t, db.Events())
+	})
+
+	t.Run("retries serialization failures by default", func(t *testing.T) {
+		db := memory.NewDB()
+		fn, attempts := createUser(memory.NewUserRepository(db), 2, serializationFailure)
+
+		require.NoError(t, memory.NewTransaction(db).Do(ctx, fn))
+		assert.Equal(t, 3, *attempts)
+	})
+
+	t.Run("gives up after 3 attempts by default", func(t *testing.T) {
+		db := memory.NewDB()
+		fn, attempts := createUser(memory.NewUserRepository(db), 3, serializationFailure)
+
+		require.ErrorIs(t, memory.NewTransaction(db).Do(ctx, fn), serializationFailure)
+		assert.Equal(t, 3, *attempts)
+	})
+
+	t.Run("retries serialization failures with WithMaxAttempts", func(t *testing.T) {
+		db := memory.NewDB()
+		fn, attempts := createUser(memory.NewUserRepository(db), 2, serializationFailure)
+
+		err := memory.NewTransaction(db).Do(ctx, fn, repository.WithMaxAttempts(4))
+		require.NoError(t, err)
+		assert.Equal(t, 3, *attempts)
+
+		// the failed attempts rolled back, and the aggregate built by the last one saved its events
+		events := db.Events()
+		require.Len(t, events, 1)
+		assert.Equal(t, domain.EventUserCreated, events[0].Type)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		db := memory.NewDB()
+		fn, attempts := createUser(memory.NewUserRepository(db), 5, serializationFailure)
+
+		err := memory.NewTransaction(db).Do(ctx, fn, repository.WithMaxAttempts(2))
+		require.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 2, *attempts)
+		assert.Empty(t, db.Events())
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		db := memory.NewDB()
+		errOther := errors.New("other")
+		fn, attempts := createUser(memory.NewUserRepository(db), 1, errOther)
+
+		err := memory.NewTransaction(db).Do(ctx, fn, repository.WithMaxAttempts(3))
+		require.ErrorIs(t, err, errOther)
+		assert.Equal(t, 1, *attempts)
+	})
+}
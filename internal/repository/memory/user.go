@@ -0,0 +1,260 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
+	"github.com/google/uuid"
+)
+
+type userRow struct {
+	id        uuid.UUID
+	name      string
+	email     string
+	password  domain.HashedPassword
+	version   int32
+	seq       int64
+	deletedAt *time.Time
+}
+
+func (u userRow) toUser() *domain.User {
+	return domain.UserFromSource(u.id, u.name, u.email, u.version, u.deletedAt)
+}
+
+// checkUniqueEmail enforces users_email_active_key: emails are unique among active users
+func checkUniqueEmail(s *state, u userRow) error {
+	if u.deletedAt != nil {
+		return nil
+	}
+
+	for _, other := range s.users {
+		if other.id != u.id && other.deletedAt == nil && other.email == u.email {
+			return &domain.ConflictError{Entity: "user", Field: "email"}
+		}
+	}
+
+	return nil
+}
+
+// userRepository implements domain.UserRepository in memory
+type userRepository struct {
+	db *DB
+}
+
+// NewUserRepository creates a new in-memory user repository
+// Following DIP: returns domain interface, not concrete type
+func NewUserRepository(db *DB) domain.UserRepository {
+	return &userRepository{db: db}
+}
+
+// GetUser implements domain.UserReader
+func (r *userRepository) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	var user *domain.User
+	r.db.read(ctx, func(s *state) {
+		if u, ok := s.users[id]; ok && u.deletedAt == nil {
+			user = u.toUser()
+		}
+	})
+	if user == nil {
+		return nil, &domain.NotFoundError{Entity: "user", Field: "id", Value: id.String()}
+	}
+
+	return user, nil
+}
+
+// ListUsers implements domain.UserReader
+func (r *userRepository) ListUsers(ctx context.Context, limit, offset int) ([]*domain.User, error) {
+	var rows []userRow
+	r.db.read(ctx, func(s *state) {
+		for _, u := range s.users {
+			if u.deletedAt == nil {
+				rows = append(rows, u)
+			}
+		}
+	})
+
+	// newest first, like ORDER BY created_at DESC
+	slices.SortFunc(rows, func(a, b userRow) int { return cmp.Compare(b.seq, a.seq) })
+
+	return toUsers(common.Paginate(rows, limit, offset)), nil
+}
+
+// GetUserByEmail implements domain.UserReader
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var user *domain.User
+	r.db.read(ctx, func(s *state) {
+		for _, u := range s.users {
+			if u.deletedAt == nil && u.email == email {
+				user = u.toUser()
+			}
+		}
+	})
+	if user == nil {
+		return nil, &domain.NotFoundError{Entity: "user", Field: "email", Value: email}
+	}
+
+	return user, nil
+}
+
+// ListDeletedUsers implements domain.UserReader
+func (r *userRepository) ListDeletedUsers(ctx context.Context, limit, offset int) ([]*domain.User, error) {
+	var rows []userRow
+	r.db.read(ctx, func(s *state) {
+		for _, u := range s.users {
+			if u.deletedAt != nil {
+				rows = append(rows, u)
+			}
+		}
+	})
+
+	// most recently deleted first, like ORDER BY deleted_at DESC
+	slices.SortFunc(rows, func(a, b userRow) int {
+		return cmp.Or(b.deletedAt.Compare(*a.deletedAt), cmp.Compare(b.seq, a.seq))
+	})
+
+	return toUsers(common.Paginate(rows, limit, offset)), nil
+}
+
+// CreateUser implements domain.UserWriter
+func (r *userRepository) CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	row := userRow{
+		id:       user.ID(),
+		name:     user.Name(),
+		email:    user.Email(),
+		password: user.Password(),
+		version:  1,
+		seq:      r.db.nextSeq(),
+	}
+
+	err := r.db.write(ctx, func(t *tx) error {
+		if _, ok := t.state.users[row.id]; ok {
+			return &domain.ConflictError{Entity: "user", Field: "id"}
+		}
+		if err := checkUniqueEmail(&t.state, row); err != nil {
+			return err
+		}
+
+		t.state.users[row.id] = row
+		t.dirtyUsers[row.id] = true
+		t.state.events = append(t.state.events, user.PullEvents()...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return row.toUser(), nil
+}
+
+// UpdateUser implements domain.UserWriter
+func (r *userRepository) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	var updated userRow
+	err := r.db.write(ctx, func(t *tx) error {
+		row, err := activeUser(t, user.ID(), user.Version())
+		if err != nil {
+			return err
+		}
+
+		row.name = user.Name()
+		row.version++
+		t.state.users[row.id] = row
+		t.dirtyUsers[row.id] = true
+		t.state.events = append(t.state.events, user.PullEvents()...)
+		updated = row
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated.toUser(), nil
+}
+
+// DeleteUser implements domain.UserWriter
+func (r *userRepository) DeleteUser(ctx context.Context, user *domain.User) error {
+	return r.db.write(ctx, func(t *tx) error {
+		row, err := activeUser(t, user.ID(), user.Version())
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		row.deletedAt = &now
+		row.version++
+		t.state.users[row.id] = row
+		t.dirtyUsers[row.id] = true
+		t.state.events = append(t.state.events, user.PullEvents()...)
+		return nil
+	})
+}
+
+// RestoreUser implements domain.UserWriter
+func (r *userRepository) RestoreUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	var restored userRow
+	err := r.db.write(ctx, func(t *tx) error {
+		row, ok := t.state.users[id]
+		if !ok || row.deletedAt == nil {
+			return &domain.NotFoundError{Entity: "user", Field: "id", Value: id.String()}
+		}
+
+		row.deletedAt = nil
+		row.version++
+		if err := checkUniqueEmail(&t.state, row); err != nil {
+			return err
+		}
+
+		t.state.users[id] = row
+		t.dirtyUsers[id] = true
+		restored = row
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored.toUser(), nil
+}
+
+// PurgeDeletedUsers implements domain.UserWriter
+func (r *userRepository) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.write(ctx, func(t *tx) error {
+		for id, row := range t.state.users {
+			if row.deletedAt != nil && row.deletedAt.Before(before) {
+				delete(t.state.users, id)
+				t.dirtyUsers[id] = true
+				purged++
+			}
+		}
+		return nil
+	})
+
+	return purged, err
+}
+
+// activeUser returns the user to write, telling apart a user that is gone
+// from one that lost against a concurrent update like the pgx repository.
+func activeUser(t *tx, id uuid.UUID, version int32) (userRow, error) {
+	row, ok := t.state.users[id]
+	if !ok || row.deletedAt != nil {
+		return userRow{}, &domain.NotFoundError{Entity: "user", Field: "id", Value: id.String()}
+	}
+	if row.version != version {
+		return userRow{}, &domain.VersionConflictError{Entity: "user", ID: id, Version: version}
+	}
+
+	return row, nil
+}
+
+func toUsers(rows []userRow) []*domain.User {
+	users := make([]*domain.User, len(rows))
+	for i, row := range rows {
+		users[i] = row.toUser()
+	}
+
+	return users
+}
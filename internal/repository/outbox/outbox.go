@@ -0,0 +1,97 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/outbox"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+)
+
+// relayLockKey is the advisory lock key taken by the relay holding the outbox
+const relayLockKey int64 = 0x6f7574626f78 // "outbox"
+
+// outboxRepository implements outbox.Store
+// Following composition: uses BaseRepository for common functionality
+type outboxRepository struct {
+	*repository.BaseRepository
+}
+
+// NewOutboxRepository creates a new outbox repository implementation
+// Following DIP: returns the outbox interface, not concrete type
+func NewOutboxRepository(pool *pgxpool.Pool) outbox.Store {
+	return &outboxRepository{
+		BaseRepository: repository.NewBaseRepository(pool),
+	}
+}
+
+// TryLock implements outbox.Store
+func (r *outboxRepository) TryLock(ctx context.Context) (bool, error) {
+	return r.GetQueries(ctx).TryLockOutboxRelay(ctx, relayLockKey)
+}
+
+// ClaimPending implements outbox.Store
+func (r *outboxRepository) ClaimPending(ctx context.Context, limit int, claimedUntil time.Time) ([]outbox.Message, error) {
+	rows, err := r.GetQueries(ctx).ClaimPendingOutboxEvents(ctx, sqlc.ClaimPendingOutboxEventsParams{
+		BatchSize:    int32(limit),
+		ClaimedUntil: common.TimeToPgtype(claimedUntil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// UPDATE ... RETURNING does not keep the order of the claim
+	slices.SortFunc(rows, func(a, b sqlc.Outbox) int { return cmp.Compare(a.ID, b.ID) })
+
+	messages := make([]outbox.Message, len(rows))
+	for i, row := range rows {
+		aggregateID, err := common.PgtypeToUUID(row.AggregateID)
+		if err != nil {
+			return nil, err
+		}
+
+		messages[i] = outbox.Message{
+			ID:            row.ID,
+			Type:          row.EventType,
+			AggregateType: row.AggregateType,
+			AggregateID:   aggregateID,
+			Payload:       row.Payload,
+			OccurredAt:    row.OccurredAt.Time,
+			Attempts:      row.Attempts,
+		}
+	}
+
+	return messages, nil
+}
+
+// MarkPublished implements outbox.Store
+func (r *outboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	return r.GetQueries(ctx).MarkOutboxEventPublished(ctx, id)
+}
+
+// RecordFailure implements outbox.Store
+func (r *outboxRepository) RecordFailure(ctx context.Context, id int64, reason string, nextAttemptAt time.Time) error {
+	return r.GetQueries(ctx).RecordOutboxEventFailure(ctx, sqlc.RecordOutboxEventFailureParams{
+		ID:            id,
+		LastError:     &reason,
+		NextAttemptAt: common.TimeToPgtype(nextAttemptAt),
+	})
+}
+
+// DeadLetter implements outbox.Store
+func (r *outboxRepository) DeadLetter(ctx context.Context, id int64, reason string) error {
+	return r.GetQueries(ctx).DeadLetterOutboxEvent(ctx, sqlc.DeadLetterOutboxEventParams{
+		ID:        id,
+		LastError: &reason,
+	})
+}
+
+// DeletePublished implements outbox.Store
+func (r *outboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	return r.GetQueries(ctx).DeletePublishedOutboxEvents(ctx, common.TimeToPgtype(before))
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+)
+
+// Columns maps the fields of a domain.QuerySchema to their SQL expressions, and must have "id".
+// Only these expressions end up in the SQL of a query; values are always bound as arguments.
+type Columns map[string]string
+
+// BuildQuery appends the filters, order and limit of q to base, a SELECT ending with a WHERE clause such as
+// "SELECT ... FROM users WHERE deleted_at IS NULL", and returns the statement with its arguments.
+// The sort is completed with id so that the order is total and pages continue after q.After without gaps.
+// It fetches one more row than q.Limit to tell whether there is a next page.
+func BuildQuery(base string, q domain.Query, columns Columns) (string, []any, error) {
+	b := &queryBuilder{columns: columns}
+	sort := append(q.Sort[:len(q.Sort):len(q.Sort)], domain.SortField{Field: "id"})
+
+	var sql strings.Builder
+	sql.WriteString(base)
+
+	for _, c := range q.Filters {
+		cond, err := b.condition(c)
+		if err != nil {
+			return "", nil, err
+		}
+		sql.WriteString(" AND " + cond)
+	}
+
+	if q.After != nil {
+		cond, err := b.after(sort, q.After)
+		if err != nil {
+			return "", nil, err
+		}
+		sql.WriteString(" AND " + cond)
+	}
+
+	order := make([]string, len(sort))
+	for i, s := range sort {
+		column, err := b.column(s.Field)
+		if err != nil {
+			return "", nil, err
+		}
+		order[i] = column + " ASC"
+		if s.Desc {
+			order[i] = column + " DESC"
+		}
+	}
+	sql.WriteString(" ORDER BY " + strings.Join(order, ", "))
+	sql.WriteString(" LIMIT " + b.arg(q.Limit+1))
+
+	return sql.String(), b.args, nil
+}
+
+// QueryPage runs q on db with BuildQuery, scanning rows into R by position, and converts them with to.
+// value returns the value of a sortable field, or of "id", of a row for the After of the next page.
+func QueryPage[R, T any](
+	ctx context.Context,
+	db sqlc.DBTX,
+	base string,
+	q domain.Query,
+	columns Columns,
+	value func(R, string) any,
+	to func(R) (*T, error),
+) (*domain.Page[T], error) {
+	sql, args, err := BuildQuery(base, q, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	records, err := pgx.CollectRows(rows, pgx.RowToStructByPos[R])
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.Page[T]{Items: make([]*T, 0, len(records))}
+	if len(records) > q.Limit {
+		records = records[:q.Limit]
+		last := records[len(records)-1]
+		for _, s := range q.Sort {
+			page.Next = append(page.Next, value(last, s.Field))
+		}
+		page.Next = append(page.Next, value(last, "id"))
+	}
+
+	for _, r := range records {
+		entity, err := to(r)
+		if err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, entity)
+	}
+
+	return page, nil
+}
+
+type queryBuilder struct {
+	columns Columns
+	args    []any
+}
+
+func (b *queryBuilder) column(field string) (string, error) {
+	column, ok := b.columns[field]
+	if !ok {
+		return "", fmt.Errorf("field %q has no column", field)
+	}
+
+	return column, nil
+}
+
+// arg binds v and returns its placeholder
+func (b *queryBuilder) arg(v any) string {
+	if id, ok := v.(uuid.UUID); ok {
+		v = common.UUIDToPgtype(id)
+	}
+	b.args = append(b.args, v)
+
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *queryBuilder) condition(c domain.Condition) (string, error) {
+	column, err := b.column(c.Field)
+	if err != nil {
+		return "", err
+	}
+
+	switch c.Op {
+	case domain.OpEq:
+		return column + " = " + b.arg(c.Value), nil
+	case domain.OpNe:
+		// unlike <>, also matches NULL
+		return column + " IS DISTINCT FROM " + b.arg(c.Value), nil
+	case domain.OpGt:
+		return column + " > " + b.arg(c.Value), nil
+	case domain.OpGte:
+		return column + " >= " + b.arg(c.Value), nil
+	case domain.OpLt:
+		return column + " < " + b.arg(c.Value), nil
+	case domain.OpLte:
+		return column + " <= " + b.arg(c.Value), nil
+	case domain.OpContains:
+		return column + " ILIKE " + b.arg("%"+escapeLike(c.Value)+"%"), nil
+	case domain.OpPrefix:
+		return column + " ILIKE " + b.arg(escapeLike(c.Value)+"%"), nil
+	case domain.OpIn:
+		values, ok := c.Value.([]any)
+		if !ok || len(values) == 0 {
+			return "", fmt.Errorf("%s of %q needs a non-empty list", c.Op, c.Field)
+		}
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			placeholders[i] = b.arg(v)
+		}
+		return column + " IN (" + strings.Join(placeholders, ", ") + ")", nil
+	}
+
+	return "", fmt.Errorf("unknown operator %q", c.Op)
+}
+
+// after matches the rows that come after the values of sort in after, e.g. for created_at DESC, id ASC:
+// (created_at < $1 OR (created_at = $1 AND id > $2))
+func (b *queryBuilder) after(sort []domain.SortField, after []any) (string, error) {
+	if len(after) != len(sort) {
+		return "", fmt.Errorf("after has %d values for %d sort fields", len(after), len(sort))
+	}
+
+	columns := make([]string, len(sort))
+	placeholders := make([]string, len(sort))
+	for i, s := range sort {
+		column, err := b.column(s.Field)
+		if err != nil {
+			return "", err
+		}
+		columns[i] = column
+		placeholders[i] = b.arg(after[i])
+	}
+
+	alternatives := make([]string, len(sort))
+	for i, s := range sort {
+		var terms []string
+		for j := range i {
+			terms = append(terms, columns[j]+" = "+placeholders[j])
+		}
+		cmp := " > "
+		if s.Desc {
+			cmp = " < "
+		}
+		terms = append(terms, columns[i]+cmp+placeholders[i])
+		alternatives[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", nil
+}
+
+// escapeLike makes the wildcards of a LIKE pattern match literally
+func escapeLike(v any) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(fmt.Sprint(v))
+}
@@ -0,0 +1,67 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
+)
+
+func TestBuildQuery(t *testing.T) {
+	columns := repository.Columns{"id": "id", "name": "name", "created_at": "created_at"}
+	base := "SELECT id, name FROM users WHERE deleted_at IS NULL"
+	id := uuid.New()
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("binds filter values", func(t *testing.T) {
+		sql, args, err := repository.BuildQuery(base, domain.Query{
+			Filters: []domain.Condition{
+				{Field: "name", Op: domain.OpContains, Value: `50%_off\`},
+				{Field: "name", Op: domain.OpNe, Value: "admin"},
+				{Field: "created_at", Op: domain.OpGte, Value: since},
+				{Field: "id", Op: domain.OpIn, Value: []any{id, id}},
+			},
+			Sort:  []domain.SortField{{Field: "name"}},
+			Limit: 10,
+		}, columns)
+		require.NoError(t, err)
+
+		assert.Equal(t, base+" AND name ILIKE $1 AND name IS DISTINCT FROM $2 AND created_at >= $3 AND id IN ($4, $5)"+
+			" ORDER BY name ASC, id ASC LIMIT $6", sql)
+		assert.Equal(t, []any{`%50\%\_off\\%`, "admin", since, common.UUIDToPgtype(id), common.UUIDToPgtype(id), 11}, args)
+	})
+
+	t.Run("continues after the last row", func(t *testing.T) {
+		sql, args, err := repository.BuildQuery(base, domain.Query{
+			Sort:  []domain.SortField{{Field: "created_at", Desc: true}, {Field: "name"}},
+			After: []any{since, "bob", id},
+			Limit: 2,
+		}, columns)
+		require.NoError(t, err)
+
+		assert.Equal(t, base+" AND ((created_at < $1) OR (created_at = $1 AND name > $2) OR (created_at = $1 AND name = $2 AND id > $3))"+
+			" ORDER BY created_at DESC, name ASC, id ASC LIMIT $4", sql)
+		assert.Equal(t, []any{since, "bob", common.UUIDToPgtype(id), 3}, args)
+	})
+
+	t.Run("rejects fields without a column", func(t *testing.T) {
+		_, _, err := repository.BuildQuery(base, domain.Query{
+			Filters: []domain.Condition{{Field: "password", Op: domain.OpEq, Value: "x"}},
+			Limit:   1,
+		}, columns)
+		assert.Error(t, err)
+
+		_, _, err = repository.BuildQuery(base, domain.Query{
+			Sort:  []domain.SortField{{Field: "name"}},
+			After: []any{"bob"},
+			Limit: 1,
+		}, columns)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/ratelimit"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+)
+
+const (
+	// purgeInterval is how often idle buckets are deleted
+	purgeInterval = time.Minute
+	// idleTTL is how long a bucket may stay untouched before it is deleted.
+	// It must exceed the time a bucket needs to refill completely.
+	idleTTL = time.Hour
+)
+
+// postgresStore implements ratelimit.Store with buckets shared by all replicas
+// Following composition: uses BaseRepository for common functionality
+type postgresStore struct {
+	*repository.BaseRepository
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+// NewPostgresStore creates a new rate limit store backed by the rate_limit_buckets table
+func NewPostgresStore(pool *pgxpool.Pool) ratelimit.Store {
+	return &postgresStore{
+		BaseRepository: repository.NewBaseRepository(pool),
+	}
+}
+
+// Take implements ratelimit.Store
+func (s *postgresStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	row, err := s.GetQueries(ctx).TakeRateLimitToken(ctx, sqlc.TakeRateLimitTokenParams{
+		Key:   key,
+		Burst: float64(limit.Burst),
+		Rate:  limit.Rate,
+	})
+	// a bucket another request created after this statement started is locked by the upsert
+	// but not visible to the read of the refilled tokens: it was just taken from, so it is empty
+	if errors.Is(err, pgx.ErrNoRows) {
+		row = sqlc.TakeRateLimitTokenRow{Allowed: false, Tokens: 0}
+	} else if err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	s.purge(ctx)
+
+	return ratelimit.NewResult(row.Allowed, row.Tokens, limit), nil
+}
+
+func (s *postgresStore) purge(ctx context.Context) {
+	s.mu.Lock()
+	now := time.Now()
+	if now.Sub(s.lastPurge) < purgeInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPurge = now
+	s.mu.Unlock()
+
+	before := pgtype.Timestamptz{Time: now.Add(-idleTTL), Valid: true}
+	if err := s.GetQueries(ctx).DeleteRateLimitBucketsBefore(ctx, before); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("failed to purge idle rate limit buckets")
+	}
+}
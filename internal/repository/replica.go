@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// ReplicaConfig configures how reads are routed to replicas
+type ReplicaConfig struct {
+	// Stickiness sends reads of a client to the primary for this long after its writes committed
+	Stickiness time.Duration
+	// HealthCheckInterval is how often replicas are pinged
+	HealthCheckInterval time.Duration
+	// MaxLag takes a replica out of rotation while it is further behind the primary. 0 disables the check.
+	MaxLag time.Duration
+}
+
+// Replicas routes reads to healthy read replicas, round robin.
+// A nil *Replicas routes everything to the primary.
+type Replicas struct {
+	replicas []*replica
+	next     atomic.Uint64
+	cfg      ReplicaConfig
+	now      func() time.Time
+
+	mu sync.Mutex
+	// lastWrites holds when each client last wrote, for read-your-writes
+	lastWrites map[string]time.Time
+	lastPrune  time.Time
+
+	started atomic.Bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type replica struct {
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// NewReplicas creates Replicas over pools, all of which are considered healthy until the first health check.
+// Call Run to check their health and Close to close them.
+func NewReplicas(pools []*pgxpool.Pool, cfg ReplicaConfig) *Replicas {
+	r := &Replicas{
+		cfg:        cfg,
+		now:        time.Now,
+		lastWrites: map[string]time.Time{},
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	for _, pool := range pools {
+		rep := &replica{pool: pool}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+
+	return r
+}
+
+// Run checks the health of the replicas every HealthCheckInterval until Close is called.
+func (r *Replicas) Run() {
+	r.started.Store(true)
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		r.checkHealth()
+
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close stops the health checks and closes the replica pools
+func (r *Replicas) Close() {
+	if r == nil {
+		return
+	}
+
+	close(r.stop)
+	if r.started.Load() {
+		<-r.done
+	}
+	for _, rep := range r.replicas {
+		rep.pool.Close()
+	}
+}
+
+// ReplicaStat is the state of a replica
+type ReplicaStat struct {
+	Healthy bool
+	Pool    *pgxpool.Stat
+}
+
+// Stats returns the state of the replicas in configuration order. It returns nil on a nil *Replicas.
+func (r *Replicas) Stats() []ReplicaStat {
+	if r == nil {
+		return nil
+	}
+
+	stats := make([]ReplicaStat, 0, len(r.replicas))
+	for _, rep := range r.replicas {
+		stats = append(stats, ReplicaStat{Healthy: rep.healthy.Load(), Pool: rep.pool.Stat()})
+	}
+
+	return stats
+}
+
+func (r *Replicas) checkHealth() {
+	for i, rep := range r.replicas {
+		healthy := r.healthy(rep.pool)
+		if rep.healthy.Swap(healthy) != healthy {
+			log.Warn().Int("replica", i).Bool("healthy", healthy).Msg("read replica health changed")
+		}
+	}
+}
+
+func (r *Replicas) healthy(pool *pgxpool.Pool) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.HealthCheckInterval)
+	defer cancel()
+
+	if r.cfg.MaxLag <= 0 {
+		return pool.Ping(ctx) == nil
+	}
+
+	// The time since the last replayed transaction only measures lag while WAL is waiting to be replayed:
+	// a replica that replayed all it received is up to date, however long ago the primary last wrote.
+	// NULL on a replica that has not replayed anything yet, i.e. there is nothing to lag behind.
+	var lag *float64
+	err := pool.QueryRow(ctx, `SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END::float8`).Scan(&lag)
+	if err != nil {
+		return false
+	}
+
+	return lag == nil || time.Duration(*lag*float64(time.Second)) <= r.cfg.MaxLag
+}
+
+// pool returns the replica to read from for ctx, or nil when reads must go to the primary
+func (r *Replicas) pool(ctx context.Context) *pgxpool.Pool {
+	if r == nil || len(r.replicas) == 0 || ReadsFromPrimary(ctx) || r.sticky(ctx) {
+		return nil
+	}
+
+	n := r.next.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(n+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep.pool
+		}
+	}
+
+	return nil
+}
+
+// markWrite starts the stickiness window of the client of ctx once the write is committed,
+// so that a long transaction does not use up the window before its writes can be read
+func (r *Replicas) markWrite(ctx context.Context) {
+	client, ok := ctx.Value(_contextKeyClient).(string)
+	if r == nil || !ok || r.cfg.Stickiness <= 0 {
+		return
+	}
+
+	AfterCommit(ctx, func(context.Context) { r.stick(client) })
+}
+
+// stick sends the reads of client to the primary for the stickiness window from now
+func (r *Replicas) stick(client string) {
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastWrites[client] = now
+
+	// drop expired windows once per window so that the map does not grow with every client ever seen
+	if now.Sub(r.lastPrune) > r.cfg.Stickiness {
+		for k, at := range r.lastWrites {
+			if now.Sub(at) > r.cfg.Stickiness {
+				delete(r.lastWrites, k)
+			}
+		}
+		r.lastPrune = now
+	}
+}
+
+func (r *Replicas) sticky(ctx context.Context) bool {
+	client, ok := ctx.Value(_contextKeyClient).(string)
+	if !ok || r.cfg.Stickiness <= 0 {
+		return false
+	}
+
+	r.mu.Lock()
+	at, ok := r.lastWrites[client]
+	r.mu.Unlock()
+
+	return ok && r.now().Sub(at) <= r.cfg.Stickiness
+}
+
+var (
+	_contextKeyPrimary = &key{"_contextKeyPrimary"}
+	_contextKeyClient  = &key{"_contextKeyClient"}
+)
+
+// WithPrimary makes reads with ctx go to the primary, e.g. to read a row right after writing it
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, _contextKeyPrimary, true)
+}
+
+// ReadsFromPrimary reports whether reads with ctx go to the primary because of WithPrimary
+func ReadsFromPrimary(ctx context.Context) bool {
+	return ctx.Value(_contextKeyPrimary) != nil
+}
+
+// WithClient identifies who is reading and writing with ctx, e.g. a user or an IP address.
+// Reads of a client go to the primary for a while after it wrote.
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, _contextKeyClient, client)
+}
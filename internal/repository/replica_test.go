@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+)
+
+// newReplicas creates Replicas over pools that connect lazily, so no database is needed until they are queried
+func newReplicas(t *testing.T, n int, stickiness time.Duration) (*repository.Replicas, []*pgxpool.Pool) {
+	t.Helper()
+
+	pools := make([]*pgxpool.Pool, n)
+	for i := range pools {
+		pool, err := pgxpool.New(context.Background(), "postgres://replica:5432/app")
+		require.NoError(t, err)
+		pools[i] = pool
+	}
+
+	replicas := repository.NewReplicas(pools, repository.ReplicaConfig{Stickiness: stickiness, HealthCheckInterval: time.Minute})
+	t.Cleanup(replicas.Close)
+
+	return replicas, pools
+}
+
+func TestReplicas_Routing(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round robin over replicas", func(t *testing.T) {
+		replicas, pools := newReplicas(t, 2, 0)
+
+		first, second := replicas.Pool(ctx), replicas.Pool(ctx)
+		assert.ElementsMatch(t, pools, []*pgxpool.Pool{first, second})
+		assert.Same(t, first, replicas.Pool(ctx))
+	})
+
+	t.Run("primary with WithPrimary", func(t *testing.T) {
+		replicas, _ := newReplicas(t, 2, 0)
+
+		assert.Nil(t, replicas.Pool(repository.WithPrimary(ctx)))
+	})
+
+	t.Run("skips unhealthy replicas", func(t *testing.T) {
+		replicas, pools := newReplicas(t, 2, 0)
+		replicas.SetHealthy(0, false)
+
+		for range 3 {
+			assert.Same(t, pools[1], replicas.Pool(ctx))
+		}
+
+		// the primary serves reads while no replica is healthy
+		replicas.SetHealthy(1, false)
+		assert.Nil(t, replicas.Pool(ctx))
+
+		replicas.SetHealthy(0, true)
+		assert.Same(t, pools[0], replicas.Pool(ctx))
+	})
+
+	t.Run("primary without replicas", func(t *testing.T) {
+		var replicas *repository.Replicas
+		assert.Nil(t, replicas.Pool(ctx))
+	})
+}
+
+func TestReplicas_Stickiness(t *testing.T) {
+	now := time.Now()
+	replicas, _ := newReplicas(t, 1, time.Second)
+	replicas.SetNow(func() time.Time { return now })
+	alice := repository.WithClient(context.Background(), "alice")
+	bob := repository.WithClient(context.Background(), "bob")
+
+	t.Run("reads of a client go to the primary for a while after it wrote", func(t *testing.T) {
+		replicas.MarkWrite(alice)
+		assert.Nil(t, replicas.Pool(alice))
+		assert.NotNil(t, replicas.Pool(bob), "other clients are not affected")
+
+		now = now.Add(time.Second)
+		assert.Nil(t, replicas.Pool(alice))
+
+		now = now.Add(time.Millisecond)
+		assert.NotNil(t, replicas.Pool(alice))
+	})
+
+	t.Run("the window starts when the transaction commits", func(t *testing.T) {
+		tctx, hooks := repository.WithCommitHooks(bob)
+		replicas.MarkWrite(tctx)
+		assert.NotNil(t, replicas.Pool(bob), "the write is not visible before the commit")
+
+		// a transaction longer than the window still sends the reads that follow it to the primary
+		now = now.Add(2 * time.Second)
+		hooks.Committed(bob)
+		assert.Nil(t, replicas.Pool(bob))
+
+		now = now.Add(time.Second + time.Millisecond)
+		assert.NotNil(t, replicas.Pool(bob))
+	})
+
+	t.Run("writes without a client are not sticky", func(t *testing.T) {
+		replicas.MarkWrite(context.Background())
+		assert.NotNil(t, replicas.Pool(context.Background()))
+	})
+}
+
+func TestBaseRepository_Stickiness(t *testing.T) {
+	replicas, _ := newReplicas(t, 1, time.Second)
+	primary, err := pgxpool.New(context.Background(), "postgres://primary:5432/app")
+	require.NoError(t, err)
+	t.Cleanup(primary.Close)
+	base := repository.NewBaseRepositoryWithReplicas(primary, replicas)
+	alice := repository.WithClient(context.Background(), "alice")
+
+	// reads and writes of other tables on the primary, such as rate limit buckets, leave reads on replicas
+	base.GetQueries(alice)
+	assert.NotNil(t, replicas.Pool(alice))
+
+	base.GetWriterQueries(alice)
+	assert.Nil(t, replicas.Pool(alice))
+}
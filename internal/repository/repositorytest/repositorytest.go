@@ -0,0 +1,485 @@
+// Package repositorytest is a conformance suite for implementations of the domain repositories
+// and repository.Transaction, so that the pgx and in-memory implementations behave the same.
+//
+// The suites only look at rows they create, so they can run against a shared database.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+)
+
+// TestUserRepository checks the behavior of a domain.UserRepository
+func TestUserRepository(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+
+	t.Run("create and get", func(t *testing.T) {
+		user := createUser(t, repo)
+
+		got, err := repo.GetUser(ctx, user.ID())
+		require.NoError(t, err)
+		assert.Equal(t, user.ID(), got.ID())
+		assert.Equal(t, user.Name(), got.Name())
+		assert.Equal(t, user.Email(), got.Email())
+		assert.Equal(t, int32(1), got.Version())
+		assert.Nil(t, got.DeletedAt())
+
+		got, err = repo.GetUserByEmail(ctx, user.Email())
+		require.NoError(t, err)
+		assert.Equal(t, user.ID(), got.ID())
+	})
+
+	t.Run("get missing user", func(t *testing.T) {
+		_, err := repo.GetUser(ctx, uuid.New())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = repo.GetUserByEmail(ctx, uniqueEmail())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("emails are unique among active users", func(t *testing.T) {
+		user := createUser(t, repo)
+
+		duplicate := newUser(t, user.Email())
+		_, err := repo.CreateUser(ctx, duplicate)
+		assertEmailConflict(t, err)
+
+		require.NoError(t, repo.DeleteUser(ctx, user))
+		_, err = repo.CreateUser(ctx, duplicate)
+		require.NoError(t, err)
+
+		_, err = repo.RestoreUser(ctx, user.ID())
+		assertEmailConflict(t, err)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		user := createUser(t, repo)
+
+		user.SetName("Updated")
+		updated, err := repo.UpdateUser(ctx, user)
+		require.NoError(t, err)
+		assert.Equal(t, "Updated", updated.Name())
+		assert.Equal(t, int32(2), updated.Version())
+
+		// user is still at version 1
+		_, err = repo.UpdateUser(ctx, user)
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+		_, err = repo.UpdateUser(ctx, domain.UserFromSource(uuid.New(), "Missing", uniqueEmail(), 1, nil))
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("delete and restore", func(t *testing.T) {
+		user := createUser(t, repo)
+
+		stale := domain.UserFromSource(user.ID(), user.Name(), user.Email(), 2, nil)
+		assert.ErrorIs(t, repo.DeleteUser(ctx, stale), domain.ErrVersionConflict)
+
+		require.NoError(t, repo.DeleteUser(ctx, user))
+		_, err := repo.GetUser(ctx, user.ID())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.ErrorIs(t, repo.DeleteUser(ctx, user), domain.ErrNotFound)
+
+		deleted, err := repo.ListDeletedUsers(ctx, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, deleted, 1)
+		assert.Equal(t, user.ID(), deleted[0].ID())
+		assert.NotNil(t, deleted[0].DeletedAt())
+
+		restored, err := repo.RestoreUser(ctx, user.ID())
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt())
+		assert.Equal(t, int32(3), restored.Version())
+
+		_, err = repo.RestoreUser(ctx, user.ID())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("list newest first", func(t *testing.T) {
+		first := createUser(t, repo)
+		second := createUser(t, repo)
+
+		users, err := repo.ListUsers(ctx, 2, 0)
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.Equal(t, second.ID(), users[0].ID())
+		assert.Equal(t, first.ID(), users[1].ID())
+
+		users, err = repo.ListUsers(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, first.ID(), users[0].ID())
+	})
+
+	t.Run("deleted users are hidden until restored", func(t *testing.T) {
+		kept := createUser(t, repo)
+		user := createUser(t, repo)
+		require.NoError(t, repo.DeleteUser(ctx, user))
+
+		_, err := repo.GetUserByEmail(ctx, user.Email())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		users, err := repo.ListUsers(ctx, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, kept.ID(), users[0].ID())
+
+		_, err = repo.RestoreUser(ctx, user.ID())
+		require.NoError(t, err)
+
+		got, err := repo.GetUser(ctx, user.ID())
+		require.NoError(t, err)
+		assert.Nil(t, got.DeletedAt())
+		_, err = repo.GetUserByEmail(ctx, user.Email())
+		require.NoError(t, err)
+		users, err = repo.ListUsers(ctx, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, user.ID(), users[0].ID())
+
+		deleted, err := repo.ListDeletedUsers(ctx, 1, 0)
+		require.NoError(t, err)
+		for _, d := range deleted {
+			assert.NotEqual(t, user.ID(), d.ID())
+		}
+	})
+
+	t.Run("purge deleted users", func(t *testing.T) {
+		user := createUser(t, repo)
+		require.NoError(t, repo.DeleteUser(ctx, user))
+
+		purged, err := repo.PurgeDeletedUsers(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, int64(1))
+
+		_, err = repo.RestoreUser(ctx, user.ID())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("purge keeps users deleted within the retention and active users", func(t *testing.T) {
+		active := createUser(t, repo)
+		user := createUser(t, repo)
+		require.NoError(t, repo.DeleteUser(ctx, user))
+
+		// the user was deleted after the cutoff
+		_, err := repo.PurgeDeletedUsers(ctx, time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		_, err = repo.RestoreUser(ctx, user.ID())
+		require.NoError(t, err)
+
+		_, err = repo.PurgeDeletedUsers(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		_, err = repo.GetUser(ctx, active.ID())
+		require.NoError(t, err)
+		_, err = repo.GetUser(ctx, user.ID())
+		require.NoError(t, err)
+	})
+}
+
+// TestItemRepository checks the behavior of a domain.ItemRepository.
+// typeID must be an existing item type.
+func TestItemRepository(t *testing.T, repo domain.ItemRepository, typeID uint) {
+	ctx := context.Background()
+
+	createItem := func(t *testing.T) *domain.Item {
+		t.Helper()
+
+		item, err := repo.CreateItem(ctx, domain.NewItem(typeID, "item", "an item"))
+		require.NoError(t, err)
+		return item
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		item := createItem(t)
+
+		got, err := repo.GetItem(ctx, item.ID())
+		require.NoError(t, err)
+		assert.Equal(t, item.ID(), got.ID())
+		assert.Equal(t, typeID, got.TypeID())
+		assert.Equal(t, "item", got.Name())
+		assert.Equal(t, "an item", got.Description())
+		assert.Equal(t, int32(1), got.Version())
+		assert.Nil(t, got.DeletedAt())
+	})
+
+	t.Run("get missing item", func(t *testing.T) {
+		_, err := repo.GetItem(ctx, uuid.New())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		item := createItem(t)
+
+		updated, err := repo.UpdateItem(ctx, item)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), updated.Version())
+
+		_, err = repo.UpdateItem(ctx, item)
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+		_, err = repo.UpdateItem(ctx, domain.ItemFromSource(uuid.New(), typeID, "item", "", 1, nil))
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("delete and restore", func(t *testing.T) {
+		item := createItem(t)
+
+		require.NoError(t, repo.DeleteItem(ctx, item))
+		_, err := repo.GetItem(ctx, item.ID())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		deleted, err := repo.ListDeletedItems(ctx, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, deleted, 1)
+		assert.Equal(t, item.ID(), deleted[0].ID())
+
+		restored, err := repo.RestoreItem(ctx, item.ID())
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt())
+		assert.Equal(t, int32(3), restored.Version())
+	})
+
+	t.Run("list newest first", func(t *testing.T) {
+		first := createItem(t)
+		second := createItem(t)
+
+		items, err := repo.ListItems(ctx, 2, 0)
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, second.ID(), items[0].ID())
+		assert.Equal(t, first.ID(), items[1].ID())
+	})
+
+	t.Run("deleted items are hidden until restored", func(t *testing.T) {
+		kept := createItem(t)
+		item := createItem(t)
+		require.NoError(t, repo.DeleteItem(ctx, item))
+
+		items, err := repo.ListItems(ctx, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, kept.ID(), items[0].ID())
+
+		_, err = repo.RestoreItem(ctx, item.ID())
+		require.NoError(t, err)
+
+		got, err := repo.GetItem(ctx, item.ID())
+		require.NoError(t, err)
+		assert.Nil(t, got.DeletedAt())
+		items, err = repo.ListItems(ctx, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, item.ID(), items[0].ID())
+
+		_, err = repo.RestoreItem(ctx, item.ID())
+		assert.ErrorIs(t, err, domain.ErrNotFound, "active items cannot be restored")
+	})
+
+	t.Run("purge deleted items", func(t *testing.T) {
+		item := createItem(t)
+		require.NoError(t, repo.DeleteItem(ctx, item))
+
+		purged, err := repo.PurgeDeletedItems(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, int64(1))
+
+		_, err = repo.RestoreItem(ctx, item.ID())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("purge keeps items deleted within the retention and active items", func(t *testing.T) {
+		active := createItem(t)
+		item := createItem(t)
+		require.NoError(t, repo.DeleteItem(ctx, item))
+
+		// the item was deleted after the cutoff
+		_, err := repo.PurgeDeletedItems(ctx, time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		_, err = repo.RestoreItem(ctx, item.ID())
+		require.NoError(t, err)
+
+		_, err = repo.PurgeDeletedItems(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		_, err = repo.GetItem(ctx, active.ID())
+		require.NoError(t, err)
+		_, err = repo.GetItem(ctx, item.ID())
+		require.NoError(t, err)
+	})
+}
+
+// TestTransaction checks the behavior of a repository.Transaction with the user repository of the same database
+func TestTransaction(t *testing.T, tx repository.Transaction, repo domain.UserRepository) {
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+
+	t.Run("commit", func(t *testing.T) {
+		user := newUser(t, uniqueEmail())
+
+		err := tx.Do(ctx, func(ctx context.Context) error {
+			_, err := repo.CreateUser(ctx, user)
+			return err
+		})
+		require.NoError(t, err)
+
+		_, err = repo.GetUser(ctx, user.ID())
+		assert.NoError(t, err)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		user := newUser(t, uniqueEmail())
+
+		err := tx.Do(ctx, func(ctx context.Context) error {
+			if _, err := repo.CreateUser(ctx, user); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+
+		_, err = repo.GetUser(ctx, user.ID())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("isolation", func(t *testing.T) {
+		user := newUser(t, uniqueEmail())
+
+		err := tx.Do(ctx, func(txCtx context.Context) error {
+			if _, err := repo.CreateUser(txCtx, user); err != nil {
+				return err
+			}
+
+			// visible in the transaction, not outside until committed
+			if _, err := repo.GetUser(txCtx, user.ID()); err != nil {
+				return err
+			}
+			_, err := repo.GetUser(ctx, user.ID())
+			assert.ErrorIs(t, err, domain.ErrNotFound)
+
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+	})
+
+	t.Run("nested transactions use savepoints", func(t *testing.T) {
+		outer := newUser(t, uniqueEmail())
+		inner := newUser(t, uniqueEmail())
+
+		err := tx.Do(ctx, func(ctx context.Context) error {
+			if _, err := repo.CreateUser(ctx, outer); err != nil {
+				return err
+			}
+
+			err := tx.Do(ctx, func(ctx context.Context) error {
+				if _, err := repo.CreateUser(ctx, inner); err != nil {
+					return err
+				}
+				return errRollback
+			})
+			assert.ErrorIs(t, err, errRollback)
+
+			// the outer transaction is still usable after the savepoint rolled back
+			_, err = repo.GetUser(ctx, outer.ID())
+			return err
+		})
+		require.NoError(t, err)
+
+		_, err = repo.GetUser(ctx, outer.ID())
+		assert.NoError(t, err)
+		_, err = repo.GetUser(ctx, inner.ID())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("savepoint recovers from a unique violation", func(t *testing.T) {
+		existing := newUser(t, uniqueEmail())
+		_, err := repo.CreateUser(ctx, existing)
+		require.NoError(t, err)
+
+		user := newUser(t, uniqueEmail())
+		err = tx.Do(ctx, func(ctx context.Context) error {
+			err := tx.Do(ctx, func(ctx context.Context) error {
+				_, err := repo.CreateUser(ctx, newUser(t, existing.Email()))
+				return err
+			})
+			assertEmailConflict(t, err)
+
+			_, err = repo.CreateUser(ctx, user)
+			return err
+		})
+		require.NoError(t, err)
+
+		_, err = repo.GetUser(ctx, user.ID())
+		assert.NoError(t, err)
+	})
+
+	t.Run("after commit hooks run once the outermost transaction committed", func(t *testing.T) {
+		var ran []string
+		hook := func(name string) func(context.Context) {
+			return func(context.Context) { ran = append(ran, name) }
+		}
+
+		err := tx.Do(ctx, func(ctx context.Context) error {
+			repository.AfterCommit(ctx, hook("outer"))
+
+			require.NoError(t, tx.Do(ctx, func(ctx context.Context) error {
+				repository.AfterCommit(ctx, hook("savepoint"))
+				return nil
+			}))
+			assert.ErrorIs(t, tx.Do(ctx, func(ctx context.Context) error {
+				repository.AfterCommit(ctx, hook("rolled back savepoint"))
+				return errRollback
+			}), errRollback)
+
+			assert.Empty(t, ran, "hooks wait for the commit")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"outer", "savepoint"}, ran)
+
+		ran = nil
+		err = tx.Do(ctx, func(ctx context.Context) error {
+			repository.AfterCommit(ctx, hook("rolled back"))
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+		assert.Empty(t, ran)
+	})
+}
+
+func newUser(t *testing.T, email string) *domain.User {
+	t.Helper()
+
+	user, err := domain.NewUser("John Doe", email, "password123")
+	require.NoError(t, err)
+	return user
+}
+
+func createUser(t *testing.T, repo domain.UserRepository) *domain.User {
+	t.Helper()
+
+	user, err := repo.CreateUser(context.Background(), newUser(t, uniqueEmail()))
+	require.NoError(t, err)
+	return user
+}
+
+func uniqueEmail() string {
+	return fmt.Sprintf("repositorytest-%s@example.com", uuid.New())
+}
+
+func assertEmailConflict(t *testing.T, err error) {
+	t.Helper()
+
+	var conflict *domain.ConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, "user", conflict.Entity)
+		assert.Equal(t, "email", conflict.Field)
+	}
+}
@@ -2,10 +2,21 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
 
+	"github.com/SoraDaibu/go-clean-starter/internal/backoff"
 	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+	"github.com/SoraDaibu/go-clean-starter/internal/tracing"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // Transaction represents an interface for grouping business procedures.
@@ -14,7 +25,111 @@ import (
 // However, transactions at the Usecase layer are business knowledge,
 // and are not necessarily used only with RDBMS repositories.
 type Transaction interface {
-	Do(ctx context.Context, fn func(ctx context.Context) error) error
+	// Do runs fn in a transaction that is committed when fn returns nil.
+	// fn runs again when the transaction fails with a serialization failure or deadlock, see WithMaxAttempts,
+	// so it should not have side effects outside of the transaction, and build the aggregates it saves itself:
+	// saving an aggregate pulls its events, which a retry would not save again. Pass WithoutRetry otherwise.
+	// Called within another Do, fn runs in a savepoint of the outer transaction and opts are ignored.
+	// Funcs given to AfterCommit within fn run once the outermost transaction committed.
+	Do(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
+}
+
+// IsolationLevel of a transaction. The zero value is the database default, read committed for PostgreSQL.
+type IsolationLevel string
+
+const (
+	ReadCommitted  IsolationLevel = "read committed"
+	RepeatableRead IsolationLevel = "repeatable read"
+	Serializable   IsolationLevel = "serializable"
+)
+
+const (
+	// defaultMaxAttempts bounds the retries of a transaction without WithMaxAttempts, so that conflicts
+	// between concurrent requests resolve on their own without holding a request for long
+	defaultMaxAttempts = 3
+	retryBaseDelay     = 20 * time.Millisecond
+	retryMaxDelay      = time.Second
+)
+
+// TxOption configures a transaction started by Transaction.Do
+type TxOption func(*txOptions)
+
+type txOptions struct {
+	isolation        IsolationLevel
+	readOnly         bool
+	deferrable       bool
+	statementTimeout time.Duration
+	lockTimeout      time.Duration
+	maxAttempts      int
+}
+
+// WithIsolation sets the isolation level
+func WithIsolation(level IsolationLevel) TxOption {
+	return func(o *txOptions) { o.isolation = level }
+}
+
+// ReadOnly rejects writes in the transaction
+func ReadOnly() TxOption {
+	return func(o *txOptions) { o.readOnly = true }
+}
+
+// Deferrable makes a serializable read-only transaction wait for a snapshot
+// on which it cannot fail with a serialization failure, e.g. for long reports
+func Deferrable() TxOption {
+	return func(o *txOptions) { o.deferrable = true }
+}
+
+// WithStatementTimeout aborts statements of the transaction running longer than d
+func WithStatementTimeout(d time.Duration) TxOption {
+	return func(o *txOptions) { o.statementTimeout = d }
+}
+
+// WithLockTimeout aborts statements of the transaction waiting longer than d for a lock
+func WithLockTimeout(d time.Duration) TxOption {
+	return func(o *txOptions) { o.lockTimeout = d }
+}
+
+// WithMaxAttempts sets how many times fn runs when the transaction keeps failing with
+// a serialization failure or deadlock, 3 by default
+func WithMaxAttempts(n int) TxOption {
+	return func(o *txOptions) { o.maxAttempts = n }
+}
+
+// WithoutRetry runs fn once, for transactions with side effects outside of the database
+func WithoutRetry() TxOption {
+	return WithMaxAttempts(1)
+}
+
+func newTxOptions(opts []TxOption) txOptions {
+	o := txOptions{maxAttempts: defaultMaxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o txOptions) pgx() pgx.TxOptions {
+	opts := pgx.TxOptions{IsoLevel: pgx.TxIsoLevel(o.isolation)}
+	if o.readOnly {
+		opts.AccessMode = pgx.ReadOnly
+	}
+	if o.deferrable {
+		opts.DeferrableMode = pgx.Deferrable
+	}
+	return opts
+}
+
+// setLocal returns the SET LOCAL statements applying the timeouts of the transaction.
+// SET does not take parameters; the values are integers.
+func (o txOptions) setLocal() []string {
+	var statements []string
+	if o.statementTimeout > 0 {
+		statements = append(statements, fmt.Sprintf("SET LOCAL statement_timeout = %d", o.statementTimeout.Milliseconds()))
+	}
+	if o.lockTimeout > 0 {
+		statements = append(statements, fmt.Sprintf("SET LOCAL lock_timeout = %d", o.lockTimeout.Milliseconds()))
+	}
+	return statements
 }
 
 type dbTransaction struct {
@@ -28,23 +143,183 @@ func NewTransaction(pool *pgxpool.Pool) Transaction {
 func (tx *dbTransaction) Do(
 	ctx context.Context,
 	fn func(context.Context) error,
-) error {
-	t, err := tx.pool.Begin(ctx)
+	opts ...TxOption,
+) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "repository.Transaction.Do")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if outer, ok := ctx.Value(_contextKeyTx).(pgx.Tx); ok {
+		span.SetAttributes(attribute.Bool("db.transaction.savepoint", true))
+		return savepoint(ctx, outer, fn)
+	}
+
+	o := newTxOptions(opts)
+	attempts, err := Retry(ctx, opts, func() error {
+		return tx.run(ctx, fn, o)
+	})
+	span.SetAttributes(attribute.Int("db.transaction.attempts", attempts))
+
+	return err
+}
+
+// Retry calls attempt, a whole transaction, until it succeeds, fails with another error than a serialization failure
+// or deadlock, or was called as many times as WithMaxAttempts of opts allows, and returns the number of calls.
+// Retries wait with exponential backoff so that transactions that conflicted are not retried at the same time again.
+// Implementations of Transaction use it to retry alike.
+func Retry(ctx context.Context, opts []TxOption, attempt func() error) (int, error) {
+	o := newTxOptions(opts)
+
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || n >= o.maxAttempts || !IsSerializationFailure(err) {
+			return n, err
+		}
+
+		delay := backoff.Delay(n, retryBaseDelay, retryMaxDelay, rand.Float64())
+		zerolog.Ctx(ctx).Debug().Err(err).Int("attempt", n).Dur("delay", delay).Msg("retrying transaction")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return n, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (tx *dbTransaction) run(ctx context.Context, fn func(context.Context) error, o txOptions) error {
+	hctx, hooks := WithCommitHooks(ctx)
+
+	t, err := tx.pool.BeginTx(ctx, o.pgx())
 	if err != nil {
 		return err
 	}
 	defer t.Rollback(ctx)
 
-	if err := fn(SetSession(ctx, t)); err != nil {
+	for _, statement := range o.setLocal() {
+		if _, err := t.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	if err := fn(SetSession(hctx, t)); err != nil {
+		return err
+	}
+
+	if err := t.Commit(ctx); err != nil {
 		return err
 	}
+	hooks.Committed(ctx)
+
+	return nil
+}
+
+// savepoint runs fn in a savepoint of outer, so that a failure of fn rolls back only its own changes
+// and leaves the outer transaction usable.
+func savepoint(ctx context.Context, outer pgx.Tx, fn func(context.Context) error) error {
+	hctx, hooks := WithCommitHooks(ctx)
+
+	sp, err := outer.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer sp.Rollback(ctx)
+
+	if err := fn(SetSession(hctx, sp)); err != nil {
+		return err
+	}
+
+	if err := sp.Commit(ctx); err != nil {
+		return err
+	}
+	hooks.Committed(ctx)
+
+	return nil
+}
+
+// IsSerializationFailure reports whether err is a serialization failure or deadlock,
+// after which the whole transaction can be retried.
+func IsSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// AfterCommit runs fn once the transaction of ctx committed, e.g. to invalidate a cache only when a write took effect.
+// fn is dropped when the transaction, or the savepoint it was registered in, rolls back.
+// Outside of a transaction, fn runs right away.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if hooks, ok := ctx.Value(_contextKeyCommitHooks).(*CommitHooks); ok {
+		hooks.add(fn)
+		return
+	}
 
-	return t.Commit(ctx)
+	fn(ctx)
+}
+
+// InTransaction reports whether ctx is within Transaction.Do
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(_contextKeyCommitHooks).(*CommitHooks)
+	return ok
+}
+
+// CommitHooks collects the funcs given to AfterCommit within a transaction or savepoint.
+// Implementations of Transaction create them with WithCommitHooks and call Committed after committing.
+type CommitHooks struct {
+	mu     sync.Mutex
+	parent *CommitHooks
+	fns    []func(ctx context.Context)
+}
+
+// WithCommitHooks returns hooks for a transaction, or a savepoint when ctx already has hooks,
+// and a copy of ctx collecting the funcs given to AfterCommit in them
+func WithCommitHooks(ctx context.Context) (context.Context, *CommitHooks) {
+	hooks := &CommitHooks{}
+	hooks.parent, _ = ctx.Value(_contextKeyCommitHooks).(*CommitHooks)
+
+	return context.WithValue(ctx, _contextKeyCommitHooks, hooks), hooks
+}
+
+// Committed runs the collected funcs with ctx, or hands them to the enclosing transaction for a savepoint
+func (h *CommitHooks) Committed(ctx context.Context) {
+	h.mu.Lock()
+	fns := h.fns
+	h.fns = nil
+	h.mu.Unlock()
+
+	if h.parent != nil {
+		h.parent.add(fns...)
+		return
+	}
+
+	for _, fn := range fns {
+		fn(ctx)
+	}
+}
+
+func (h *CommitHooks) add(fns ...func(ctx context.Context)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.fns = append(h.fns, fns...)
 }
 
 type key struct{ value string }
 
-var _contextKeyTx = &key{"_contextKeyTx"}
+var (
+	_contextKeyTx          = &key{"_contextKeyTx"}
+	_contextKeyCommitHooks = &key{"_contextKeyCommitHooks"}
+)
 
 // GetSessionOr returns the current transaction or the fallback pool.
 // It'll use a transaction if it exists, otherwise it'll use the fallback pool.
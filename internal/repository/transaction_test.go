@@ -0,0 +1,100 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+)
+
+func TestIsSerializationFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, expected: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: "40P01"}, expected: true},
+		{name: "wrapped", err: fmt.Errorf("failed to create user: %w", &pgconn.PgError{Code: "40001"}), expected: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, expected: false},
+		{name: "other error", err: errors.New("40001"), expected: false},
+		{name: "nil", err: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, repository.IsSerializationFailure(tt.err))
+		})
+	}
+}
+
+func TestAfterCommit(t *testing.T) {
+	var ran []string
+	hook := func(name string) func(context.Context) {
+		return func(context.Context) { ran = append(ran, name) }
+	}
+
+	// outside of a transaction the func runs right away
+	repository.AfterCommit(context.Background(), hook("now"))
+	assert.Equal(t, []string{"now"}, ran)
+	ran = nil
+
+	ctx, tx := repository.WithCommitHooks(context.Background())
+	assert.True(t, repository.InTransaction(ctx))
+	repository.AfterCommit(ctx, hook("tx"))
+
+	// a committed savepoint hands its funcs to the transaction, a rolled back one drops them
+	spCtx, sp := repository.WithCommitHooks(ctx)
+	repository.AfterCommit(spCtx, hook("savepoint"))
+	sp.Committed(spCtx)
+	rolledBackCtx, _ := repository.WithCommitHooks(ctx)
+	repository.AfterCommit(rolledBackCtx, hook("rolled back"))
+	assert.Empty(t, ran)
+
+	tx.Committed(context.Background())
+	assert.Equal(t, []string{"tx", "savepoint"}, ran)
+	assert.False(t, repository.InTransaction(context.Background()))
+}
+
+func TestRetry(t *testing.T) {
+	serializationFailure := &pgconn.PgError{Code: "40001"}
+
+	t.Run("stops at the first success", func(t *testing.T) {
+		calls := 0
+		attempts, err := repository.Retry(context.Background(), []repository.TxOption{repository.WithMaxAttempts(5)}, func() error {
+			calls++
+			if calls < 3 {
+				return serializationFailure
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("stops waiting when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts, err := repository.Retry(ctx, []repository.TxOption{repository.WithMaxAttempts(5)}, func() error {
+			cancel()
+			return serializationFailure
+		})
+		require.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestSetLocal(t *testing.T) {
+	assert.Empty(t, repository.SetLocal())
+	assert.Empty(t, repository.SetLocal(repository.WithIsolation(repository.Serializable), repository.WithMaxAttempts(3)))
+	assert.Equal(t, []string{
+		"SET LOCAL statement_timeout = 1500",
+		"SET LOCAL lock_timeout = 200",
+	}, repository.SetLocal(repository.WithLockTimeout(200*time.Millisecond), repository.WithStatementTimeout(1500*time.Millisecond)))
+}
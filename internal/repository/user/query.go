@@ -0,0 +1,64 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+)
+
+// userColumns are the columns of the fields of domain.UserQuerySchema
+var userColumns = repository.Columns{
+	"id":         "id",
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// selectUsers lists the columns of sqlc.User in order, to scan rows by position
+const selectUsers = `SELECT id, name, email, password, created_at, updated_at, version, deleted_at FROM users WHERE deleted_at IS NULL`
+
+// userLister implements domain.UserLister with SQL built from the query
+type userLister struct {
+	*repository.BaseRepository
+}
+
+// NewUserLister creates a new user lister implementation
+// Lists use replicas when given; nil replicas read from the primary pool
+// Following DIP: returns domain interface, not concrete type
+func NewUserLister(pool *pgxpool.Pool, replicas *repository.Replicas) domain.UserLister {
+	return &userLister{
+		BaseRepository: repository.NewBaseRepositoryWithReplicas(pool, replicas),
+	}
+}
+
+// QueryUsers implements domain.UserLister
+func (r *userLister) QueryUsers(ctx context.Context, q domain.Query) (*domain.Page[domain.User], error) {
+	page, err := repository.QueryPage(ctx, r.GetReader(ctx), selectUsers, q, userColumns, userValue, toUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+
+	return page, nil
+}
+
+// userValue returns the value of a sortable field of u
+func userValue(u sqlc.User, field string) any {
+	switch field {
+	case "id":
+		return uuid.UUID(u.ID.Bytes)
+	case "name":
+		return u.Name
+	case "created_at":
+		return u.CreatedAt.Time
+	case "updated_at":
+		return u.UpdatedAt.Time
+	}
+
+	return nil
+}
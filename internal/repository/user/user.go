@@ -2,131 +2,143 @@ package user
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/SoraDaibu/go-clean-starter/domain"
 	"github.com/SoraDaibu/go-clean-starter/internal/repository"
 	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
 	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
 )
 
 // userRepository implements domain.UserRepository
 // Following DIP: depends on abstractions (domain interfaces) not concrete implementations
-// Following composition: uses BaseRepository for common functionality
+// Following composition: uses the generic Repository for common functionality
 type userRepository struct {
-	*repository.BaseRepository
+	*repository.Repository[domain.User, *domain.User, sqlc.User]
+}
+
+var _ domain.BaseRepository[domain.User] = (*repository.Repository[domain.User, *domain.User, sqlc.User])(nil)
+
+// userDefinition declares how users are stored
+var userDefinition = repository.Definition[domain.User, sqlc.User]{
+	Name:     "user",
+	ToEntity: toUser,
+	Get:      (*sqlc.Queries).GetUser,
+	List:     (*sqlc.Queries).ListUsers,
+	Create: func(q *sqlc.Queries, ctx context.Context, user *domain.User) (sqlc.User, error) {
+		return q.CreateUser(ctx, sqlc.CreateUserParams{
+			ID:       common.UUIDToPgtype(user.ID()),
+			Name:     user.Name(),
+			Email:    user.Email(),
+			Password: string(user.Password()),
+		})
+	},
+	Update: func(q *sqlc.Queries, ctx context.Context, user *domain.User) (sqlc.User, error) {
+		return q.UpdateUser(ctx, sqlc.UpdateUserParams{
+			ID:      common.UUIDToPgtype(user.ID()),
+			Name:    user.Name(),
+			Version: user.Version(),
+		})
+	},
+	Delete: func(q *sqlc.Queries, ctx context.Context, user *domain.User) (int64, error) {
+		return q.DeleteUser(ctx, sqlc.DeleteUserParams{
+			ID:      common.UUIDToPgtype(user.ID()),
+			Version: user.Version(),
+		})
+	},
 }
 
 // NewUserRepository creates a new user repository implementation
+// Readers use replicas when given; nil replicas read from the primary pool
 // Following DIP: returns domain interface, not concrete type
-func NewUserRepository(pool *pgxpool.Pool) domain.UserRepository {
+func NewUserRepository(pool *pgxpool.Pool, replicas *repository.Replicas) domain.UserRepository {
 	return &userRepository{
-		BaseRepository: repository.NewBaseRepository(pool),
+		Repository: repository.NewRepository[domain.User, *domain.User](
+			repository.NewBaseRepositoryWithReplicas(pool, replicas),
+			userDefinition,
+		),
 	}
 }
 
 // GetUser implements domain.UserReader
 func (r *userRepository) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
-	u, err := r.GetQueries(ctx).GetUser(ctx, common.UUIDToPgtype(id))
-	if err != nil {
-		return nil, err
-	}
-
-	userID, err := common.PgtypeToUUID(u.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	return domain.UserFromSource(userID, u.Name, u.Email), nil
+	return r.Get(ctx, id)
 }
 
 // ListUsers implements domain.UserReader
-// Note: The current sqlc query doesn't support limit/offset, so we apply manual pagination
 func (r *userRepository) ListUsers(ctx context.Context, limit, offset int) ([]*domain.User, error) {
-	users, err := r.GetQueries(ctx).ListUsers(ctx)
+	return r.List(ctx, limit, offset)
+}
+
+// ListDeletedUsers implements domain.UserReader
+// Note: The current sqlc query doesn't support limit/offset, so we apply manual pagination
+func (r *userRepository) ListDeletedUsers(ctx context.Context, limit, offset int) ([]*domain.User, error) {
+	users, err := r.GetReaderQueries(ctx).ListDeletedUsers(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply manual pagination since sqlc query doesn't support it
-	start := offset
-	end := offset + limit
-	if start >= len(users) {
-		return []*domain.User{}, nil
-	}
-	if end > len(users) {
-		end = len(users)
-	}
+	zerolog.Ctx(ctx).Debug().Int("total", len(users)).Int("limit", limit).Int("offset", offset).Msg("paginating deleted users in memory")
 
-	result := make([]*domain.User, end-start)
-	for i, u := range users[start:end] {
-		userID, err := common.PgtypeToUUID(u.ID)
-		if err != nil {
-			return nil, err
-		}
-		result[i] = domain.UserFromSource(userID, u.Name, u.Email)
-	}
-
-	return result, nil
+	return r.ToEntities(common.Paginate(users, limit, offset))
 }
 
 // GetUserByEmail implements domain.UserReader
 func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
-	u, err := r.GetQueries(ctx).GetUserByEmail(ctx, email)
-	if err != nil {
-		return nil, err
+	u, err := r.GetReaderQueries(ctx).GetUserByEmail(ctx, email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &domain.NotFoundError{Entity: "user", Field: "email", Value: email}
 	}
-
-	userID, err := common.PgtypeToUUID(u.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	return domain.UserFromSource(userID, u.Name, u.Email), nil
+	return toUser(u)
 }
 
 // CreateUser implements domain.UserWriter
 func (r *userRepository) CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
-	u, err := r.GetQueries(ctx).CreateUser(ctx, sqlc.CreateUserParams{
-		ID:       common.UUIDToPgtype(user.ID()),
-		Name:     user.Name(),
-		Email:    user.Email(),
-		Password: string(user.Password()),
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	userID, err := common.PgtypeToUUID(u.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	return domain.UserFromSource(userID, u.Name, u.Email), nil
+	return r.Create(ctx, user)
 }
 
 // UpdateUser implements domain.UserWriter
 func (r *userRepository) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
-	u, err := r.GetQueries(ctx).UpdateUser(ctx, sqlc.UpdateUserParams{
-		ID:   common.UUIDToPgtype(user.ID()),
-		Name: user.Name(),
-	})
+	return r.Update(ctx, user)
+}
 
+// DeleteUser implements domain.UserWriter
+func (r *userRepository) DeleteUser(ctx context.Context, user *domain.User) error {
+	return r.Delete(ctx, user)
+}
+
+// RestoreUser implements domain.UserWriter
+func (r *userRepository) RestoreUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	u, err := r.GetWriterQueries(ctx).RestoreUser(ctx, common.UUIDToPgtype(id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.NotFound(id)
+	}
 	if err != nil {
-		return nil, err
+		return nil, repository.TranslateError(err)
 	}
 
+	return toUser(u)
+}
+
+// PurgeDeletedUsers implements domain.UserWriter
+func (r *userRepository) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	return r.GetWriterQueries(ctx).PurgeDeletedUsers(ctx, common.TimeToPgtype(before))
+}
+
+func toUser(u sqlc.User) (*domain.User, error) {
 	userID, err := common.PgtypeToUUID(u.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	return domain.UserFromSource(userID, u.Name, u.Email), nil
-}
-
-// DeleteUser implements domain.UserWriter
-func (r *userRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	return r.GetQueries(ctx).DeleteUser(ctx, common.UUIDToPgtype(id))
+	return domain.UserFromSource(userID, u.Name, u.Email, u.Version, common.PgtypeToTimePtr(u.DeletedAt)), nil
 }
@@ -0,0 +1,286 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/common"
+	"github.com/SoraDaibu/go-clean-starter/internal/sqlc"
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+)
+
+// webhookRepository implements webhook.Repository
+// Following composition: uses BaseRepository for common functionality
+type webhookRepository struct {
+	*repository.BaseRepository
+}
+
+// NewWebhookRepository creates a new webhook repository implementation
+// Following DIP: returns the webhook interface, not concrete type
+func NewWebhookRepository(pool *pgxpool.Pool) webhook.Repository {
+	return &webhookRepository{
+		BaseRepository: repository.NewBaseRepository(pool),
+	}
+}
+
+// CreateSubscription implements webhook.Repository
+func (r *webhookRepository) CreateSubscription(ctx context.Context, subscription *webhook.Subscription) (*webhook.Subscription, error) {
+	s, err := r.GetQueries(ctx).CreateWebhookSubscription(ctx, sqlc.CreateWebhookSubscriptionParams{
+		ID:         common.UUIDToPgtype(subscription.ID),
+		URL:        subscription.URL,
+		EventTypes: nonNil(subscription.EventTypes),
+		Secret:     subscription.Secret,
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return toSubscription(s)
+}
+
+// GetSubscription implements webhook.Repository
+func (r *webhookRepository) GetSubscription(ctx context.Context, id uuid.UUID) (*webhook.Subscription, error) {
+	s, err := r.GetQueries(ctx).GetWebhookSubscription(ctx, common.UUIDToPgtype(id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, webhook.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toSubscription(s)
+}
+
+// ListSubscriptions implements webhook.Repository
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]*webhook.Subscription, error) {
+	subscriptions, err := r.GetQueries(ctx).ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSubscriptions(subscriptions)
+}
+
+// ListEnabledSubscriptions implements webhook.Repository
+func (r *webhookRepository) ListEnabledSubscriptions(ctx context.Context) ([]*webhook.Subscription, error) {
+	subscriptions, err := r.GetQueries(ctx).ListEnabledWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSubscriptions(subscriptions)
+}
+
+// UpdateSubscription implements webhook.Repository
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, subscription *webhook.Subscription) (*webhook.Subscription, error) {
+	params := sqlc.UpdateWebhookSubscriptionParams{
+		ID:                  common.UUIDToPgtype(subscription.ID),
+		URL:                 subscription.URL,
+		EventTypes:          nonNil(subscription.EventTypes),
+		Enabled:             subscription.Enabled,
+		ConsecutiveFailures: subscription.ConsecutiveFailures,
+	}
+	if subscription.DisabledAt != nil {
+		params.DisabledAt = common.TimeToPgtype(*subscription.DisabledAt)
+	}
+
+	s, err := r.GetQueries(ctx).UpdateWebhookSubscription(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, webhook.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toSubscription(s)
+}
+
+// DeleteSubscription implements webhook.Repository
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	deleted, err := r.GetQueries(ctx).DeleteWebhookSubscription(ctx, common.UUIDToPgtype(id))
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return webhook.ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// ResetFailures implements webhook.Repository
+func (r *webhookRepository) ResetFailures(ctx context.Context, id uuid.UUID) error {
+	return r.GetQueries(ctx).ResetWebhookSubscriptionFailures(ctx, common.UUIDToPgtype(id))
+}
+
+// IncrementFailures implements webhook.Repository
+func (r *webhookRepository) IncrementFailures(ctx context.Context, id uuid.UUID) (*webhook.Subscription, error) {
+	s, err := r.GetQueries(ctx).IncrementWebhookSubscriptionFailures(ctx, common.UUIDToPgtype(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return toSubscription(s)
+}
+
+// DisableSubscription implements webhook.Repository
+func (r *webhookRepository) DisableSubscription(ctx context.Context, id uuid.UUID) error {
+	return r.GetQueries(ctx).DisableWebhookSubscription(ctx, common.UUIDToPgtype(id))
+}
+
+// CreateDelivery implements webhook.Repository
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	err := r.GetQueries(ctx).CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+		SubscriptionID: common.UUIDToPgtype(delivery.SubscriptionID),
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+	})
+
+	// the subscription may have been deleted since the event was fanned out
+	if repository.IsForeignKeyViolation(err) {
+		return webhook.ErrSubscriptionNotFound
+	}
+	return err
+}
+
+// ClaimDueDeliveries implements webhook.Repository
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*webhook.Delivery, error) {
+	deliveries, err := r.GetQueries(ctx).ClaimDueWebhookDeliveries(ctx, sqlc.ClaimDueWebhookDeliveriesParams{
+		NextAttemptAt: common.TimeToPgtype(now),
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toDeliveries(deliveries)
+}
+
+// RecordAttempt implements webhook.Repository
+func (r *webhookRepository) RecordAttempt(ctx context.Context, delivery *webhook.Delivery) error {
+	params := sqlc.RecordWebhookDeliveryAttemptParams{
+		ID:            delivery.ID,
+		Status:        string(delivery.Status),
+		NextAttemptAt: common.TimeToPgtype(delivery.NextAttemptAt),
+	}
+	if delivery.ResponseCode != nil {
+		code := int32(*delivery.ResponseCode)
+		params.ResponseCode = &code
+	}
+	if delivery.LastError != "" {
+		params.LastError = &delivery.LastError
+	}
+
+	return r.GetQueries(ctx).RecordWebhookDeliveryAttempt(ctx, params)
+}
+
+// ListDeliveries implements webhook.Repository
+func (r *webhookRepository) ListDeliveries(ctx context.Context, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	params := sqlc.ListWebhookDeliveriesParams{
+		SubscriptionID: common.UUIDToPgtype(filter.SubscriptionID),
+		PageSize:       int32(filter.Limit),
+	}
+	if filter.Status != "" {
+		status := string(filter.Status)
+		params.Status = &status
+	}
+	if filter.AfterID != 0 {
+		params.AfterID = &filter.AfterID
+	}
+
+	deliveries, err := r.GetQueries(ctx).ListWebhookDeliveries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDeliveries(deliveries)
+}
+
+func toSubscription(s sqlc.WebhookSubscription) (*webhook.Subscription, error) {
+	id, err := common.PgtypeToUUID(s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook.Subscription{
+		ID:                  id,
+		URL:                 s.URL,
+		EventTypes:          s.EventTypes,
+		Secret:              s.Secret,
+		Enabled:             s.Enabled,
+		ConsecutiveFailures: s.ConsecutiveFailures,
+		DisabledAt:          common.PgtypeToTimePtr(s.DisabledAt),
+		CreatedAt:           s.CreatedAt.Time,
+		UpdatedAt:           s.UpdatedAt.Time,
+	}, nil
+}
+
+func toSubscriptions(subscriptions []sqlc.WebhookSubscription) ([]*webhook.Subscription, error) {
+	result := make([]*webhook.Subscription, len(subscriptions))
+	for i, s := range subscriptions {
+		subscription, err := toSubscription(s)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = subscription
+	}
+
+	return result, nil
+}
+
+func toDelivery(d sqlc.WebhookDelivery) (*webhook.Delivery, error) {
+	subscriptionID, err := common.PgtypeToUUID(d.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &webhook.Delivery{
+		ID:             d.ID,
+		SubscriptionID: subscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         webhook.DeliveryStatus(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt.Time,
+		LastAttemptAt:  common.PgtypeToTimePtr(d.LastAttemptAt),
+		CreatedAt:      d.CreatedAt.Time,
+	}
+	if d.ResponseCode != nil {
+		code := int(*d.ResponseCode)
+		delivery.ResponseCode = &code
+	}
+	if d.LastError != nil {
+		delivery.LastError = *d.LastError
+	}
+
+	return delivery, nil
+}
+
+func toDeliveries(deliveries []sqlc.WebhookDelivery) ([]*webhook.Delivery, error) {
+	result := make([]*webhook.Delivery, len(deliveries))
+	for i, d := range deliveries {
+		delivery, err := toDelivery(d)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = delivery
+	}
+
+	return result, nil
+}
+
+// nonNil stores no filter as an empty array rather than NULL
+func nonNil(eventTypes []string) []string {
+	if eventTypes == nil {
+		return []string{}
+	}
+	return eventTypes
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+)
+
+func (u *auditUsecase) ListAuditEvents(ctx context.Context, input *ListAuditEventsInput) (*ListAuditEventsOutput, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	afterID, err := decodeCursor(input.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// fetch one more event to know whether there is a next page
+	events, err := u.auditRepository.ListEvents(ctx, audit.Filter{
+		EntityType: input.EntityType,
+		EntityID:   input.EntityID,
+		Actor:      input.Actor,
+		Action:     audit.Action(input.Action),
+		Since:      input.Since,
+		Until:      input.Until,
+		AfterID:    afterID,
+		Limit:      input.Limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ListAuditEventsOutput{Events: []*AuditEventOutput{}}
+	if len(events) > input.Limit {
+		events = events[:input.Limit]
+		output.NextCursor = encodeCursor(events[len(events)-1].ID)
+	}
+
+	for _, event := range events {
+		output.Events = append(output.Events, NewAuditEventOutput(event))
+	}
+
+	return output, nil
+}
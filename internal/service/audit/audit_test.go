@@ -0,0 +1,152 @@
+package audit_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+	service "github.com/SoraDaibu/go-clean-starter/internal/service/audit"
+)
+
+// fakeRepository lists events newest first like ORDER BY id DESC, recording the filters it got
+type fakeRepository struct {
+	audit.Repository
+	events  []*audit.Event
+	filters []audit.Filter
+}
+
+func (r *fakeRepository) ListEvents(_ context.Context, filter audit.Filter) ([]*audit.Event, error) {
+	r.filters = append(r.filters, filter)
+
+	var events []*audit.Event
+	for _, e := range slices.Backward(r.events) {
+		if filter.AfterID != 0 && e.ID >= filter.AfterID {
+			continue
+		}
+		events = append(events, e)
+		if len(events) == filter.Limit {
+			break
+		}
+	}
+	return events, nil
+}
+
+func newFakeRepository(n int) *fakeRepository {
+	r := &fakeRepository{}
+	for i := 1; i <= n; i++ {
+		r.events = append(r.events, &audit.Event{
+			ID:         int64(i),
+			Actor:      "alice",
+			EntityType: "user",
+			EntityID:   uuid.New(),
+			Action:     audit.ActionUpdate,
+			Diff:       map[string]audit.Change{"name": {Before: "a", After: "b"}},
+			CreatedAt:  time.Date(2025, 1, 1, 0, 0, i, 0, time.UTC),
+		})
+	}
+	return r
+}
+
+func TestAuditUsecase_ListAuditEvents(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pages with cursors", func(t *testing.T) {
+		uc := service.NewAuditUsecase(newFakeRepository(5))
+
+		var ids []int64
+		input := &service.ListAuditEventsInput{Limit: 2}
+		for range 4 {
+			output, err := uc.ListAuditEvents(ctx, input)
+			require.NoError(t, err)
+			for _, e := range output.Events {
+				ids = append(ids, e.ID)
+			}
+			if output.NextCursor == "" {
+				break
+			}
+			input.Cursor = output.NextCursor
+		}
+
+		assert.Equal(t, []int64{5, 4, 3, 2, 1}, ids)
+	})
+
+	t.Run("passes the filter", func(t *testing.T) {
+		repo := newFakeRepository(1)
+		entityID := uuid.New()
+		since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		until := since.Add(time.Hour)
+
+		_, err := service.NewAuditUsecase(repo).ListAuditEvents(ctx, &service.ListAuditEventsInput{
+			EntityType: "user",
+			EntityID:   entityID,
+			Actor:      "alice",
+			Action:     "delete",
+			Since:      since,
+			Until:      until,
+		})
+		require.NoError(t, err)
+
+		// one more than the default limit tells whether there is a next page
+		assert.Equal(t, []audit.Filter{{
+			EntityType: "user",
+			EntityID:   entityID,
+			Actor:      "alice",
+			Action:     audit.ActionDelete,
+			Since:      since,
+			Until:      until,
+			Limit:      51,
+		}}, repo.filters)
+	})
+
+	t.Run("maps events", func(t *testing.T) {
+		repo := newFakeRepository(2)
+		repo.events[0].EntityID = uuid.Nil
+
+		output, err := service.NewAuditUsecase(repo).ListAuditEvents(ctx, &service.ListAuditEventsInput{})
+		require.NoError(t, err)
+		require.Len(t, output.Events, 2)
+		assert.Empty(t, output.NextCursor)
+
+		latest := output.Events[0]
+		assert.Equal(t, "alice", latest.Actor)
+		assert.Equal(t, "update", latest.Action)
+		require.NotNil(t, latest.EntityID)
+		assert.Equal(t, repo.events[1].EntityID, *latest.EntityID)
+		assert.Equal(t, map[string]audit.Change{"name": {Before: "a", After: "b"}}, latest.Diff)
+
+		// events about several entities have none
+		assert.Nil(t, output.Events[1].EntityID)
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		now := time.Now()
+		tests := []struct {
+			name     string
+			input    service.ListAuditEventsInput
+			expected error
+		}{
+			{name: "limit too small", input: service.ListAuditEventsInput{Limit: -1}, expected: service.ErrInvalidLimit},
+			{name: "limit too large", input: service.ListAuditEventsInput{Limit: 101}, expected: service.ErrInvalidLimit},
+			{name: "since after until", input: service.ListAuditEventsInput{Since: now, Until: now.Add(-time.Second)}, expected: service.ErrInvalidPeriod},
+			{name: "empty period", input: service.ListAuditEventsInput{Since: now, Until: now}, expected: service.ErrInvalidPeriod},
+			{name: "cursor not base64", input: service.ListAuditEventsInput{Cursor: "???"}, expected: service.ErrInvalidCursor},
+			{name: "cursor not an id", input: service.ListAuditEventsInput{Cursor: "YWJj"}, expected: service.ErrInvalidCursor},
+			{name: "cursor not positive", input: service.ListAuditEventsInput{Cursor: "MA"}, expected: service.ErrInvalidCursor},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := newFakeRepository(1)
+				_, err := service.NewAuditUsecase(repo).ListAuditEvents(ctx, &tt.input)
+				require.ErrorIs(t, err, tt.expected)
+				assert.Empty(t, repo.filters)
+			})
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package audit
+
+import "github.com/SoraDaibu/go-clean-starter/domain"
+
+// errors name their query parameter in the response
+var (
+	ErrInvalidCursor = &domain.FieldError{Field: "cursor", Text: "cursor is invalid"}
+	ErrInvalidLimit  = &domain.FieldError{Field: "limit", Text: "limit must be between 1 and 100"}
+	ErrInvalidSince  = &domain.FieldError{Field: "since", Text: "since must be an RFC 3339 timestamp"}
+	ErrInvalidUntil  = &domain.FieldError{Field: "until", Text: "until must be an RFC 3339 timestamp"}
+	ErrInvalidPeriod = &domain.FieldError{Field: "since", Text: "since must be before until"}
+)
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 100
+)
+
+type ListAuditEventsInput struct {
+	EntityType string    `json:"entity_type"`
+	EntityID   uuid.UUID `json:"entity_id"`
+	Actor      string    `json:"actor"`
+	Action     string    `json:"action"`
+	Since      time.Time `json:"since"`
+	Until      time.Time `json:"until"`
+	// Cursor is the NextCursor of the previous page
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+func (i *ListAuditEventsInput) validate() error {
+	if i.Limit == 0 {
+		i.Limit = defaultLimit
+	}
+	if i.Limit < 1 || i.Limit > maxLimit {
+		return ErrInvalidLimit
+	}
+
+	if !i.Since.IsZero() && !i.Until.IsZero() && !i.Since.Before(i.Until) {
+		return ErrInvalidPeriod
+	}
+
+	if _, err := decodeCursor(i.Cursor); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// encodeCursor makes the ID of the last event of a page opaque to clients
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return id, nil
+}
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+)
+
+type AuditEventOutput struct {
+	ID         int64                   `json:"id"`
+	Actor      string                  `json:"actor"`
+	EntityType string                  `json:"entity_type"`
+	EntityID   *uuid.UUID              `json:"entity_id"`
+	Action     string                  `json:"action"`
+	Diff       map[string]audit.Change `json:"diff"`
+	CreatedAt  time.Time               `json:"created_at"`
+}
+
+func NewAuditEventOutput(event *audit.Event) *AuditEventOutput {
+	output := &AuditEventOutput{
+		ID:         event.ID,
+		Actor:      event.Actor,
+		EntityType: event.EntityType,
+		Action:     string(event.Action),
+		Diff:       event.Diff,
+		CreatedAt:  event.CreatedAt,
+	}
+	if event.EntityID != uuid.Nil {
+		output.EntityID = &event.EntityID
+	}
+
+	return output
+}
+
+type ListAuditEventsOutput struct {
+	Events []*AuditEventOutput `json:"events"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
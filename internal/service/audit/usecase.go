@@ -0,0 +1,21 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+)
+
+type AuditUsecase interface {
+	ListAuditEvents(ctx context.Context, input *ListAuditEventsInput) (*ListAuditEventsOutput, error)
+}
+
+type auditUsecase struct {
+	auditRepository audit.Repository
+}
+
+// NewAuditUsecase creates a new audit usecase
+// Following DIP: depends on the repository interface, not concrete implementation
+func NewAuditUsecase(auditRepository audit.Repository) AuditUsecase {
+	return &auditUsecase{auditRepository: auditRepository}
+}
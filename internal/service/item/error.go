@@ -0,0 +1,15 @@
+package item
+
+import "github.com/SoraDaibu/go-clean-starter/domain"
+
+// Validation errors are field errors, so that the response names the invalid query parameter
+var (
+	ErrQueryRequired = &domain.FieldError{Field: "q", Text: "q is required"}
+	ErrQueryTooLong  = &domain.FieldError{Field: "q", Text: "q must be at most 200 characters"}
+	ErrInvalidType   = &domain.FieldError{Field: "type", Text: "type must be a comma-separated list of item type IDs"}
+	ErrInvalidSort   = &domain.FieldError{Field: "sort", Text: "sort must be relevance, -created_at, created_at or name"}
+	ErrInvalidPeriod = &domain.FieldError{Field: "created_from", Text: "created_from must be before created_to"}
+	ErrInvalidTime   = &domain.FieldError{Field: "created_from", Text: "created_from and created_to must be RFC 3339 timestamps"}
+	ErrInvalidCursor = &domain.FieldError{Field: "cursor", Text: "cursor is invalid"}
+	ErrInvalidLimit  = &domain.FieldError{Field: "limit", Text: "limit must be between 1 and 100"}
+)
@@ -0,0 +1,134 @@
+package item
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+	maxQueryLen  = 200
+)
+
+type SearchItemsInput struct {
+	Query       string    `json:"q"`
+	TypeIDs     []uint    `json:"type"`
+	CreatedFrom time.Time `json:"created_from"`
+	CreatedTo   time.Time `json:"created_to"`
+	// Sort is a domain.ItemSort, relevance by default
+	Sort string `json:"sort"`
+	// Cursor is the NextCursor of the previous page, with the same sort
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+
+	// after is the decoded Cursor, set by validate
+	after *domain.ItemSearchKey
+}
+
+func (i *SearchItemsInput) validate() error {
+	i.Query = strings.TrimSpace(i.Query)
+	if i.Query == "" {
+		return ErrQueryRequired
+	}
+	if utf8.RuneCountInString(i.Query) > maxQueryLen {
+		return ErrQueryTooLong
+	}
+
+	if i.Sort == "" {
+		i.Sort = string(domain.ItemSortRelevance)
+	}
+	if !slices.Contains(domain.ItemSorts, domain.ItemSort(i.Sort)) {
+		return ErrInvalidSort
+	}
+
+	if i.Limit == 0 {
+		i.Limit = defaultLimit
+	}
+	if i.Limit < 1 || i.Limit > maxLimit {
+		return ErrInvalidLimit
+	}
+
+	if !i.CreatedFrom.IsZero() && !i.CreatedTo.IsZero() && !i.CreatedFrom.Before(i.CreatedTo) {
+		return ErrInvalidPeriod
+	}
+
+	after, err := decodeCursor(i.Cursor, domain.ItemSort(i.Sort))
+	if err != nil {
+		return err
+	}
+	i.after = after
+
+	return nil
+}
+
+type ListItemsInput struct {
+	// Query holds the filters and sort, checked against domain.ItemQuerySchema
+	Query domain.Query
+	// Cursor is the NextCursor of the previous page, with the same sort
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+func (i *ListItemsInput) validate() error {
+	if i.Limit == 0 {
+		i.Limit = defaultLimit
+	}
+	if i.Limit < 1 || i.Limit > maxLimit {
+		return ErrInvalidLimit
+	}
+
+	if len(i.Query.Sort) == 0 {
+		i.Query.Sort = domain.ItemQuerySchema.DefaultSort
+	}
+
+	after, err := domain.ItemQuerySchema.DecodeCursor(i.Query.Sort, i.Cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	i.Query.After = after
+	i.Query.Limit = i.Limit
+
+	return nil
+}
+
+// cursor is the sort key of the last item of a page. It keeps the sort, as it is only valid with it.
+type cursor struct {
+	Sort      domain.ItemSort `json:"s"`
+	ID        uuid.UUID       `json:"id"`
+	Score     float32         `json:"sc,omitempty"`
+	CreatedAt time.Time       `json:"c,omitzero"`
+	Name      string          `json:"n,omitempty"`
+}
+
+// encodeCursor makes the sort key of the last item of a page opaque to clients
+func encodeCursor(sort domain.ItemSort, key *domain.ItemSearchKey) string {
+	b, _ := json.Marshal(cursor{Sort: sort, ID: key.ID, Score: key.Score, CreatedAt: key.CreatedAt, Name: key.Name})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string, sort domain.ItemSort) (*domain.ItemSearchKey, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sort || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &domain.ItemSearchKey{ID: c.ID, Score: c.Score, CreatedAt: c.CreatedAt, Name: c.Name}, nil
+}
@@ -0,0 +1,62 @@
+package item
+
+import (
+	"context"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+)
+
+func (u *itemUsecase) ListItems(ctx context.Context, input *ListItemsInput) (*ListItemsOutput, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	page, err := u.itemLister.QueryItems(ctx, input.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ListItemsOutput{Items: make([]*ItemOutput, len(page.Items))}
+	for i, item := range page.Items {
+		output.Items[i] = NewItemOutput(item)
+	}
+	if page.Next != nil {
+		output.NextCursor = domain.ItemQuerySchema.EncodeCursor(input.Query.Sort, page.Next)
+	}
+
+	return output, nil
+}
+
+func (u *itemUsecase) SearchItems(ctx context.Context, input *SearchItemsInput) (*SearchItemsOutput, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	sort := domain.ItemSort(input.Sort)
+
+	// fetch one more item to know whether there is a next page
+	results, err := u.itemSearcher.SearchItems(ctx, domain.ItemSearch{
+		Text:        input.Query,
+		TypeIDs:     input.TypeIDs,
+		CreatedFrom: input.CreatedFrom,
+		CreatedTo:   input.CreatedTo,
+		Sort:        sort,
+		After:       input.after,
+		Limit:       input.Limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &SearchItemsOutput{Items: []*ItemSearchResultOutput{}}
+	if len(results) > input.Limit {
+		results = results[:input.Limit]
+		output.NextCursor = encodeCursor(sort, results[len(results)-1].Key(sort))
+	}
+
+	for _, result := range results {
+		output.Items = append(output.Items, NewItemSearchResultOutput(result))
+	}
+
+	return output, nil
+}
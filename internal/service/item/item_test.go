@@ -0,0 +1,122 @@
+package item_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/service/item"
+)
+
+// searcher pages through results in order, recording the searches it got
+type searcher struct {
+	results  []*domain.ItemSearchResult
+	searches []domain.ItemSearch
+}
+
+func (s *searcher) SearchItems(_ context.Context, search domain.ItemSearch) ([]*domain.ItemSearchResult, error) {
+	s.searches = append(s.searches, search)
+
+	results := s.results
+	if search.After != nil {
+		for i, r := range s.results {
+			if r.Item.ID() == search.After.ID {
+				results = s.results[i+1:]
+			}
+		}
+	}
+	return results[:min(search.Limit, len(results))], nil
+}
+
+func newResults(n int) []*domain.ItemSearchResult {
+	results := make([]*domain.ItemSearchResult, n)
+	for i := range results {
+		results[i] = &domain.ItemSearchResult{
+			Item:          domain.NewItem(1, "item", "description"),
+			CreatedAt:     time.Date(2025, 1, 1, 0, 0, i, 0, time.UTC),
+			Score:         float32(n-i) / 3,
+			NameHighlight: "<mark>item</mark>",
+		}
+	}
+	return results
+}
+
+func TestItemUsecase_SearchItems(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pages with cursors", func(t *testing.T) {
+		s := &searcher{results: newResults(5)}
+		iu := item.NewItemUsecase(s, nil)
+
+		var ids []uuid.UUID
+		input := &item.SearchItemsInput{Query: " item ", Limit: 2}
+		for page := 1; ; page++ {
+			output, err := iu.SearchItems(ctx, input)
+			require.NoError(t, err)
+			for _, r := range output.Items {
+				ids = append(ids, r.ID)
+			}
+			if output.NextCursor == "" {
+				assert.Equal(t, 3, page)
+				break
+			}
+			input.Cursor = output.NextCursor
+		}
+
+		require.Len(t, ids, 5)
+		for i, r := range s.results {
+			assert.Equal(t, r.Item.ID(), ids[i])
+		}
+
+		// the searcher gets the text trimmed, one more than the limit and the key of the last result
+		assert.Equal(t, "item", s.searches[1].Text)
+		assert.Equal(t, 3, s.searches[1].Limit)
+		assert.Equal(t, domain.ItemSortRelevance, s.searches[1].Sort)
+		assert.Equal(t, &domain.ItemSearchKey{ID: s.results[1].Item.ID(), Score: s.results[1].Score}, s.searches[1].After)
+	})
+
+	t.Run("cursors keep the sort key of their sort", func(t *testing.T) {
+		s := &searcher{results: newResults(3)}
+		iu := item.NewItemUsecase(s, nil)
+
+		output, err := iu.SearchItems(ctx, &item.SearchItemsInput{Query: "item", Sort: "-created_at", Limit: 1})
+		require.NoError(t, err)
+
+		_, err = iu.SearchItems(ctx, &item.SearchItemsInput{Query: "item", Sort: "-created_at", Limit: 1, Cursor: output.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, &domain.ItemSearchKey{ID: s.results[0].Item.ID(), CreatedAt: s.results[0].CreatedAt}, s.searches[1].After)
+
+		// a cursor is only valid with its sort
+		_, err = iu.SearchItems(ctx, &item.SearchItemsInput{Query: "item", Sort: "name", Cursor: output.NextCursor})
+		assert.ErrorIs(t, err, item.ErrInvalidCursor)
+	})
+
+	t.Run("validates the input", func(t *testing.T) {
+		from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		tests := []struct {
+			name  string
+			input item.SearchItemsInput
+			err   error
+		}{
+			{"q is required", item.SearchItemsInput{Query: "  "}, item.ErrQueryRequired},
+			{"q is limited", item.SearchItemsInput{Query: strings.Repeat("a", 201)}, item.ErrQueryTooLong},
+			{"sort is known", item.SearchItemsInput{Query: "a", Sort: "type_id"}, item.ErrInvalidSort},
+			{"limit is bounded", item.SearchItemsInput{Query: "a", Limit: 101}, item.ErrInvalidLimit},
+			{"period is ordered", item.SearchItemsInput{Query: "a", CreatedFrom: from, CreatedTo: from}, item.ErrInvalidPeriod},
+			{"cursor is decodable", item.SearchItemsInput{Query: "a", Cursor: "!"}, item.ErrInvalidCursor},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := item.NewItemUsecase(&searcher{}, nil).SearchItems(ctx, &tt.input)
+				assert.ErrorIs(t, err, tt.err)
+			})
+		}
+	})
+}
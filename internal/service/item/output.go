@@ -0,0 +1,62 @@
+package item
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+)
+
+type ItemOutput struct {
+	ID          uuid.UUID `json:"id"`
+	TypeID      uint      `json:"type_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+}
+
+func NewItemOutput(item *domain.Item) *ItemOutput {
+	return &ItemOutput{
+		ID:          item.ID(),
+		TypeID:      item.TypeID(),
+		Name:        item.Name(),
+		Description: item.Description(),
+	}
+}
+
+type ListItemsOutput struct {
+	Items []*ItemOutput `json:"items"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+type ItemSearchResultOutput struct {
+	ID          uuid.UUID `json:"id"`
+	TypeID      uint      `json:"type_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	Score       float32   `json:"score"`
+	// NameHighlight and DescriptionHighlight are escaped HTML wrapping the matching words in <mark> and </mark>
+	NameHighlight        string `json:"name_highlight"`
+	DescriptionHighlight string `json:"description_highlight"`
+}
+
+func NewItemSearchResultOutput(result *domain.ItemSearchResult) *ItemSearchResultOutput {
+	return &ItemSearchResultOutput{
+		ID:                   result.Item.ID(),
+		TypeID:               result.Item.TypeID(),
+		Name:                 result.Item.Name(),
+		Description:          result.Item.Description(),
+		CreatedAt:            result.CreatedAt,
+		Score:                result.Score,
+		NameHighlight:        result.NameHighlight,
+		DescriptionHighlight: result.DescriptionHighlight,
+	}
+}
+
+type SearchItemsOutput struct {
+	Items []*ItemSearchResultOutput `json:"items"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
@@ -0,0 +1,23 @@
+package item
+
+import (
+	"context"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+)
+
+type ItemUsecase interface {
+	ListItems(ctx context.Context, input *ListItemsInput) (*ListItemsOutput, error)
+	SearchItems(ctx context.Context, input *SearchItemsInput) (*SearchItemsOutput, error)
+}
+
+type itemUsecase struct {
+	itemSearcher domain.ItemSearcher
+	itemLister   domain.ItemLister
+}
+
+// NewItemUsecase creates a new item usecase
+// Following DIP: depends on domain interface, not concrete implementation
+func NewItemUsecase(itemSearcher domain.ItemSearcher, itemLister domain.ItemLister) ItemUsecase {
+	return &itemUsecase{itemSearcher: itemSearcher, itemLister: itemLister}
+}
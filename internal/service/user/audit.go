@@ -0,0 +1,26 @@
+package user
+
+import (
+	"time"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+)
+
+const auditEntityType = "user"
+
+// auditSnapshot is the state of a user recorded in the audit log; the password is never recorded
+type auditSnapshot struct {
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Version   int32      `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func newAuditSnapshot(user *domain.User) *auditSnapshot {
+	return &auditSnapshot{
+		Name:      user.Name(),
+		Email:     user.Email(),
+		Version:   user.Version(),
+		DeletedAt: user.DeletedAt(),
+	}
+}
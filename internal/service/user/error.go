@@ -1,6 +1,10 @@
 package user
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+)
 
 var (
 	ErrNameIsRequired     = errors.New("name is required")
@@ -8,3 +12,9 @@ var (
 	ErrPasswordIsRequired = errors.New("password is required")
 	ErrPasswordTooShort   = errors.New("password must be at least 8 characters long")
 )
+
+// Errors of list queries are field errors, so that the response names the invalid query parameter
+var (
+	ErrInvalidCursor = &domain.FieldError{Field: "cursor", Text: "cursor is invalid"}
+	ErrInvalidLimit  = &domain.FieldError{Field: "limit", Text: "limit must be between 1 and 100"}
+)
@@ -1,5 +1,16 @@
 package user
 
+import (
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
 type CreateUserInput struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
@@ -25,3 +36,54 @@ func (i *CreateUserInput) validate() error {
 
 	return nil
 }
+
+type UpdateUserInput struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// Versions the client expects the user to be at one of, nil to update any version
+	Versions []int32 `json:"versions"`
+}
+
+func (i *UpdateUserInput) validate() error {
+	if i.Name == "" {
+		return ErrNameIsRequired
+	}
+
+	return nil
+}
+
+type DeleteUserInput struct {
+	ID uuid.UUID `json:"id"`
+	// Versions the client expects the user to be at one of, nil to delete any version
+	Versions []int32 `json:"versions"`
+}
+
+type ListUsersInput struct {
+	// Query holds the filters and sort, checked against domain.UserQuerySchema
+	Query domain.Query
+	// Cursor is the NextCursor of the previous page, with the same sort
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+func (i *ListUsersInput) validate() error {
+	if i.Limit == 0 {
+		i.Limit = defaultLimit
+	}
+	if i.Limit < 1 || i.Limit > maxLimit {
+		return ErrInvalidLimit
+	}
+
+	if len(i.Query.Sort) == 0 {
+		i.Query.Sort = domain.UserQuerySchema.DefaultSort
+	}
+
+	after, err := domain.UserQuerySchema.DecodeCursor(i.Query.Sort, i.Cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	i.Query.After = after
+	i.Query.Limit = i.Limit
+
+	return nil
+}
@@ -6,13 +6,21 @@ import (
 )
 
 type UserOutput struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Version int32     `json:"version"`
 }
 
 func NewUserOutput(user *domain.User) *UserOutput {
 	return &UserOutput{
-		ID:   user.ID(),
-		Name: user.Name(),
+		ID:      user.ID(),
+		Name:    user.Name(),
+		Version: user.Version(),
 	}
 }
+
+type ListUsersOutput struct {
+	Users []*UserOutput `json:"users"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
@@ -6,19 +6,37 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
 )
 
 type UserUsecase interface {
 	GetUser(ctx context.Context, id uuid.UUID) (*UserOutput, error)
+	ListUsers(ctx context.Context, input *ListUsersInput) (*ListUsersOutput, error)
 	CreateUser(ctx context.Context, input *CreateUserInput) (*UserOutput, error)
+	UpdateUser(ctx context.Context, input *UpdateUserInput) (*UserOutput, error)
+	DeleteUser(ctx context.Context, input *DeleteUserInput) error
 }
 
 type userUsecase struct {
+	tx             repository.Transaction
 	userRepository domain.UserRepository
+	userLister     domain.UserLister
+	auditRecorder  audit.Recorder
 }
 
 // NewUserUsecase creates a new user usecase
 // Following DIP: depends on domain interface, not concrete implementation
-func NewUserUsecase(userRepository domain.UserRepository) UserUsecase {
-	return &userUsecase{userRepository: userRepository}
+func NewUserUsecase(
+	tx repository.Transaction,
+	userRepository domain.UserRepository,
+	userLister domain.UserLister,
+	auditRecorder audit.Recorder,
+) UserUsecase {
+	return &userUsecase{
+		tx:             tx,
+		userRepository: userRepository,
+		userLister:     userLister,
+		auditRecorder:  auditRecorder,
+	}
 }
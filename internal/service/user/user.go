@@ -4,7 +4,9 @@ import (
 	"context"
 
 	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog"
 )
 
 func (u *userUsecase) GetUser(ctx context.Context, id uuid.UUID) (*UserOutput, error) {
@@ -16,20 +18,148 @@ func (u *userUsecase) GetUser(ctx context.Context, id uuid.UUID) (*UserOutput, e
 	return NewUserOutput(user), nil
 }
 
-func (u *userUsecase) CreateUser(ctx context.Context, input *CreateUserInput) (*UserOutput, error) {
+func (u *userUsecase) ListUsers(ctx context.Context, input *ListUsersInput) (*ListUsersOutput, error) {
 	if err := input.validate(); err != nil {
 		return nil, err
 	}
 
-	user, err := domain.NewUser(input.Name, input.Email, domain.Password(input.Password))
+	page, err := u.userLister.QueryUsers(ctx, input.Query)
 	if err != nil {
 		return nil, err
 	}
 
-	createdUser, err := u.userRepository.CreateUser(ctx, user)
+	output := &ListUsersOutput{Users: make([]*UserOutput, len(page.Items))}
+	for i, user := range page.Items {
+		output.Users[i] = NewUserOutput(user)
+	}
+	if page.Next != nil {
+		output.NextCursor = domain.UserQuerySchema.EncodeCursor(input.Query.Sort, page.Next)
+	}
+
+	return output, nil
+}
+
+func (u *userUsecase) CreateUser(ctx context.Context, input *CreateUserInput) (*UserOutput, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	var createdUser *domain.User
+	err := u.tx.Do(ctx, func(ctx context.Context) error {
+		// built in the transaction, so that a retry saves its events again
+		user, err := domain.NewUser(input.Name, input.Email, domain.Password(input.Password))
+		if err != nil {
+			return err
+		}
+
+		createdUser, err = u.userRepository.CreateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		return u.auditRecorder.Record(ctx, audit.Entry{
+			EntityType: auditEntityType,
+			EntityID:   createdUser.ID(),
+			Action:     audit.ActionCreate,
+			After:      newAuditSnapshot(createdUser),
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	zerolog.Ctx(ctx).Info().Str("user_id", createdUser.ID().String()).Msg("user created")
+
 	return NewUserOutput(createdUser), nil
 }
+
+func (u *userUsecase) UpdateUser(ctx context.Context, input *UpdateUserInput) (*UserOutput, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	var updatedUser *domain.User
+	err := u.tx.Do(ctx, func(ctx context.Context) error {
+		user, err := u.userRepository.GetUser(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersions(user, input.Versions); err != nil {
+			return err
+		}
+
+		before := newAuditSnapshot(user)
+		user.SetName(input.Name)
+
+		updatedUser, err = u.userRepository.UpdateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		return u.auditRecorder.Record(ctx, audit.Entry{
+			EntityType: auditEntityType,
+			EntityID:   updatedUser.ID(),
+			Action:     audit.ActionUpdate,
+			Before:     before,
+			After:      newAuditSnapshot(updatedUser),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Info().Str("user_id", updatedUser.ID().String()).Int32("version", updatedUser.Version()).Msg("user updated")
+
+	return NewUserOutput(updatedUser), nil
+}
+
+func (u *userUsecase) DeleteUser(ctx context.Context, input *DeleteUserInput) error {
+	err := u.tx.Do(ctx, func(ctx context.Context) error {
+		user, err := u.userRepository.GetUser(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersions(user, input.Versions); err != nil {
+			return err
+		}
+
+		before := newAuditSnapshot(user)
+		user.Delete()
+
+		if err := u.userRepository.DeleteUser(ctx, user); err != nil {
+			return err
+		}
+
+		return u.auditRecorder.Record(ctx, audit.Entry{
+			EntityType: auditEntityType,
+			EntityID:   user.ID(),
+			Action:     audit.ActionDelete,
+			Before:     before,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	zerolog.Ctx(ctx).Info().Str("user_id", input.ID.String()).Msg("user deleted")
+
+	return nil
+}
+
+// checkVersions returns a VersionConflictError unless the user is at one of the versions; nil matches any version
+func checkVersions(user *domain.User, versions []int32) error {
+	if versions == nil {
+		return nil
+	}
+
+	var err error
+	for _, v := range versions {
+		if err = user.CheckVersion(v); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,202 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository/memory"
+	"github.com/SoraDaibu/go-clean-starter/internal/service/user"
+)
+
+// recorder records entries in memory and fails with err when set
+type recorder struct {
+	entries []audit.Entry
+	err     error
+}
+
+func (r *recorder) Record(_ context.Context, entry audit.Entry) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// lister pages through users created a second apart, newest first, recording the queries it got
+type lister struct {
+	users   []*domain.User
+	queries []domain.Query
+}
+
+func (l *lister) createdAt(i int) time.Time {
+	return time.Date(2025, 1, 1, 0, 0, len(l.users)-i, 0, time.UTC)
+}
+
+func (l *lister) QueryUsers(_ context.Context, q domain.Query) (*domain.Page[domain.User], error) {
+	l.queries = append(l.queries, q)
+
+	start := 0
+	if q.After != nil {
+		for i, u := range l.users {
+			if u.ID() == q.After[len(q.After)-1] {
+				start = i + 1
+			}
+		}
+	}
+	end := min(start+q.Limit, len(l.users))
+
+	page := &domain.Page[domain.User]{Items: l.users[start:end]}
+	if end < len(l.users) {
+		page.Next = []any{l.createdAt(end - 1), l.users[end-1].ID()}
+	}
+	return page, nil
+}
+
+func newUsecase() (user.UserUsecase, *memory.DB, *recorder) {
+	db := memory.NewDB()
+	rec := &recorder{}
+	return user.NewUserUsecase(memory.NewTransaction(db), memory.NewUserRepository(db), &lister{}, rec), db, rec
+}
+
+func TestUserUsecase_ListUsers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pages with cursors", func(t *testing.T) {
+		l := &lister{}
+		for range 5 {
+			l.users = append(l.users, domain.UserFromSource(uuid.New(), "user", "user@example.com", 1, nil))
+		}
+		uu := user.NewUserUsecase(nil, nil, l, nil)
+
+		var ids []uuid.UUID
+		input := &user.ListUsersInput{Limit: 2}
+		for page := 1; ; page++ {
+			output, err := uu.ListUsers(ctx, input)
+			require.NoError(t, err)
+			for _, u := range output.Users {
+				ids = append(ids, u.ID)
+			}
+			if output.NextCursor == "" {
+				assert.Equal(t, 3, page)
+				break
+			}
+			input = &user.ListUsersInput{Limit: 2, Cursor: output.NextCursor}
+		}
+
+		require.Len(t, ids, 5)
+		for i, u := range l.users {
+			assert.Equal(t, u.ID(), ids[i])
+		}
+
+		// the lister gets the default sort and the values of the last user of the previous page
+		assert.Equal(t, domain.UserQuerySchema.DefaultSort, l.queries[1].Sort)
+		assert.Equal(t, 2, l.queries[1].Limit)
+		assert.Equal(t, []any{l.createdAt(1), l.users[1].ID()}, l.queries[1].After)
+	})
+
+	t.Run("validates the input", func(t *testing.T) {
+		l := &lister{users: []*domain.User{domain.UserFromSource(uuid.New(), "a", "a@example.com", 1, nil), domain.UserFromSource(uuid.New(), "b", "b@example.com", 1, nil)}}
+		uu := user.NewUserUsecase(nil, nil, l, nil)
+		output, err := uu.ListUsers(ctx, &user.ListUsersInput{Limit: 1})
+		require.NoError(t, err)
+		require.NotEmpty(t, output.NextCursor)
+
+		byName := domain.Query{Sort: []domain.SortField{{Field: "name"}}}
+		tests := []struct {
+			name  string
+			input user.ListUsersInput
+			err   error
+			field string
+		}{
+			{"limit is bounded", user.ListUsersInput{Limit: 101}, user.ErrInvalidLimit, "limit"},
+			{"cursor is decodable", user.ListUsersInput{Cursor: "!"}, user.ErrInvalidCursor, "cursor"},
+			{"cursor is only valid with its sort", user.ListUsersInput{Query: byName, Cursor: output.NextCursor}, user.ErrInvalidCursor, "cursor"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := uu.ListUsers(ctx, &tt.input)
+				assert.ErrorIs(t, err, tt.err)
+				// the response names the invalid query parameter
+				var invalid *domain.FieldError
+				require.ErrorAs(t, err, &invalid)
+				assert.Equal(t, tt.field, invalid.Field)
+			})
+		}
+	})
+}
+
+func TestUserUsecase_CreateUser(t *testing.T) {
+	ctx := context.Background()
+	input := &user.CreateUserInput{Name: "John Doe", Email: "john@example.com", Password: "password123"}
+
+	t.Run("records the creation and its event", func(t *testing.T) {
+		uu, db, rec := newUsecase()
+
+		created, err := uu.CreateUser(ctx, input)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), created.Version)
+
+		require.Len(t, rec.entries, 1)
+		assert.Equal(t, audit.ActionCreate, rec.entries[0].Action)
+		require.Len(t, db.Events(), 1)
+		assert.Equal(t, domain.EventUserCreated, db.Events()[0].Type)
+	})
+
+	t.Run("rolls back when recording fails", func(t *testing.T) {
+		uu, db, rec := newUsecase()
+		rec.err = errors.New("audit is down")
+
+		_, err := uu.CreateUser(ctx, input)
+		require.ErrorIs(t, err, rec.err)
+		assert.Empty(t, db.Events())
+
+		// the email is still free
+		rec.err = nil
+		_, err = uu.CreateUser(ctx, input)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects duplicate emails", func(t *testing.T) {
+		uu, _, _ := newUsecase()
+
+		_, err := uu.CreateUser(ctx, input)
+		require.NoError(t, err)
+		_, err = uu.CreateUser(ctx, input)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
+
+func TestUserUsecase_UpdateUser(t *testing.T) {
+	ctx := context.Background()
+	uu, _, _ := newUsecase()
+
+	created, err := uu.CreateUser(ctx, &user.CreateUserInput{Name: "John Doe", Email: "john@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	updated, err := uu.UpdateUser(ctx, &user.UpdateUserInput{ID: created.ID, Name: "Jane Doe", Versions: []int32{created.Version}})
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", updated.Name)
+	assert.Equal(t, int32(2), updated.Version)
+
+	// created.Version is stale now
+	_, err = uu.UpdateUser(ctx, &user.UpdateUserInput{ID: created.ID, Name: "Jim Doe", Versions: []int32{created.Version}})
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+	// any of the versions may match
+	updated, err = uu.UpdateUser(ctx, &user.UpdateUserInput{ID: created.ID, Name: "Jill Doe", Versions: []int32{created.Version, updated.Version}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), updated.Version)
+
+	got, err := uu.GetUser(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Jill Doe", got.Name)
+}
@@ -0,0 +1,15 @@
+package webhook
+
+import "github.com/SoraDaibu/go-clean-starter/domain"
+
+var (
+	ErrURLRequired      = &domain.FieldError{Field: "url", Text: "url is required"}
+	ErrInvalidURL       = &domain.FieldError{Field: "url", Text: "url must be an absolute http or https URL"}
+	ErrPrivateURL       = &domain.FieldError{Field: "url", Text: "url must not point to a loopback, private or link-local address"}
+	ErrInvalidEventType = &domain.FieldError{Field: "event_types", Text: "event_types contains an unknown event type"}
+	ErrSecretTooShort   = &domain.FieldError{Field: "secret", Text: "secret must be at least 16 characters long"}
+	ErrNothingToUpdate  = &domain.FieldError{Text: "url, event_types or enabled is required"}
+	ErrInvalidStatus    = &domain.FieldError{Field: "status", Text: "status must be pending, succeeded or failed"}
+	ErrInvalidCursor    = &domain.FieldError{Field: "cursor", Text: "cursor is invalid"}
+	ErrInvalidLimit     = &domain.FieldError{Field: "limit", Text: "limit must be between 1 and 100"}
+)
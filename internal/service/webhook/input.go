@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"encoding/base64"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/httpclient"
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+)
+
+const (
+	defaultLimit   = 50
+	maxLimit       = 100
+	minSecretBytes = 16
+)
+
+type CreateSubscriptionInput struct {
+	URL string `json:"url"`
+	// EventTypes are event types such as user.created, aggregate wildcards such as user.* or *; empty sends every event
+	EventTypes []string `json:"event_types"`
+	// Secret is generated when empty
+	Secret string `json:"secret"`
+}
+
+func (i *CreateSubscriptionInput) validate(allowPrivateNetworks bool) error {
+	if err := validateURL(i.URL, allowPrivateNetworks); err != nil {
+		return err
+	}
+
+	if err := validateEventTypes(i.EventTypes); err != nil {
+		return err
+	}
+
+	if i.Secret != "" && len(i.Secret) < minSecretBytes {
+		return ErrSecretTooShort
+	}
+
+	return nil
+}
+
+// UpdateSubscriptionInput changes the non-nil fields
+type UpdateSubscriptionInput struct {
+	ID         uuid.UUID `json:"id"`
+	URL        *string   `json:"url"`
+	EventTypes *[]string `json:"event_types"`
+	// Enabled re-enables a subscription disabled after repeated failures, or pauses deliveries
+	Enabled *bool `json:"enabled"`
+}
+
+func (i *UpdateSubscriptionInput) validate(allowPrivateNetworks bool) error {
+	if i.URL == nil && i.EventTypes == nil && i.Enabled == nil {
+		return ErrNothingToUpdate
+	}
+
+	if i.URL != nil {
+		if err := validateURL(*i.URL, allowPrivateNetworks); err != nil {
+			return err
+		}
+	}
+
+	if i.EventTypes != nil {
+		if err := validateEventTypes(*i.EventTypes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type ListDeliveriesInput struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	Status         string    `json:"status"`
+	// Cursor is the NextCursor of the previous page
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+func (i *ListDeliveriesInput) validate() error {
+	if i.Limit == 0 {
+		i.Limit = defaultLimit
+	}
+	if i.Limit < 1 || i.Limit > maxLimit {
+		return ErrInvalidLimit
+	}
+
+	switch webhook.DeliveryStatus(i.Status) {
+	case "", webhook.DeliveryPending, webhook.DeliverySucceeded, webhook.DeliveryFailed:
+	default:
+		return ErrInvalidStatus
+	}
+
+	if _, err := decodeCursor(i.Cursor); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateURL rejects URLs naming a private address unless allowPrivateNetworks is set.
+// Host names are only checked when connecting, by the client of the dispatcher, as they may resolve differently then.
+func validateURL(v string, allowPrivateNetworks bool) error {
+	if v == "" {
+		return ErrURLRequired
+	}
+
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+
+	if allowPrivateNetworks {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateURL
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && httpclient.IsPrivate(addr) {
+		return ErrPrivateURL
+	}
+
+	return nil
+}
+
+// validateEventTypes accepts known event types, aggregate wildcards such as user.* and *
+func validateEventTypes(eventTypes []string) error {
+	for _, t := range eventTypes {
+		if t == "*" || slices.Contains(domain.EventTypes, t) {
+			continue
+		}
+
+		prefix, ok := strings.CutSuffix(t, ".*")
+		if !ok || !slices.ContainsFunc(domain.EventTypes, func(e string) bool { return strings.HasPrefix(e, prefix+".") }) {
+			return ErrInvalidEventType
+		}
+	}
+
+	return nil
+}
+
+// encodeCursor makes the ID of the last delivery of a page opaque to clients
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return id, nil
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+)
+
+type SubscriptionOutput struct {
+	ID                  uuid.UUID  `json:"id"`
+	URL                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func NewSubscriptionOutput(subscription *webhook.Subscription) *SubscriptionOutput {
+	eventTypes := subscription.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	return &SubscriptionOutput{
+		ID:                  subscription.ID,
+		URL:                 subscription.URL,
+		EventTypes:          eventTypes,
+		Enabled:             subscription.Enabled,
+		ConsecutiveFailures: subscription.ConsecutiveFailures,
+		DisabledAt:          subscription.DisabledAt,
+		CreatedAt:           subscription.CreatedAt,
+		UpdatedAt:           subscription.UpdatedAt,
+	}
+}
+
+// CreateSubscriptionOutput is the only output carrying the secret
+type CreateSubscriptionOutput struct {
+	*SubscriptionOutput
+	Secret string `json:"secret"`
+}
+
+type DeliveryOutput struct {
+	ID             int64      `json:"id"`
+	SubscriptionID uuid.UUID  `json:"subscription_id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`
+	ResponseCode   *int       `json:"response_code"`
+	LastError      string     `json:"last_error"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func NewDeliveryOutput(delivery *webhook.Delivery) *DeliveryOutput {
+	output := &DeliveryOutput{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseCode:   delivery.ResponseCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	// only pending deliveries have a next attempt
+	if delivery.Status == webhook.DeliveryPending {
+		output.NextAttemptAt = &delivery.NextAttemptAt
+	}
+
+	return output
+}
+
+type ListDeliveriesOutput struct {
+	Deliveries []*DeliveryOutput `json:"deliveries"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+)
+
+type WebhookUsecase interface {
+	CreateSubscription(ctx context.Context, input *CreateSubscriptionInput) (*CreateSubscriptionOutput, error)
+	GetSubscription(ctx context.Context, id uuid.UUID) (*SubscriptionOutput, error)
+	ListSubscriptions(ctx context.Context) ([]*SubscriptionOutput, error)
+	UpdateSubscription(ctx context.Context, input *UpdateSubscriptionInput) (*SubscriptionOutput, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, input *ListDeliveriesInput) (*ListDeliveriesOutput, error)
+}
+
+type webhookUsecase struct {
+	webhookRepository webhook.Repository
+	// allowPrivateNetworks accepts URLs of loopback, private and link-local addresses, for development
+	allowPrivateNetworks bool
+}
+
+// NewWebhookUsecase creates a new webhook usecase
+// Following DIP: depends on the repository interface, not concrete implementation
+func NewWebhookUsecase(webhookRepository webhook.Repository, allowPrivateNetworks bool) WebhookUsecase {
+	return &webhookUsecase{webhookRepository: webhookRepository, allowPrivateNetworks: allowPrivateNetworks}
+}
@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+)
+
+// secretPrefix marks generated secrets so that they are easy to spot in configs and logs
+const secretPrefix = "whsec_"
+
+func (u *webhookUsecase) CreateSubscription(ctx context.Context, input *CreateSubscriptionInput) (*CreateSubscriptionOutput, error) {
+	if err := input.validate(u.allowPrivateNetworks); err != nil {
+		return nil, err
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	subscription, err := u.webhookRepository.CreateSubscription(ctx, &webhook.Subscription{
+		ID:         uuid.New(),
+		URL:        input.URL,
+		EventTypes: input.EventTypes,
+		Secret:     secret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Info().Str("subscription_id", subscription.ID.String()).Strs("event_types", subscription.EventTypes).Msg("webhook subscription created")
+
+	return &CreateSubscriptionOutput{
+		SubscriptionOutput: NewSubscriptionOutput(subscription),
+		Secret:             subscription.Secret,
+	}, nil
+}
+
+func (u *webhookUsecase) GetSubscription(ctx context.Context, id uuid.UUID) (*SubscriptionOutput, error) {
+	subscription, err := u.webhookRepository.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSubscriptionOutput(subscription), nil
+}
+
+func (u *webhookUsecase) ListSubscriptions(ctx context.Context) ([]*SubscriptionOutput, error) {
+	subscriptions, err := u.webhookRepository.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]*SubscriptionOutput, len(subscriptions))
+	for i, subscription := range subscriptions {
+		outputs[i] = NewSubscriptionOutput(subscription)
+	}
+
+	return outputs, nil
+}
+
+func (u *webhookUsecase) UpdateSubscription(ctx context.Context, input *UpdateSubscriptionInput) (*SubscriptionOutput, error) {
+	if err := input.validate(u.allowPrivateNetworks); err != nil {
+		return nil, err
+	}
+
+	subscription, err := u.webhookRepository.GetSubscription(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.URL != nil {
+		subscription.URL = *input.URL
+	}
+	if input.EventTypes != nil {
+		subscription.EventTypes = *input.EventTypes
+	}
+	if input.Enabled != nil && *input.Enabled != subscription.Enabled {
+		subscription.Enabled = *input.Enabled
+		// give re-enabled subscriptions a fresh failure budget
+		subscription.ConsecutiveFailures = 0
+		subscription.DisabledAt = nil
+	}
+
+	updated, err := u.webhookRepository.UpdateSubscription(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Info().Str("subscription_id", updated.ID.String()).Bool("enabled", updated.Enabled).Msg("webhook subscription updated")
+
+	return NewSubscriptionOutput(updated), nil
+}
+
+func (u *webhookUsecase) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	if err := u.webhookRepository.DeleteSubscription(ctx, id); err != nil {
+		return err
+	}
+
+	zerolog.Ctx(ctx).Info().Str("subscription_id", id.String()).Msg("webhook subscription deleted")
+
+	return nil
+}
+
+func (u *webhookUsecase) ListDeliveries(ctx context.Context, input *ListDeliveriesInput) (*ListDeliveriesOutput, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	afterID, err := decodeCursor(input.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// 404 rather than an empty page for unknown subscriptions
+	if _, err := u.webhookRepository.GetSubscription(ctx, input.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	// fetch one more delivery to know whether there is a next page
+	deliveries, err := u.webhookRepository.ListDeliveries(ctx, webhook.DeliveryFilter{
+		SubscriptionID: input.SubscriptionID,
+		Status:         webhook.DeliveryStatus(input.Status),
+		AfterID:        afterID,
+		Limit:          input.Limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ListDeliveriesOutput{Deliveries: []*DeliveryOutput{}}
+	if len(deliveries) > input.Limit {
+		deliveries = deliveries[:input.Limit]
+		output.NextCursor = encodeCursor(deliveries[len(deliveries)-1].ID)
+	}
+
+	for _, delivery := range deliveries {
+		output.Deliveries = append(output.Deliveries, NewDeliveryOutput(delivery))
+	}
+
+	return output, nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return secretPrefix + hex.EncodeToString(b), nil
+}
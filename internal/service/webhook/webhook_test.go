@@ -0,0 +1,262 @@
+package webhook_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	service "github.com/SoraDaibu/go-clean-starter/internal/service/webhook"
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+)
+
+// fakeRepository keeps subscriptions and deliveries in memory; only what the use case uses is implemented
+type fakeRepository struct {
+	webhook.Repository
+	subscriptions map[uuid.UUID]*webhook.Subscription
+	deliveries    []*webhook.Delivery
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{subscriptions: map[uuid.UUID]*webhook.Subscription{}}
+}
+
+func (r *fakeRepository) CreateSubscription(_ context.Context, subscription *webhook.Subscription) (*webhook.Subscription, error) {
+	created := *subscription
+	created.Enabled = true
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	r.subscriptions[created.ID] = &created
+
+	copied := created
+	return &copied, nil
+}
+
+func (r *fakeRepository) GetSubscription(_ context.Context, id uuid.UUID) (*webhook.Subscription, error) {
+	s, ok := r.subscriptions[id]
+	if !ok {
+		return nil, webhook.ErrSubscriptionNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (r *fakeRepository) ListSubscriptions(ctx context.Context) ([]*webhook.Subscription, error) {
+	var subscriptions []*webhook.Subscription
+	for id := range r.subscriptions {
+		s, _ := r.GetSubscription(ctx, id)
+		subscriptions = append(subscriptions, s)
+	}
+	return subscriptions, nil
+}
+
+func (r *fakeRepository) UpdateSubscription(ctx context.Context, subscription *webhook.Subscription) (*webhook.Subscription, error) {
+	if _, ok := r.subscriptions[subscription.ID]; !ok {
+		return nil, webhook.ErrSubscriptionNotFound
+	}
+	updated := *subscription
+	r.subscriptions[updated.ID] = &updated
+	return r.GetSubscription(ctx, updated.ID)
+}
+
+func (r *fakeRepository) DeleteSubscription(_ context.Context, id uuid.UUID) error {
+	if _, ok := r.subscriptions[id]; !ok {
+		return webhook.ErrSubscriptionNotFound
+	}
+	delete(r.subscriptions, id)
+	return nil
+}
+
+func (r *fakeRepository) ListDeliveries(_ context.Context, filter webhook.DeliveryFilter) ([]*webhook.Delivery, error) {
+	var deliveries []*webhook.Delivery
+	// newest first, like ORDER BY id DESC
+	for _, d := range slices.Backward(r.deliveries) {
+		if d.SubscriptionID != filter.SubscriptionID ||
+			(filter.Status != "" && d.Status != filter.Status) ||
+			(filter.AfterID != 0 && d.ID >= filter.AfterID) {
+			continue
+		}
+		deliveries = append(deliveries, d)
+		if len(deliveries) == filter.Limit {
+			break
+		}
+	}
+	return deliveries, nil
+}
+
+func TestWebhookUsecase_CreateSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("generates a secret", func(t *testing.T) {
+		repo := newFakeRepository()
+		uc := service.NewWebhookUsecase(repo, false)
+
+		output, err := uc.CreateSubscription(ctx, &service.CreateSubscriptionInput{
+			URL:        "https://partner.example.com/hooks",
+			EventTypes: []string{"user.*"},
+		})
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(output.Secret, "whsec_"), output.Secret)
+		assert.Equal(t, []string{"user.*"}, output.EventTypes)
+		assert.True(t, output.Enabled)
+		assert.Equal(t, output.Secret, repo.subscriptions[output.ID].Secret)
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			input    service.CreateSubscriptionInput
+			expected error
+		}{
+			{name: "missing url", input: service.CreateSubscriptionInput{}, expected: service.ErrURLRequired},
+			{name: "relative url", input: service.CreateSubscriptionInput{URL: "/hooks"}, expected: service.ErrInvalidURL},
+			{name: "other scheme", input: service.CreateSubscriptionInput{URL: "ftp://partner.example.com"}, expected: service.ErrInvalidURL},
+			{name: "loopback", input: service.CreateSubscriptionInput{URL: "http://127.0.0.1:8080/hooks"}, expected: service.ErrPrivateURL},
+			{name: "localhost", input: service.CreateSubscriptionInput{URL: "http://localhost/hooks"}, expected: service.ErrPrivateURL},
+			{name: "private", input: service.CreateSubscriptionInput{URL: "http://10.0.0.5/hooks"}, expected: service.ErrPrivateURL},
+			{name: "cloud metadata", input: service.CreateSubscriptionInput{URL: "http://169.254.169.254/latest"}, expected: service.ErrPrivateURL},
+			{name: "ipv6 loopback", input: service.CreateSubscriptionInput{URL: "http://[::1]/hooks"}, expected: service.ErrPrivateURL},
+			{
+				name:     "unknown event type",
+				input:    service.CreateSubscriptionInput{URL: "https://partner.example.com", EventTypes: []string{"order.*"}},
+				expected: service.ErrInvalidEventType,
+			},
+			{
+				name:     "short secret",
+				input:    service.CreateSubscriptionInput{URL: "https://partner.example.com", Secret: "short"},
+				expected: service.ErrSecretTooShort,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := newFakeRepository()
+				_, err := service.NewWebhookUsecase(repo, false).CreateSubscription(ctx, &tt.input)
+				require.ErrorIs(t, err, tt.expected)
+				assert.Empty(t, repo.subscriptions)
+			})
+		}
+	})
+
+	t.Run("accepts private URLs when allowed", func(t *testing.T) {
+		output, err := service.NewWebhookUsecase(newFakeRepository(), true).CreateSubscription(ctx, &service.CreateSubscriptionInput{
+			URL: "http://localhost:9000/hooks",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:9000/hooks", output.URL)
+	})
+}
+
+func TestWebhookUsecase_UpdateSubscription(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	uc := service.NewWebhookUsecase(repo, false)
+
+	created, err := uc.CreateSubscription(ctx, &service.CreateSubscriptionInput{URL: "https://partner.example.com/hooks"})
+	require.NoError(t, err)
+
+	t.Run("re-enabling resets failures", func(t *testing.T) {
+		disabledAt := time.Now()
+		repo.subscriptions[created.ID].Enabled = false
+		repo.subscriptions[created.ID].ConsecutiveFailures = 50
+		repo.subscriptions[created.ID].DisabledAt = &disabledAt
+
+		enabled := true
+		output, err := uc.UpdateSubscription(ctx, &service.UpdateSubscriptionInput{ID: created.ID, Enabled: &enabled})
+		require.NoError(t, err)
+
+		assert.True(t, output.Enabled)
+		assert.Zero(t, output.ConsecutiveFailures)
+		assert.Nil(t, output.DisabledAt)
+	})
+
+	t.Run("rejects a private URL", func(t *testing.T) {
+		url := "http://192.168.0.10/hooks"
+		_, err := uc.UpdateSubscription(ctx, &service.UpdateSubscriptionInput{ID: created.ID, URL: &url})
+		require.ErrorIs(t, err, service.ErrPrivateURL)
+		assert.Equal(t, "https://partner.example.com/hooks", repo.subscriptions[created.ID].URL)
+	})
+
+	t.Run("requires a change", func(t *testing.T) {
+		_, err := uc.UpdateSubscription(ctx, &service.UpdateSubscriptionInput{ID: created.ID})
+		require.ErrorIs(t, err, service.ErrNothingToUpdate)
+	})
+
+	t.Run("unknown subscription", func(t *testing.T) {
+		enabled := false
+		_, err := uc.UpdateSubscription(ctx, &service.UpdateSubscriptionInput{ID: uuid.New(), Enabled: &enabled})
+		require.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestWebhookUsecase_ListDeliveries(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	uc := service.NewWebhookUsecase(repo, false)
+
+	created, err := uc.CreateSubscription(ctx, &service.CreateSubscriptionInput{URL: "https://partner.example.com/hooks"})
+	require.NoError(t, err)
+	for i := 1; i <= 5; i++ {
+		status := webhook.DeliverySucceeded
+		if i == 5 {
+			status = webhook.DeliveryPending
+		}
+		repo.deliveries = append(repo.deliveries, &webhook.Delivery{
+			ID:             int64(i),
+			SubscriptionID: created.ID,
+			EventID:        int64(100 + i),
+			EventType:      "user.created",
+			Status:         status,
+			NextAttemptAt:  time.Now(),
+		})
+	}
+
+	t.Run("pages with cursors", func(t *testing.T) {
+		var ids []int64
+		input := &service.ListDeliveriesInput{SubscriptionID: created.ID, Limit: 2}
+		for range 3 {
+			output, err := uc.ListDeliveries(ctx, input)
+			require.NoError(t, err)
+			for _, d := range output.Deliveries {
+				ids = append(ids, d.ID)
+			}
+			if output.NextCursor == "" {
+				break
+			}
+			input.Cursor = output.NextCursor
+		}
+
+		assert.Equal(t, []int64{5, 4, 3, 2, 1}, ids)
+	})
+
+	t.Run("filters by status", func(t *testing.T) {
+		output, err := uc.ListDeliveries(ctx, &service.ListDeliveriesInput{SubscriptionID: created.ID, Status: "pending"})
+		require.NoError(t, err)
+		require.Len(t, output.Deliveries, 1)
+		assert.Equal(t, int64(5), output.Deliveries[0].ID)
+		assert.NotNil(t, output.Deliveries[0].NextAttemptAt)
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		_, err := uc.ListDeliveries(ctx, &service.ListDeliveriesInput{SubscriptionID: created.ID, Status: "sent"})
+		require.ErrorIs(t, err, service.ErrInvalidStatus)
+
+		_, err = uc.ListDeliveries(ctx, &service.ListDeliveriesInput{SubscriptionID: created.ID, Cursor: "???"})
+		require.ErrorIs(t, err, service.ErrInvalidCursor)
+
+		_, err = uc.ListDeliveries(ctx, &service.ListDeliveriesInput{SubscriptionID: created.ID, Limit: 101})
+		require.ErrorIs(t, err, service.ErrInvalidLimit)
+	})
+
+	t.Run("unknown subscription", func(t *testing.T) {
+		_, err := uc.ListDeliveries(ctx, &service.ListDeliveriesInput{SubscriptionID: uuid.New()})
+		require.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
@@ -12,39 +12,62 @@ import (
 )
 
 const createItem = `-- name: CreateItem :one
-INSERT INTO items (id, type_id)
-VALUES ($1, $2)
-RETURNING id, type_id, created_at, updated_at
+INSERT INTO items (id, type_id, name, description)
+VALUES ($1, $2, $3, $4)
+RETURNING id, type_id, created_at, updated_at, version, deleted_at, name, description, search
 `
 
 type CreateItemParams struct {
-	ID     pgtype.UUID
-	TypeID *int32
+	ID          pgtype.UUID
+	TypeID      *int32
+	Name        string
+	Description string
 }
 
 func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, error) {
-	row := q.db.QueryRow(ctx, createItem, arg.ID, arg.TypeID)
+	row := q.db.QueryRow(ctx, createItem,
+		arg.ID,
+		arg.TypeID,
+		arg.Name,
+		arg.Description,
+	)
 	var i Item
 	err := row.Scan(
 		&i.ID,
 		&i.TypeID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Name,
+		&i.Description,
+		&i.Search,
 	)
 	return i, err
 }
 
-const deleteItem = `-- name: DeleteItem :exec
-DELETE FROM items WHERE id = $1
+const deleteItem = `-- name: DeleteItem :execrows
+UPDATE items
+SET deleted_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = $1 AND version = $2 AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteItem(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteItem, id)
-	return err
+type DeleteItemParams struct {
+	ID      pgtype.UUID
+	Version int32
+}
+
+// DeleteItem soft deletes the row while it still has the expected version
+func (q *Queries) DeleteItem(ctx context.Context, arg DeleteItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteItem, arg.ID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getItem = `-- name: GetItem :one
-SELECT id, type_id, created_at, updated_at FROM items WHERE id = $1 LIMIT 1
+SELECT id, type_id, created_at, updated_at, version, deleted_at, name, description, search FROM items WHERE id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetItem(ctx context.Context, id pgtype.UUID) (Item, error) {
@@ -55,12 +78,51 @@ func (q *Queries) GetItem(ctx context.Context, id pgtype.UUID) (Item, error) {
 		&i.TypeID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Name,
+		&i.Description,
+		&i.Search,
 	)
 	return i, err
 }
 
+const listDeletedItems = `-- name: ListDeletedItems :many
+SELECT id, type_id, created_at, updated_at, version, deleted_at, name, description, search FROM items WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC
+`
+
+func (q *Queries) ListDeletedItems(ctx context.Context) ([]Item, error) {
+	rows, err := q.db.Query(ctx, listDeletedItems)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Item
+	for rows.Next() {
+		var i Item
+		if err := rows.Scan(
+			&i.ID,
+			&i.TypeID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Name,
+			&i.Description,
+			&i.Search,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listItems = `-- name: ListItems :many
-SELECT id, type_id, created_at, updated_at FROM items ORDER BY created_at DESC
+SELECT id, type_id, created_at, updated_at, version, deleted_at, name, description, search FROM items WHERE deleted_at IS NULL ORDER BY created_at DESC
 `
 
 func (q *Queries) ListItems(ctx context.Context) ([]Item, error) {
@@ -77,6 +139,11 @@ func (q *Queries) ListItems(ctx context.Context) ([]Item, error) {
 			&i.TypeID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Name,
+			&i.Description,
+			&i.Search,
 		); err != nil {
 			return nil, err
 		}
@@ -88,26 +155,70 @@ func (q *Queries) ListItems(ctx context.Context) ([]Item, error) {
 	return items, nil
 }
 
+const purgeDeletedItems = `-- name: PurgeDeletedItems :execrows
+DELETE FROM items WHERE deleted_at < $1
+`
+
+// PurgeDeletedItems hard deletes items soft deleted before the given time
+func (q *Queries) PurgeDeletedItems(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedItems, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreItem = `-- name: RestoreItem :one
+UPDATE items
+SET deleted_at = NULL, version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, type_id, created_at, updated_at, version, deleted_at, name, description, search
+`
+
+func (q *Queries) RestoreItem(ctx context.Context, id pgtype.UUID) (Item, error) {
+	row := q.db.QueryRow(ctx, restoreItem, id)
+	var i Item
+	err := row.Scan(
+		&i.ID,
+		&i.TypeID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Name,
+		&i.Description,
+		&i.Search,
+	)
+	return i, err
+}
+
 const updateItem = `-- name: UpdateItem :one
 UPDATE items
-SET type_id = $2
-WHERE id = $1
-RETURNING id, type_id, created_at, updated_at
+SET type_id = $2, version = version + 1
+WHERE id = $1 AND version = $3 AND deleted_at IS NULL
+RETURNING id, type_id, created_at, updated_at, version, deleted_at, name, description, search
 `
 
 type UpdateItemParams struct {
-	ID     pgtype.UUID
-	TypeID *int32
+	ID      pgtype.UUID
+	TypeID  *int32
+	Version int32
 }
 
+// UpdateItem only matches the row while it still has the expected version
 func (q *Queries) UpdateItem(ctx context.Context, arg UpdateItemParams) (Item, error) {
-	row := q.db.QueryRow(ctx, updateItem, arg.ID, arg.TypeID)
+	row := q.db.QueryRow(ctx, updateItem, arg.ID, arg.TypeID, arg.Version)
 	var i Item
 	err := row.Scan(
 		&i.ID,
 		&i.TypeID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Name,
+		&i.Description,
+		&i.Search,
 	)
 	return i, err
 }
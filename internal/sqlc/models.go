@@ -8,12 +8,40 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// This table stores who changed which entity, and how
+type AuditEvent struct {
+	ID         int64
+	Actor      string
+	EntityType string
+	EntityID   pgtype.UUID
+	Action     string
+	Diff       []byte
+	CreatedAt  pgtype.Timestamptz
+}
+
+// This table stores responses of requests sent with an Idempotency-Key header to replay them on retries
+type IdempotencyKey struct {
+	Key             string
+	Fingerprint     string
+	StatusCode      *int32
+	ResponseHeaders []byte
+	ResponseBody    []byte
+	CreatedAt       pgtype.Timestamptz
+	ExpiresAt       pgtype.Timestamptz
+	LockedUntil     pgtype.Timestamptz
+}
+
 // This table is used to store master items
 type Item struct {
-	ID        pgtype.UUID
-	TypeID    *int32
-	CreatedAt pgtype.Timestamptz
-	UpdatedAt pgtype.Timestamptz
+	ID          pgtype.UUID
+	TypeID      *int32
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	Version     int32
+	DeletedAt   pgtype.Timestamptz
+	Name        string
+	Description string
+	Search      string
 }
 
 // This table is used to store item types
@@ -25,6 +53,28 @@ type ItemType struct {
 	UpdatedAt   pgtype.Timestamptz
 }
 
+// This table stores domain events until the relay publishes them
+type Outbox struct {
+	ID             int64
+	AggregateType  string
+	AggregateID    pgtype.UUID
+	EventType      string
+	Payload        []byte
+	OccurredAt     pgtype.Timestamptz
+	PublishedAt    pgtype.Timestamptz
+	Attempts       int32
+	LastError      *string
+	NextAttemptAt  pgtype.Timestamptz
+	DeadLetteredAt pgtype.Timestamptz
+}
+
+// This table stores token buckets shared by API replicas for rate limiting
+type RateLimitBucket struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt pgtype.Timestamptz
+}
+
 type SchemaMigration struct {
 	Version int64
 	Dirty   bool
@@ -38,4 +88,35 @@ type User struct {
 	Password  string
 	CreatedAt pgtype.Timestamptz
 	UpdatedAt pgtype.Timestamptz
+	Version   int32
+	DeletedAt pgtype.Timestamptz
+}
+
+// This table stores each event sent to a webhook subscription and the outcome of its last attempt
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID pgtype.UUID
+	EventID        int64
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	NextAttemptAt  pgtype.Timestamptz
+	LastAttemptAt  pgtype.Timestamptz
+	ResponseCode   *int32
+	LastError      *string
+	CreatedAt      pgtype.Timestamptz
+}
+
+// This table stores partner endpoints receiving events by webhook
+type WebhookSubscription struct {
+	ID                  pgtype.UUID
+	URL                 string
+	EventTypes          []string
+	Secret              string
+	Enabled             bool
+	ConsecutiveFailures int32
+	DisabledAt          pgtype.Timestamptz
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
 }
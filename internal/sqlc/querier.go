@@ -11,17 +11,77 @@ import (
 )
 
 type Querier interface {
+	// Locks pending deliveries of enabled subscriptions that are due; concurrent dispatchers skip them
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	// Inserts the key, or takes it over when it expired or the request holding it stopped before storing a response.
+	// Returns no row while another request holds the key or once its response is stored.
+	ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (IdempotencyKey, error)
+	// Claims the pending events of the aggregates whose earliest pending event is due, in the order they were written,
+	// by delaying their next attempt until claimed_until. The aggregates of events waiting for a retry, or claimed by
+	// another relay, are skipped so that they do not hold back the others.
+	ClaimPendingOutboxEvents(ctx context.Context, arg ClaimPendingOutboxEventsParams) ([]Outbox, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
 	CreateItem(ctx context.Context, arg CreateItemParams) (Item, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteItem(ctx context.Context, id pgtype.UUID) error
-	DeleteUser(ctx context.Context, id pgtype.UUID) error
+	// Does nothing when the event was already delivered to the subscription, as the outbox may publish it again
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	// Gives up on the event, letting the later events of its aggregate be published
+	DeadLetterOutboxEvent(ctx context.Context, arg DeadLetterOutboxEventParams) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error)
+	// DeleteItem soft deletes the row while it still has the expected version
+	DeleteItem(ctx context.Context, arg DeleteItemParams) (int64, error)
+	DeletePublishedOutboxEvents(ctx context.Context, publishedAt pgtype.Timestamptz) (int64, error)
+	DeleteRateLimitBucketsBefore(ctx context.Context, updatedAt pgtype.Timestamptz) error
+	// DeleteUser soft deletes the row while it still has the expected version
+	DeleteUser(ctx context.Context, arg DeleteUserParams) (int64, error)
+	DeleteWebhookSubscription(ctx context.Context, id pgtype.UUID) (int64, error)
+	DisableWebhookSubscription(ctx context.Context, id pgtype.UUID) error
+	GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error)
 	GetItem(ctx context.Context, id pgtype.UUID) (Item, error)
 	GetUser(ctx context.Context, id pgtype.UUID) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetWebhookSubscription(ctx context.Context, id pgtype.UUID) (WebhookSubscription, error)
+	IncrementWebhookSubscriptionFailures(ctx context.Context, id pgtype.UUID) (WebhookSubscription, error)
+	InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error
+	// Lists events newest first. Filters are skipped when NULL; after_id is the cursor of the previous page.
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListDeletedItems(ctx context.Context) ([]Item, error)
+	ListDeletedUsers(ctx context.Context) ([]User, error)
+	ListEnabledWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
 	ListItems(ctx context.Context) ([]Item, error)
 	ListUsers(ctx context.Context) ([]User, error)
+	// Lists deliveries of a subscription newest first. status is skipped when NULL; after_id is the cursor of the previous page.
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	// PurgeDeletedItems hard deletes items soft deleted before the given time
+	PurgeDeletedItems(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error)
+	// PurgeDeletedUsers hard deletes users soft deleted before the given time
+	PurgeDeletedUsers(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error)
+	RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) error
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) error
+	// Deletes the key of a request whose response is not stored, so that a retry runs it again
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	ResetWebhookSubscriptionFailures(ctx context.Context, id pgtype.UUID) error
+	RestoreItem(ctx context.Context, id pgtype.UUID) (Item, error)
+	RestoreUser(ctx context.Context, id pgtype.UUID) (User, error)
+	SaveIdempotencyResponse(ctx context.Context, arg SaveIdempotencyResponseParams) error
+	// SearchItems finds active items whose name or description match the web search query q,
+	// or whose name is similar to q, to tolerate typos. Filters are skipped when NULL.
+	// sort_by is relevance, -created_at, created_at or name; the after_ params are the cursor of the previous page
+	// and hold the sort key of sort_by and the id of its last item.
+	// The highlights are HTML: name and description are escaped before ts_headline wraps the matches in <mark>.
+	SearchItems(ctx context.Context, arg SearchItemsParams) ([]SearchItemsRow, error)
+	// Refills the bucket for the elapsed time and takes one token if available, atomically per key.
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error)
+	// Takes a lock held until the end of the transaction so that only one relay claims events at a time
+	TryLockOutboxRelay(ctx context.Context, lockKey int64) (bool, error)
+	// UpdateItem only matches the row while it still has the expected version
 	UpdateItem(ctx context.Context, arg UpdateItemParams) (Item, error)
+	// UpdateUser only matches the row while it still has the expected version
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error)
 }
 
 var _ Querier = (*Queries)(nil)
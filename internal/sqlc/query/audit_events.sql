@@ -0,0 +1,17 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (actor, entity_type, entity_id, action, diff)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListAuditEvents :many
+-- Lists events newest first. Filters are skipped when NULL; after_id is the cursor of the previous page.
+SELECT * FROM audit_events
+WHERE (sqlc.narg(entity_type)::text IS NULL OR entity_type = sqlc.narg(entity_type))
+  AND (sqlc.narg(entity_id)::uuid IS NULL OR entity_id = sqlc.narg(entity_id))
+  AND (sqlc.narg(actor)::text IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(since)::timestamptz IS NULL OR created_at >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamptz IS NULL OR created_at < sqlc.narg(until))
+  AND (sqlc.narg(after_id)::bigint IS NULL OR id < sqlc.narg(after_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(page_size);
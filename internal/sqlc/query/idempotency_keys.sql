@@ -0,0 +1,33 @@
+-- name: ClaimIdempotencyKey :one
+-- Inserts the key, or takes it over when it expired or the request holding it stopped before storing a response.
+-- Returns no row while another request holds the key or once its response is stored.
+INSERT INTO idempotency_keys (key, fingerprint, locked_until, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (key) DO UPDATE
+SET fingerprint = EXCLUDED.fingerprint,
+    status_code = NULL,
+    response_headers = NULL,
+    response_body = NULL,
+    created_at = CURRENT_TIMESTAMP,
+    locked_until = EXCLUDED.locked_until,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at < CURRENT_TIMESTAMP
+   OR (idempotency_keys.status_code IS NULL
+       AND idempotency_keys.locked_until < CURRENT_TIMESTAMP
+       AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys WHERE key = $1;
+
+-- name: SaveIdempotencyResponse :exec
+UPDATE idempotency_keys
+SET status_code = $2, response_headers = $3, response_body = $4
+WHERE key = $1 AND status_code IS NULL;
+
+-- name: ReleaseIdempotencyKey :exec
+-- Deletes the key of a request whose response is not stored, so that a retry runs it again
+DELETE FROM idempotency_keys WHERE key = $1 AND status_code IS NULL;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at < $1;
@@ -0,0 +1,36 @@
+-- name: SearchItems :many
+-- SearchItems finds active items whose name or description match the web search query q,
+-- or whose name is similar to q, to tolerate typos. Filters are skipped when NULL.
+-- sort_by is relevance, -created_at, created_at or name; the after_ params are the cursor of the previous page
+-- and hold the sort key of sort_by and the id of its last item.
+-- The highlights are HTML: name and description are escaped before ts_headline wraps the matches in <mark>.
+WITH matches AS (
+    SELECT id, type_id, name, description, version, created_at,
+        (ts_rank_cd(search, websearch_to_tsquery('english', sqlc.arg(q))) + similarity(name, sqlc.arg(q)))::real AS score
+    FROM items
+    WHERE deleted_at IS NULL
+      AND (search @@ websearch_to_tsquery('english', sqlc.arg(q)) OR name % sqlc.arg(q))
+      AND (sqlc.narg(type_ids)::integer[] IS NULL OR type_id = ANY(sqlc.narg(type_ids)::integer[]))
+      AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+      AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+)
+SELECT id, type_id, name, description, version, created_at, score,
+    ts_headline('english', replace(replace(replace(replace(replace(name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'), websearch_to_tsquery('english', sqlc.arg(q)),
+        'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')::text AS name_highlight,
+    ts_headline('english', replace(replace(replace(replace(replace(description, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'), websearch_to_tsquery('english', sqlc.arg(q)),
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')::text AS description_highlight
+FROM matches
+WHERE sqlc.narg(after_id)::uuid IS NULL OR CASE sqlc.arg(sort_by)::text
+    WHEN 'relevance' THEN (score, id) < (sqlc.narg(after_score)::real, sqlc.narg(after_id))
+    WHEN '-created_at' THEN (created_at, id) < (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id))
+    WHEN 'created_at' THEN (created_at, id) > (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id))
+    WHEN 'name' THEN (name, id) > (sqlc.narg(after_name)::text, sqlc.narg(after_id))
+END
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by) = 'relevance' THEN score END DESC,
+    CASE WHEN sqlc.arg(sort_by) = '-created_at' THEN created_at END DESC,
+    CASE WHEN sqlc.arg(sort_by) = 'created_at' THEN created_at END ASC,
+    CASE WHEN sqlc.arg(sort_by) = 'name' THEN name END ASC,
+    CASE WHEN sqlc.arg(sort_by) IN ('relevance', '-created_at') THEN id END DESC,
+    id ASC
+LIMIT sqlc.arg(page_size);
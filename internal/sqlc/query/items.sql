@@ -1,19 +1,36 @@
 -- name: CreateItem :one
-INSERT INTO items (id, type_id)
-VALUES ($1, $2)
+INSERT INTO items (id, type_id, name, description)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetItem :one
-SELECT * FROM items WHERE id = $1 LIMIT 1;
+SELECT * FROM items WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: ListItems :many
-SELECT * FROM items ORDER BY created_at DESC;
+SELECT * FROM items WHERE deleted_at IS NULL ORDER BY created_at DESC;
+
+-- name: ListDeletedItems :many
+SELECT * FROM items WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC;
 
 -- name: UpdateItem :one
+-- UpdateItem only matches the row while it still has the expected version
+UPDATE items
+SET type_id = $2, version = version + 1
+WHERE id = $1 AND version = $3 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteItem :execrows
+-- DeleteItem soft deletes the row while it still has the expected version
+UPDATE items
+SET deleted_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = $1 AND version = $2 AND deleted_at IS NULL;
+
+-- name: RestoreItem :one
 UPDATE items
-SET type_id = $2
-WHERE id = $1
+SET deleted_at = NULL, version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
 RETURNING *;
 
--- name: DeleteItem :exec
-DELETE FROM items WHERE id = $1;
+-- name: PurgeDeletedItems :execrows
+-- PurgeDeletedItems hard deletes items soft deleted before the given time
+DELETE FROM items WHERE deleted_at < $1;
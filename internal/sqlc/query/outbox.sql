@@ -0,0 +1,45 @@
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload, occurred_at)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ClaimPendingOutboxEvents :many
+-- Claims the pending events of the aggregates whose earliest pending event is due, in the order they were written,
+-- by delaying their next attempt until claimed_until. The aggregates of events waiting for a retry, or claimed by
+-- another relay, are skipped so that they do not hold back the others.
+WITH heads AS (
+    SELECT DISTINCT ON (aggregate_type, aggregate_id) aggregate_type, aggregate_id, next_attempt_at
+    FROM outbox
+    WHERE published_at IS NULL AND dead_lettered_at IS NULL
+    ORDER BY aggregate_type, aggregate_id, id
+), claimed AS (
+    SELECT o.id FROM outbox o
+    JOIN heads h ON h.aggregate_type = o.aggregate_type AND h.aggregate_id = o.aggregate_id
+    WHERE o.published_at IS NULL AND o.dead_lettered_at IS NULL AND h.next_attempt_at <= CURRENT_TIMESTAMP
+    ORDER BY o.id
+    LIMIT sqlc.arg(batch_size)
+)
+UPDATE outbox SET next_attempt_at = sqlc.arg(claimed_until)
+FROM claimed
+WHERE outbox.id = claimed.id
+RETURNING outbox.*;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox SET published_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL
+WHERE id = $1;
+
+-- name: RecordOutboxEventFailure :exec
+UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+WHERE id = $1;
+
+-- name: DeadLetterOutboxEvent :exec
+-- Gives up on the event, letting the later events of its aggregate be published
+UPDATE outbox SET dead_lettered_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = $2
+WHERE id = $1;
+
+-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox
+WHERE published_at < $1;
+
+-- name: TryLockOutboxRelay :one
+-- Takes a lock held until the end of the transaction so that only one relay claims events at a time
+SELECT pg_try_advisory_xact_lock(sqlc.arg(lock_key)::bigint);
@@ -0,0 +1,21 @@
+-- name: TakeRateLimitToken :one
+-- Refills the bucket for the elapsed time and takes one token if available, atomically per key.
+-- The upsert locks the row even when the bucket is new, so concurrent requests of a key queue up on it.
+-- Without a token the row is left as is, and the refilled tokens are read to tell when to retry.
+WITH taken AS (
+    INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+    VALUES (@key, @burst::float8 - 1, CURRENT_TIMESTAMP)
+    ON CONFLICT (key) DO UPDATE
+    SET tokens = LEAST(@burst::float8, b.tokens + EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - b.updated_at)::float8 * @rate::float8) - 1,
+        updated_at = CURRENT_TIMESTAMP
+    WHERE LEAST(@burst::float8, b.tokens + EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - b.updated_at)::float8 * @rate::float8) >= 1
+    RETURNING tokens
+)
+SELECT tokens, TRUE AS allowed FROM taken
+UNION ALL
+SELECT LEAST(@burst::float8, tokens + EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - updated_at)::float8 * @rate::float8), FALSE
+FROM rate_limit_buckets
+WHERE key = @key AND NOT EXISTS (SELECT 1 FROM taken);
+
+-- name: DeleteRateLimitBucketsBefore :exec
+DELETE FROM rate_limit_buckets WHERE updated_at < $1;
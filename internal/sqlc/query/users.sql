@@ -4,19 +4,36 @@ VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetUser :one
-SELECT * FROM users WHERE id = $1 LIMIT 1;
+SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetUserByEmail :one
-SELECT * FROM users WHERE email = $1 LIMIT 1;
+SELECT * FROM users WHERE email = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: ListUsers :many
-SELECT * FROM users ORDER BY created_at DESC;
+SELECT * FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC;
+
+-- name: ListDeletedUsers :many
+SELECT * FROM users WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC;
 
 -- name: UpdateUser :one
+-- UpdateUser only matches the row while it still has the expected version
+UPDATE users
+SET name = $2, version = version + 1
+WHERE id = $1 AND version = $3 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteUser :execrows
+-- DeleteUser soft deletes the row while it still has the expected version
+UPDATE users
+SET deleted_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = $1 AND version = $2 AND deleted_at IS NULL;
+
+-- name: RestoreUser :one
 UPDATE users
-SET name = $2
-WHERE id = $1
+SET deleted_at = NULL, version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
 RETURNING *;
 
--- name: DeleteUser :exec
-DELETE FROM users WHERE id = $1;
+-- name: PurgeDeletedUsers :execrows
+-- PurgeDeletedUsers hard deletes users soft deleted before the given time
+DELETE FROM users WHERE deleted_at < $1;
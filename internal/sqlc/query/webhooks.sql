@@ -0,0 +1,68 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (id, url, event_types, secret)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+ORDER BY created_at;
+
+-- name: ListEnabledWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE enabled = TRUE
+ORDER BY created_at;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions SET url = $2, event_types = $3, enabled = $4, consecutive_failures = $5, disabled_at = $6, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: ResetWebhookSubscriptionFailures :exec
+UPDATE webhook_subscriptions SET consecutive_failures = 0
+WHERE id = $1 AND consecutive_failures > 0;
+
+-- name: IncrementWebhookSubscriptionFailures :one
+UPDATE webhook_subscriptions SET consecutive_failures = consecutive_failures + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: DisableWebhookSubscription :exec
+UPDATE webhook_subscriptions SET enabled = FALSE, disabled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CreateWebhookDelivery :exec
+-- Does nothing when the event was already delivered to the subscription, as the outbox may publish it again
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ClaimDueWebhookDeliveries :many
+-- Locks pending deliveries of enabled subscriptions that are due; concurrent dispatchers skip them
+SELECT * FROM webhook_deliveries
+WHERE status = 'pending'
+  AND next_attempt_at <= $1
+  AND subscription_id IN (SELECT id FROM webhook_subscriptions WHERE enabled = TRUE)
+ORDER BY next_attempt_at, id
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries SET status = $2, attempts = attempts + 1, next_attempt_at = $3, last_attempt_at = CURRENT_TIMESTAMP, response_code = $4, last_error = $5
+WHERE id = $1;
+
+-- name: ListWebhookDeliveries :many
+-- Lists deliveries of a subscription newest first. status is skipped when NULL; after_id is the cursor of the previous page.
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg(subscription_id)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(after_id)::bigint IS NULL OR id < sqlc.narg(after_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(page_size);
@@ -14,7 +14,7 @@ import (
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, name, email, password)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, email, password, created_at, updated_at
+RETURNING id, name, email, password, created_at, updated_at, version, deleted_at
 `
 
 type CreateUserParams struct {
@@ -39,21 +39,34 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE FROM users WHERE id = $1
+const deleteUser = `-- name: DeleteUser :execrows
+UPDATE users
+SET deleted_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = $1 AND version = $2 AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteUser, id)
-	return err
+type DeleteUserParams struct {
+	ID      pgtype.UUID
+	Version int32
+}
+
+// DeleteUser soft deletes the row while it still has the expected version
+func (q *Queries) DeleteUser(ctx context.Context, arg DeleteUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUser, arg.ID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, name, email, password, created_at, updated_at FROM users WHERE id = $1 LIMIT 1
+SELECT id, name, email, password, created_at, updated_at, version, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUser(ctx context.Context, id pgtype.UUID) (User, error) {
@@ -66,12 +79,14 @@ func (q *Queries) GetUser(ctx context.Context, id pgtype.UUID) (User, error) {
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, name, email, password, created_at, updated_at FROM users WHERE email = $1 LIMIT 1
+SELECT id, name, email, password, created_at, updated_at, version, deleted_at FROM users WHERE email = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -84,12 +99,47 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const listDeletedUsers = `-- name: ListDeletedUsers :many
+SELECT id, name, email, password, created_at, updated_at, version, deleted_at FROM users WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC
+`
+
+func (q *Queries) ListDeletedUsers(ctx context.Context) ([]User, error) {
+	rows, err := q.db.Query(ctx, listDeletedUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, name, email, password, created_at, updated_at FROM users ORDER BY created_at DESC
+SELECT id, name, email, password, created_at, updated_at, version, deleted_at FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC
 `
 
 func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
@@ -108,6 +158,8 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 			&i.Password,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -119,20 +171,58 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :execrows
+DELETE FROM users WHERE deleted_at < $1
+`
+
+// PurgeDeletedUsers hard deletes users soft deleted before the given time
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedUsers, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreUser = `-- name: RestoreUser :one
+UPDATE users
+SET deleted_at = NULL, version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, name, email, password, created_at, updated_at, version, deleted_at
+`
+
+func (q *Queries) RestoreUser(ctx context.Context, id pgtype.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, restoreUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET name = $2
-WHERE id = $1
-RETURNING id, name, email, password, created_at, updated_at
+SET name = $2, version = version + 1
+WHERE id = $1 AND version = $3 AND deleted_at IS NULL
+RETURNING id, name, email, password, created_at, updated_at, version, deleted_at
 `
 
 type UpdateUserParams struct {
-	ID   pgtype.UUID
-	Name string
+	ID      pgtype.UUID
+	Name    string
+	Version int32
 }
 
+// UpdateUser only matches the row while it still has the expected version
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, updateUser, arg.ID, arg.Name)
+	row := q.db.QueryRow(ctx, updateUser, arg.ID, arg.Name, arg.Version)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -141,6 +231,8 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
 	)
 	return i, err
 }
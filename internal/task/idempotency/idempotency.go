@@ -0,0 +1,20 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func (u *idempotencyTaskUsecase) PurgeExpiredKeys(ctx context.Context) error {
+	deleted, err := u.Store.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	zerolog.Ctx(ctx).Info().Int64("deleted", deleted).Msg("Purged expired idempotency keys")
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package idempotency
+
+import (
+	"context"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/idempotency"
+)
+
+type IdempotencyTaskUsecase interface {
+	PurgeExpiredKeys(ctx context.Context) error
+}
+
+type idempotencyTaskUsecase struct {
+	Store idempotency.Store
+}
+
+// NewIdempotencyTaskUsecase creates a new idempotency task usecase
+// Following DIP: depends on the store interface, not concrete implementation
+func NewIdempotencyTaskUsecase(store idempotency.Store) IdempotencyTaskUsecase {
+	return &idempotencyTaskUsecase{
+		Store: store,
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"strconv"
 
 	"github.com/SoraDaibu/go-clean-starter/domain"
-	"github.com/rs/zerolog/log"
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+	"github.com/rs/zerolog"
 )
 
 type ImportResult struct {
@@ -24,12 +26,12 @@ func (r *ImportResult) addError(err error) {
 }
 
 func (u *itemTaskUsecase) ImportItems(ctx context.Context, sourceDir string, dryRun bool) error {
-	log.Info().Str("source_dir", sourceDir).Bool("dry_run", dryRun).Msg("Starting item import")
+	zerolog.Ctx(ctx).Info().Str("source_dir", sourceDir).Bool("dry_run", dryRun).Msg("Starting item import")
 
 	// Read all CSV files in the source directory
 	files, err := os.ReadDir(sourceDir)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to read source directory")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to read source directory")
 		return fmt.Errorf("failed to read source directory: %w", err)
 	}
 
@@ -43,14 +45,20 @@ func (u *itemTaskUsecase) ImportItems(ctx context.Context, sourceDir string, dry
 		filePath := filepath.Join(sourceDir, file.Name())
 		result, err := u.importCSVFile(ctx, filePath, dryRun)
 		if err != nil {
-			log.Error().Err(err).Str("file", filePath).Msg("Failed to import CSV file")
+			zerolog.Ctx(ctx).Error().Err(err).Str("file", filePath).Msg("Failed to import CSV file")
 			return fmt.Errorf("failed to import file %s: %w", filePath, err)
 		}
 
 		result.FilePath = filePath
 		totalResults = append(totalResults, result)
 
-		log.Info().
+		if !dryRun {
+			if err := u.recordImport(ctx, result); err != nil {
+				return err
+			}
+		}
+
+		zerolog.Ctx(ctx).Info().
 			Str("file", filePath).
 			Int("created", result.ItemsCreated).
 			Int("skipped", result.ItemsSkipped).
@@ -68,7 +76,7 @@ func (u *itemTaskUsecase) ImportItems(ctx context.Context, sourceDir string, dry
 		totalErrors += len(result.Errors)
 	}
 
-	log.Info().
+	zerolog.Ctx(ctx).Info().
 		Int("files_processed", len(totalResults)).
 		Int("total_created", totalCreated).
 		Int("total_skipped", totalSkipped).
@@ -78,6 +86,22 @@ func (u *itemTaskUsecase) ImportItems(ctx context.Context, sourceDir string, dry
 	return nil
 }
 
+// recordImport writes one audit event per imported file rather than one per item.
+func (u *itemTaskUsecase) recordImport(ctx context.Context, result *ImportResult) error {
+	return u.Tx.Do(ctx, func(ctx context.Context) error {
+		return u.AuditRecorder.Record(ctx, audit.Entry{
+			EntityType: "item",
+			Action:     audit.ActionImport,
+			After: map[string]any{
+				"file":          result.FilePath,
+				"items_created": result.ItemsCreated,
+				"items_skipped": result.ItemsSkipped,
+				"errors":        len(result.Errors),
+			},
+		})
+	})
+}
+
 func (u *itemTaskUsecase) importCSVFile(ctx context.Context, filePath string, dryRun bool) (*ImportResult, error) {
 	result := &ImportResult{}
 
@@ -101,7 +125,7 @@ func (u *itemTaskUsecase) importCSVFile(ctx context.Context, filePath string, dr
 	for i, record := range records[1:] {
 		if len(record) < 3 {
 			err := fmt.Errorf("invalid CSV format at line %d: expected 3 columns (type_id,name,description), got %d", i+2, len(record))
-			log.Error().Err(err).Msg("invalid CSV format")
+			zerolog.Ctx(ctx).Error().Err(err).Msg("invalid CSV format")
 			result.addError(err)
 			continue
 		}
@@ -110,7 +134,7 @@ func (u *itemTaskUsecase) importCSVFile(ctx context.Context, filePath string, dr
 		var typeID uint
 		if record[0] == "" {
 			err := fmt.Errorf("empty type_id for item %s at line %d", record[1], i+2)
-			log.Error().Err(err).Msg("empty type_id")
+			zerolog.Ctx(ctx).Error().Err(err).Msg("empty type_id")
 			result.addError(err)
 			continue
 		}
@@ -118,34 +142,42 @@ func (u *itemTaskUsecase) importCSVFile(ctx context.Context, filePath string, dr
 		typeIDInt, err := strconv.Atoi(record[0])
 		if err != nil {
 			err := fmt.Errorf("invalid type_id '%s' for item %s at line %d: %w", record[0], record[1], i+2, err)
-			log.Error().Err(err).Msg("failed to convert type_id to int")
+			zerolog.Ctx(ctx).Error().Err(err).Msg("failed to convert type_id to int")
 			result.addError(err)
 			continue
 		}
 
 		if typeIDInt < 0 {
 			err := fmt.Errorf("negative type_id '%d' for item %s at line %d", typeIDInt, record[1], i+2)
-			log.Error().Err(err).Msg("invalid type_id")
+			zerolog.Ctx(ctx).Error().Err(err).Msg("invalid type_id")
 			result.addError(err)
 			continue
 		}
 
 		typeID = uint(typeIDInt)
 
-		// Create domain item
-		item := domain.NewItem(typeID)
+		if record[1] == "" {
+			err := fmt.Errorf("empty name at line %d", i+2)
+			zerolog.Ctx(ctx).Error().Err(err).Msg("empty name")
+			result.addError(err)
+			continue
+		}
 
 		if dryRun {
-			log.Info().
+			item := domain.NewItem(typeID, record[1], record[2])
+			zerolog.Ctx(ctx).Info().
 				Str("id", item.ID().String()).
 				Interface("type_id", item.TypeID()).
+				Str("name", item.Name()).
 				Msg("DRY RUN: Would create item")
 			result.ItemsCreated++
 			continue
 		}
 
-		// Create item in database with transaction
+		// Create item in database with transaction, built in it so that a retry saves its events again
+		var item *domain.Item
 		err = u.Tx.Do(ctx, func(ctx context.Context) error {
+			item = domain.NewItem(typeID, record[1], record[2])
 			_, err := u.ItemRepo.CreateItem(ctx, item)
 			return err
 		})
@@ -157,11 +189,16 @@ func (u *itemTaskUsecase) importCSVFile(ctx context.Context, filePath string, dr
 		}
 
 		result.ItemsCreated++
-		log.Debug().
+		zerolog.Ctx(ctx).Debug().
 			Str("id", item.ID().String()).
 			Interface("type_id", item.TypeID()).
 			Msg("Item created successfully")
 	}
 
+	dryRunLabel := strconv.FormatBool(dryRun)
+	metrics.ImportItemsTotal.WithLabelValues("created", dryRunLabel).Add(float64(result.ItemsCreated))
+	metrics.ImportItemsTotal.WithLabelValues("skipped", dryRunLabel).Add(float64(result.ItemsSkipped))
+	metrics.ImportItemsTotal.WithLabelValues("error", dryRunLabel).Add(float64(len(result.Errors)))
+
 	return result, nil
 }
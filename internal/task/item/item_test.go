@@ -0,0 +1,31 @@
+package item_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/metrics"
+	"github.com/SoraDaibu/go-clean-starter/internal/task/item"
+)
+
+func TestImportItems_Metrics(t *testing.T) {
+	dir := t.TempDir()
+	csv := "type_id,name,description\n1,Pen,Blue\n2,Cup,\n,Nameless type,\nx,Bad type,\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "items.csv"), []byte(csv), 0o600))
+
+	created := testutil.ToFloat64(metrics.ImportItemsTotal.WithLabelValues("created", "true"))
+	failed := testutil.ToFloat64(metrics.ImportItemsTotal.WithLabelValues("error", "true"))
+
+	// a dry run touches no repository
+	require.NoError(t, item.NewItemTaskUsecase(nil, nil, nil).ImportItems(context.Background(), dir, true))
+
+	assert.Equal(t, created+2, testutil.ToFloat64(metrics.ImportItemsTotal.WithLabelValues("created", "true")))
+	assert.Equal(t, failed+2, testutil.ToFloat64(metrics.ImportItemsTotal.WithLabelValues("error", "true")))
+	assert.Zero(t, testutil.ToFloat64(metrics.ImportItemsTotal.WithLabelValues("created", "false")))
+}
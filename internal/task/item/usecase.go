@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/audit"
 	"github.com/SoraDaibu/go-clean-starter/internal/repository"
 )
 
@@ -12,8 +13,9 @@ type ItemTaskUsecase interface {
 }
 
 type itemTaskUsecase struct {
-	Tx       repository.Transaction
-	ItemRepo domain.ItemRepository
+	Tx            repository.Transaction
+	ItemRepo      domain.ItemRepository
+	AuditRecorder audit.Recorder
 }
 
 // NewItemTaskUsecase creates a new item task usecase
@@ -21,9 +23,11 @@ type itemTaskUsecase struct {
 func NewItemTaskUsecase(
 	tx repository.Transaction,
 	itemRepo domain.ItemRepository,
+	auditRecorder audit.Recorder,
 ) ItemTaskUsecase {
 	return &itemTaskUsecase{
-		Tx:       tx,
-		ItemRepo: itemRepo,
+		Tx:            tx,
+		ItemRepo:      itemRepo,
+		AuditRecorder: auditRecorder,
 	}
 }
@@ -0,0 +1,50 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// errDryRun rolls back the purge transaction after counting the rows.
+var errDryRun = errors.New("dry run")
+
+// PurgeDeleted hard deletes users and items that were soft deleted more than retention ago.
+func (u *purgeTaskUsecase) PurgeDeleted(ctx context.Context, retention time.Duration, dryRun bool) error {
+	before := time.Now().Add(-retention)
+	zerolog.Ctx(ctx).Info().Time("deleted_before", before).Bool("dry_run", dryRun).Msg("Starting purge of soft deleted rows")
+
+	var users, items int64
+	err := u.Tx.Do(ctx, func(ctx context.Context) error {
+		var err error
+		users, err = u.UserRepo.PurgeDeletedUsers(ctx, before)
+		if err != nil {
+			return fmt.Errorf("failed to purge users: %w", err)
+		}
+
+		items, err = u.ItemRepo.PurgeDeletedItems(ctx, before)
+		if err != nil {
+			return fmt.Errorf("failed to purge items: %w", err)
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return err
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Int64("users", users).
+		Int64("items", items).
+		Bool("dry_run", dryRun).
+		Msg("Purge completed")
+
+	return nil
+}
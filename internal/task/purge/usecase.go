@@ -0,0 +1,33 @@
+package purge
+
+import (
+	"context"
+	"time"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+)
+
+type PurgeTaskUsecase interface {
+	PurgeDeleted(ctx context.Context, retention time.Duration, dryRun bool) error
+}
+
+type purgeTaskUsecase struct {
+	Tx       repository.Transaction
+	UserRepo domain.UserRepository
+	ItemRepo domain.ItemRepository
+}
+
+// NewPurgeTaskUsecase creates a new purge task usecase
+// Following DIP: depends on domain interface, not concrete implementation
+func NewPurgeTaskUsecase(
+	tx repository.Transaction,
+	userRepo domain.UserRepository,
+	itemRepo domain.ItemRepository,
+) PurgeTaskUsecase {
+	return &purgeTaskUsecase{
+		Tx:       tx,
+		UserRepo: userRepo,
+		ItemRepo: itemRepo,
+	}
+}
@@ -0,0 +1,19 @@
+package tracing
+
+import (
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogHook adds trace_id and span_id to every zerolog event logged with a traced context,
+// e.g. log.Info().Ctx(ctx) or zerolog.Ctx(ctx).
+type LogHook struct{}
+
+func (LogHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	sc := trace.SpanContextFromContext(e.GetCtx())
+	if !sc.IsValid() {
+		return
+	}
+
+	e.Str("trace_id", sc.TraceID().String()).Str("span_id", sc.SpanID().String())
+}
@@ -0,0 +1,196 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/SoraDaibu/go-clean-starter/config"
+	"github.com/SoraDaibu/go-clean-starter/internal/tracing"
+)
+
+// newRecorder installs a global TracerProvider recording ended spans, and the W3C propagator, for the test
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return recorder
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTransport(t *testing.T) {
+	recorder := newRecorder(t)
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "parent")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/hooks?token=secret", nil)
+	require.NoError(t, err)
+	res, err := (&http.Client{Transport: tracing.NewTransport(nil)}).Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "HTTP GET", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+
+	attrs := attributes(span)
+	assert.Equal(t, "GET", attrs["http.request.method"].AsString())
+	assert.Equal(t, int64(http.StatusBadGateway), attrs["http.response.status_code"].AsInt64())
+	assert.Equal(t, srv.URL+"/hooks", attrs["url.full"].AsString())
+	assert.Equal(t, codes.Error, span.Status().Code)
+
+	// the callee continues the trace of the client span
+	assert.Equal(t, "00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01", traceparent)
+	assert.Empty(t, req.Header.Get("traceparent"), "the request of the caller is not modified")
+}
+
+func TestQueryTracer(t *testing.T) {
+	recorder := newRecorder(t)
+	tracer := tracing.NewQueryTracer()
+	sql := "-- name: GetUser :one\nSELECT * FROM users WHERE id = $1"
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: pgx.ErrNoRows})
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: &pgconn.PgError{Code: "40001"}})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	attrs := attributes(spans[0])
+	assert.Equal(t, "db GetUser", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, "postgresql", attrs["db.system.name"].AsString())
+	assert.Equal(t, "GetUser", attrs["db.operation.name"].AsString())
+	assert.Equal(t, sql, attrs["db.query.text"].AsString())
+	// no rows is a result, not a failure
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "40001", attributes(spans[1])["db.response.status_code"].AsString())
+}
+
+func TestLogHook(t *testing.T) {
+	newRecorder(t)
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Hook(tracing.LogHook{})
+
+	logger.Info().Ctx(context.Background()).Msg("untraced")
+	assert.NotContains(t, buf.String(), "trace_id")
+
+	ctx, span := tracing.Tracer().Start(context.Background(), "traced")
+	defer span.End()
+	buf.Reset()
+	logger.Info().Ctx(ctx).Msg("traced")
+	assert.Contains(t, buf.String(), `"trace_id":"`+span.SpanContext().TraceID().String()+`"`)
+	assert.Contains(t, buf.String(), `"span_id":"`+span.SpanContext().SpanID().String()+`"`)
+}
+
+func TestStartTask(t *testing.T) {
+	recorder := newRecorder(t)
+
+	// a task starts its own trace even when its context carries a span
+	parentCtx, parent := tracing.Tracer().Start(context.Background(), "parent")
+	ctx, end := tracing.StartTask(parentCtx, "import", attribute.Bool("task.dry_run", true))
+	_, child := tracing.Tracer().Start(ctx, "db CreateItem")
+	child.End()
+	end(errors.New("invalid CSV"))
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	task := spans[1]
+	assert.Equal(t, "task import", task.Name())
+	assert.False(t, task.Parent().IsValid())
+	assert.NotEqual(t, parent.SpanContext().TraceID(), task.SpanContext().TraceID())
+	assert.True(t, attributes(task)["task.dry_run"].AsBool())
+	assert.Equal(t, codes.Error, task.Status().Code)
+	assert.Equal(t, "invalid CSV", task.Status().Description)
+
+	// spans of the run are its children
+	assert.Equal(t, task.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func TestSetup(t *testing.T) {
+	newRecorder(t)
+	ctx := context.Background()
+
+	t.Run("none exports nothing", func(t *testing.T) {
+		var cfg config.Config
+		cfg.Tracing.Exporter = tracing.ExporterNone
+
+		shutdown, err := tracing.Setup(ctx, &cfg)
+		require.NoError(t, err)
+		require.NoError(t, shutdown(ctx))
+	})
+
+	t.Run("file writes spans on shutdown", func(t *testing.T) {
+		var cfg config.Config
+		cfg.Tracing.Exporter = tracing.ExporterFile
+		cfg.Tracing.FilePath = filepath.Join(t.TempDir(), "traces.json")
+		cfg.Tracing.ServiceName = "test"
+		cfg.Tracing.SampleRatio = 1
+
+		shutdown, err := tracing.Setup(ctx, &cfg)
+		require.NoError(t, err)
+
+		_, span := tracing.Tracer().Start(ctx, "written")
+		span.End()
+		require.NoError(t, shutdown(ctx))
+
+		b, err := os.ReadFile(cfg.Tracing.FilePath)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"Name":"written"`)
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		var cfg config.Config
+		cfg.Tracing.Exporter = "zipkin"
+
+		_, err := tracing.Setup(ctx, &cfg)
+		require.ErrorContains(t, err, "unknown tracing exporter")
+	})
+}
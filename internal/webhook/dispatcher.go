@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/backoff"
+	"github.com/SoraDaibu/go-clean-starter/internal/httpclient"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+)
+
+// maxResponseBytes bounds how much of a response is read before the connection is reused
+const maxResponseBytes = 64 << 10
+
+// Config tunes the dispatcher
+type Config struct {
+	// BatchSize is the maximum number of deliveries sent per transaction
+	BatchSize    int
+	PollInterval time.Duration
+	// MaxAttempts is how many times a delivery is sent before it is marked failed
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; later retries double it up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// DisableAfter is the number of consecutive failed attempts after which a subscription is disabled
+	DisableAfter int
+}
+
+// Dispatcher sends pending deliveries to subscriptions, retrying failed ones with exponential backoff.
+// Several dispatchers may run at the same time; each delivery is claimed by only one of them.
+type Dispatcher struct {
+	tx         repository.Transaction
+	repository Repository
+	client     *http.Client
+	cfg        Config
+	now        func() time.Time
+	jitter     func() float64
+}
+
+// NewDispatcher creates a new dispatcher sending requests with client
+// Following DIP: depends on the repository interface, not concrete implementation
+func NewDispatcher(tx repository.Transaction, repository Repository, client *http.Client, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		tx:         tx,
+		repository: repository,
+		client:     client,
+		cfg:        cfg,
+		now:        time.Now,
+		jitter:     rand.Float64,
+	}
+}
+
+// Run sends deliveries until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) error {
+	poll := time.NewTicker(d.cfg.PollInterval)
+	defer poll.Stop()
+
+	for {
+		sent, err := d.DispatchBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("failed to dispatch webhook deliveries")
+		}
+
+		// keep going while deliveries are due
+		if err == nil && sent == d.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-poll.C:
+		}
+	}
+}
+
+// DispatchBatch sends up to Config.BatchSize due deliveries and returns how many were attempted
+func (d *Dispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	attempted := 0
+
+	// not retried, so that deliveries are not sent twice; failed batches are claimed again by the next poll
+	err := d.tx.Do(ctx, func(ctx context.Context) error {
+		deliveries, err := d.repository.ClaimDueDeliveries(ctx, d.now(), d.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		subscriptions := map[uuid.UUID]*Subscription{}
+		for _, delivery := range deliveries {
+			subscription, ok := subscriptions[delivery.SubscriptionID]
+			if !ok {
+				if subscription, err = d.repository.GetSubscription(ctx, delivery.SubscriptionID); err != nil {
+					return err
+				}
+				subscriptions[delivery.SubscriptionID] = subscription
+			}
+			// skip the remaining deliveries of a subscription disabled during this batch
+			if !subscription.Enabled {
+				continue
+			}
+
+			if err := d.attempt(ctx, subscription, delivery); err != nil {
+				return err
+			}
+			attempted++
+		}
+
+		return nil
+	}, repository.WithoutRetry())
+	if err != nil {
+		return 0, err
+	}
+
+	return attempted, nil
+}
+
+// attempt sends the delivery once and records the outcome
+func (d *Dispatcher) attempt(ctx context.Context, subscription *Subscription, delivery *Delivery) error {
+	logger := zerolog.Ctx(ctx).With().
+		Int64("delivery_id", delivery.ID).
+		Str("subscription_id", subscription.ID.String()).
+		Str("event_type", delivery.EventType).
+		Logger()
+
+	code, sendErr := d.send(ctx, subscription, delivery)
+	delivery.ResponseCode = code
+	delivery.Attempts++
+
+	if sendErr == nil {
+		delivery.Status = DeliverySucceeded
+		delivery.LastError = ""
+		if err := d.repository.RecordAttempt(ctx, delivery); err != nil {
+			return err
+		}
+		logger.Debug().Int32("attempts", delivery.Attempts).Msg("webhook delivered")
+
+		return d.repository.ResetFailures(ctx, subscription.ID)
+	}
+
+	delivery.LastError = sendErr.Error()
+	if int(delivery.Attempts) >= d.cfg.MaxAttempts {
+		delivery.Status = DeliveryFailed
+	} else {
+		delivery.NextAttemptAt = d.now().Add(backoff.Delay(int(delivery.Attempts), d.cfg.BaseDelay, d.cfg.MaxDelay, d.jitter()))
+	}
+	if err := d.repository.RecordAttempt(ctx, delivery); err != nil {
+		return err
+	}
+	logger.Warn().Err(sendErr).Int32("attempts", delivery.Attempts).Str("status", string(delivery.Status)).Msg("failed to deliver webhook")
+
+	// the request was not sent while the host recovers: its failures were counted when the circuit opened,
+	// and counting every delivery skipped since would disable a subscription for a short outage
+	if errors.Is(sendErr, httpclient.ErrCircuitOpen) {
+		return nil
+	}
+
+	updated, err := d.repository.IncrementFailures(ctx, subscription.ID)
+	if err != nil {
+		return err
+	}
+	if int(updated.ConsecutiveFailures) >= d.cfg.DisableAfter {
+		if err := d.repository.DisableSubscription(ctx, subscription.ID); err != nil {
+			return err
+		}
+		subscription.Enabled = false
+		logger.Warn().Int32("consecutive_failures", updated.ConsecutiveFailures).Msg("webhook subscription disabled")
+	}
+
+	return nil
+}
+
+// send posts the delivery and returns the response status, if any
+func (d *Dispatcher) send(ctx context.Context, subscription *Subscription, delivery *Delivery) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderEventID, strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(HeaderEventType, delivery.EventType)
+	now := d.now()
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, now, delivery.Payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxResponseBytes))
+
+	code := res.StatusCode
+	if code < 200 || code >= 300 {
+		return &code, fmt.Errorf("webhook responded with %s", res.Status)
+	}
+
+	return &code, nil
+}
@@ -0,0 +1,209 @@
+package webhook_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/httpclient"
+	"github.com/SoraDaibu/go-clean-starter/internal/repository"
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+)
+
+type fakeTransaction struct{}
+
+func (fakeTransaction) Do(ctx context.Context, fn func(ctx context.Context) error, _ ...repository.TxOption) error {
+	return fn(ctx)
+}
+
+// fakeRepository keeps subscriptions and deliveries in memory; only what the dispatcher uses is implemented
+type fakeRepository struct {
+	webhook.Repository
+	subscriptions map[uuid.UUID]*webhook.Subscription
+	deliveries    []*webhook.Delivery
+}
+
+func (r *fakeRepository) GetSubscription(_ context.Context, id uuid.UUID) (*webhook.Subscription, error) {
+	s, ok := r.subscriptions[id]
+	if !ok {
+		return nil, webhook.ErrSubscriptionNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (r *fakeRepository) ClaimDueDeliveries(_ context.Context, now time.Time, limit int) ([]*webhook.Delivery, error) {
+	var due []*webhook.Delivery
+	for _, d := range r.deliveries {
+		if d.Status == webhook.DeliveryPending && !d.NextAttemptAt.After(now) && r.subscriptions[d.SubscriptionID].Enabled && len(due) < limit {
+			copied := *d
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeRepository) RecordAttempt(_ context.Context, delivery *webhook.Delivery) error {
+	for i, d := range r.deliveries {
+		if d.ID == delivery.ID {
+			copied := *delivery
+			r.deliveries[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) ResetFailures(_ context.Context, id uuid.UUID) error {
+	r.subscriptions[id].ConsecutiveFailures = 0
+	return nil
+}
+
+func (r *fakeRepository) IncrementFailures(ctx context.Context, id uuid.UUID) (*webhook.Subscription, error) {
+	r.subscriptions[id].ConsecutiveFailures++
+	return r.GetSubscription(ctx, id)
+}
+
+func (r *fakeRepository) DisableSubscription(_ context.Context, id uuid.UUID) error {
+	r.subscriptions[id].Enabled = false
+	return nil
+}
+
+func newFakeRepository(url string, deliveries int) (*fakeRepository, *webhook.Subscription) {
+	subscription := &webhook.Subscription{ID: uuid.New(), URL: url, Secret: "whsec_0123456789abcdef", Enabled: true}
+	r := &fakeRepository{subscriptions: map[uuid.UUID]*webhook.Subscription{subscription.ID: subscription}}
+	for i := 1; i <= deliveries; i++ {
+		r.deliveries = append(r.deliveries, &webhook.Delivery{
+			ID:             int64(i),
+			SubscriptionID: subscription.ID,
+			EventID:        int64(100 + i),
+			EventType:      "user.created",
+			Payload:        []byte(`{"type":"user.created"}`),
+			Status:         webhook.DeliveryPending,
+		})
+	}
+	return r, subscription
+}
+
+func TestDispatcher_DispatchBatch(t *testing.T) {
+	cfg := webhook.Config{BatchSize: 10, MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute, DisableAfter: 5}
+
+	t.Run("sends signed deliveries", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		repo, subscription := newFakeRepository(receiver.URL, 1)
+		dispatcher := webhook.NewDispatcher(fakeTransaction{}, repo, receiver.Client(), cfg)
+
+		attempted, err := dispatcher.DispatchBatch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+
+		require.NotNil(t, received)
+		assert.Equal(t, "1", received.Header.Get(webhook.HeaderDeliveryID))
+		assert.Equal(t, "101", received.Header.Get(webhook.HeaderEventID))
+		assert.Equal(t, "user.created", received.Header.Get(webhook.HeaderEventType))
+		assert.NoError(t, webhook.Verify(
+			subscription.Secret,
+			received.Header.Get(webhook.HeaderSignature),
+			received.Header.Get(webhook.HeaderTimestamp),
+			body,
+			time.Now(),
+			time.Minute,
+		))
+
+		delivery := repo.deliveries[0]
+		assert.Equal(t, webhook.DeliverySucceeded, delivery.Status)
+		assert.Equal(t, int32(1), delivery.Attempts)
+		require.NotNil(t, delivery.ResponseCode)
+		assert.Equal(t, http.StatusNoContent, *delivery.ResponseCode)
+	})
+
+	t.Run("schedules retries and gives up after max attempts", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer receiver.Close()
+
+		repo, _ := newFakeRepository(receiver.URL, 1)
+		dispatcher := webhook.NewDispatcher(fakeTransaction{}, repo, receiver.Client(), cfg)
+
+		for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
+			before := time.Now()
+			_, err := dispatcher.DispatchBatch(context.Background())
+			require.NoError(t, err)
+
+			delivery := repo.deliveries[0]
+			assert.Equal(t, int32(attempt), delivery.Attempts)
+			assert.Equal(t, http.StatusInternalServerError, *delivery.ResponseCode)
+			assert.Contains(t, delivery.LastError, "500")
+			if attempt < cfg.MaxAttempts {
+				assert.Equal(t, webhook.DeliveryPending, delivery.Status)
+				assert.True(t, delivery.NextAttemptAt.After(before), "retry is scheduled in the future")
+				// make the retry due
+				delivery.NextAttemptAt = time.Time{}
+			} else {
+				assert.Equal(t, webhook.DeliveryFailed, delivery.Status)
+			}
+		}
+	})
+
+	t.Run("disables the subscription after consecutive failures", func(t *testing.T) {
+		calls := 0
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer receiver.Close()
+
+		repo, subscription := newFakeRepository(receiver.URL, 8)
+		dispatcher := webhook.NewDispatcher(fakeTransaction{}, repo, receiver.Client(), cfg)
+
+		attempted, err := dispatcher.DispatchBatch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, cfg.DisableAfter, attempted)
+		assert.Equal(t, cfg.DisableAfter, calls)
+		assert.False(t, repo.subscriptions[subscription.ID].Enabled)
+
+		attempted, err = dispatcher.DispatchBatch(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, attempted)
+	})
+
+	t.Run("does not count deliveries skipped by an open circuit as failures", func(t *testing.T) {
+		repo, subscription := newFakeRepository("https://partner.example.com/hooks", 8)
+		open := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			return nil, fmt.Errorf("partner.example.com: %w", httpclient.ErrCircuitOpen)
+		})}
+		dispatcher := webhook.NewDispatcher(fakeTransaction{}, repo, open, cfg)
+
+		attempted, err := dispatcher.DispatchBatch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 8, attempted)
+		assert.True(t, repo.subscriptions[subscription.ID].Enabled)
+		assert.Zero(t, repo.subscriptions[subscription.ID].ConsecutiveFailures)
+		for _, delivery := range repo.deliveries {
+			assert.Equal(t, webhook.DeliveryPending, delivery.Status)
+			assert.Contains(t, delivery.LastError, "circuit breaker open")
+		}
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with each delivery
+const (
+	HeaderDeliveryID = "X-Webhook-ID"
+	HeaderEventID    = "X-Event-ID"
+	HeaderEventType  = "X-Event-Type"
+	// HeaderTimestamp is the Unix time the delivery was signed at
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature is "v1=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the subscription secret
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const signatureVersion = "v1="
+
+var (
+	ErrInvalidSignature = errors.New("webhook signature is invalid")
+	ErrExpiredTimestamp = errors.New("webhook timestamp is outside the tolerance")
+)
+
+// Sign returns the HeaderSignature value of body sent at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a delivery as a receiver would.
+// Deliveries signed more than tolerance away from now are rejected to prevent replays.
+func Verify(secret, signature, timestamp string, body []byte, now time.Time, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	signedAt := time.Unix(unix, 0)
+	if now.Sub(signedAt).Abs() > tolerance {
+		return ErrExpiredTimestamp
+	}
+
+	if !strings.HasPrefix(signature, signatureVersion) ||
+		!hmac.Equal([]byte(signature), []byte(Sign(secret, signedAt, body))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/outbox"
+)
+
+// Sink is an outbox.Sink creating a delivery of each event for every subscription matching it.
+// Called by the outbox relay, the deliveries are committed together with the event being marked published.
+type Sink struct {
+	repository Repository
+}
+
+// NewSink creates a new sink
+// Following DIP: depends on the repository interface, not concrete implementation
+func NewSink(repository Repository) *Sink {
+	return &Sink{repository: repository}
+}
+
+// Publish implements outbox.Sink
+func (s *Sink) Publish(ctx context.Context, msg outbox.Message) error {
+	subscriptions, err := s.repository.ListEnabledSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(msg.Type) {
+			continue
+		}
+
+		if err := s.repository.CreateDelivery(ctx, &Delivery{
+			SubscriptionID: subscription.ID,
+			EventID:        msg.ID,
+			EventType:      msg.Type,
+			Payload:        payload,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
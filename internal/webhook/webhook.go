@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/SoraDaibu/go-clean-starter/domain"
+)
+
+// ErrSubscriptionNotFound matches domain.ErrNotFound with errors.Is
+var ErrSubscriptionNotFound error = &domain.NotFoundError{Entity: "webhook subscription"}
+
+// Subscription is a partner endpoint receiving events
+type Subscription struct {
+	ID  uuid.UUID
+	URL string
+	// EventTypes filters the events sent, e.g. user.created or user.*; empty sends every event
+	EventTypes []string
+	// Secret signs the deliveries
+	Secret  string
+	Enabled bool
+	// ConsecutiveFailures counts failed attempts since the last successful one
+	ConsecutiveFailures int32
+	DisabledAt          *time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// Matches reports whether events of eventType are sent to the subscription
+func (s *Subscription) Matches(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+
+	for _, t := range s.EventTypes {
+		if t == "*" || t == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DeliveryStatus is the state of a delivery
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries are sent at NextAttemptAt
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed deliveries ran out of attempts
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is an event to send, or sent, to a subscription
+type Delivery struct {
+	ID             int64
+	SubscriptionID uuid.UUID
+	EventID        int64
+	EventType      string
+	// Payload is the request body
+	Payload       json.RawMessage
+	Status        DeliveryStatus
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastAttemptAt *time.Time
+	// ResponseCode is the HTTP status of the last attempt, nil when no response was received
+	ResponseCode *int
+	LastError    string
+	CreatedAt    time.Time
+}
+
+// DeliveryFilter selects deliveries of a subscription to list. Zero values match everything.
+type DeliveryFilter struct {
+	SubscriptionID uuid.UUID
+	Status         DeliveryStatus
+	// AfterID lists deliveries older than the delivery with this ID
+	AfterID int64
+	Limit   int
+}
+
+// Repository stores subscriptions and their deliveries
+type Repository interface {
+	CreateSubscription(ctx context.Context, subscription *Subscription) (*Subscription, error)
+	// GetSubscription returns ErrSubscriptionNotFound when the subscription does not exist
+	GetSubscription(ctx context.Context, id uuid.UUID) (*Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+	ListEnabledSubscriptions(ctx context.Context) ([]*Subscription, error)
+	// UpdateSubscription saves URL, EventTypes, Enabled, ConsecutiveFailures and DisabledAt
+	UpdateSubscription(ctx context.Context, subscription *Subscription) (*Subscription, error)
+	// DeleteSubscription returns ErrSubscriptionNotFound when the subscription does not exist
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	// ResetFailures clears ConsecutiveFailures after a successful attempt
+	ResetFailures(ctx context.Context, id uuid.UUID) error
+	// IncrementFailures counts a failed attempt and returns the updated subscription
+	IncrementFailures(ctx context.Context, id uuid.UUID) (*Subscription, error)
+	DisableSubscription(ctx context.Context, id uuid.UUID) error
+
+	// CreateDelivery ignores deliveries of an event already delivered to the subscription,
+	// and returns ErrSubscriptionNotFound when the subscription does not exist
+	CreateDelivery(ctx context.Context, delivery *Delivery) error
+	// ClaimDueDeliveries locks up to limit pending deliveries due at now until the transaction ends
+	ClaimDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*Delivery, error)
+	// RecordAttempt saves Status, NextAttemptAt, ResponseCode and LastError and counts the attempt
+	RecordAttempt(ctx context.Context, delivery *Delivery) error
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]*Delivery, error)
+}
@@ -0,0 +1,62 @@
+package webhook_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SoraDaibu/go-clean-starter/internal/webhook"
+)
+
+func TestSubscription_Matches(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventTypes []string
+		eventType  string
+		expected   bool
+	}{
+		{name: "no filter matches everything", eventTypes: nil, eventType: "user.created", expected: true},
+		{name: "exact type", eventTypes: []string{"user.created"}, eventType: "user.created", expected: true},
+		{name: "other type", eventTypes: []string{"user.created"}, eventType: "user.deleted", expected: false},
+		{name: "aggregate wildcard", eventTypes: []string{"item.*"}, eventType: "item.updated", expected: true},
+		{name: "other aggregate", eventTypes: []string{"item.*"}, eventType: "user.updated", expected: false},
+		{name: "wildcard", eventTypes: []string{"*"}, eventType: "user.updated", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &webhook.Subscription{EventTypes: tt.eventTypes}
+			assert.Equal(t, tt.expected, s.Matches(tt.eventType))
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	secret := "whsec_0123456789abcdef"
+	body := []byte(`{"id":1}`)
+	signedAt := time.Unix(1700000000, 0)
+	signature := webhook.Sign(secret, signedAt, body)
+	timestamp := "1700000000"
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		body      []byte
+		now       time.Time
+		expected  error
+	}{
+		{name: "valid", secret: secret, signature: signature, body: body, now: signedAt.Add(time.Minute)},
+		{name: "wrong secret", secret: "another secret", signature: signature, body: body, now: signedAt, expected: webhook.ErrInvalidSignature},
+		{name: "tampered body", secret: secret, signature: signature, body: []byte(`{"id":2}`), now: signedAt, expected: webhook.ErrInvalidSignature},
+		{name: "replayed", secret: secret, signature: signature, body: body, now: signedAt.Add(time.Hour), expected: webhook.ErrExpiredTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhook.Verify(tt.secret, tt.signature, timestamp, tt.body, tt.now, 5*time.Minute)
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/SoraDaibu/go-clean-starter/cmd"
+	"github.com/SoraDaibu/go-clean-starter/internal/buildinfo"
 
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v3"
@@ -18,13 +19,18 @@ var Version = "edge"
 var Revision = "latest"
 
 func main() {
+	buildinfo.Set(Version, Revision)
+
 	app := &cli.Command{
 		Name:    "go-clean-starter",
 		Version: fmt.Sprintf("%s - %s", Version, Revision),
+		Flags:   cmd.ConfigFlags(),
 		Commands: []*cli.Command{
 			cmd.ServeCommand,
 			cmd.TaskCommand,
+			cmd.RelayCommand,
 			cmd.MigrationCommand,
+			cmd.ConfigCommand,
 		},
 	}
 
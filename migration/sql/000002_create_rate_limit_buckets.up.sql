@@ -0,0 +1,11 @@
+-- rate limit buckets
+-- UNLOGGED: buckets are short-lived counters and losing them on crash only resets the limits
+CREATE UNLOGGED TABLE rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE rate_limit_buckets IS 'This table stores token buckets shared by API replicas for rate limiting';
+
+CREATE INDEX idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
@@ -0,0 +1,14 @@
+-- idempotency keys
+CREATE TABLE idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    fingerprint VARCHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_headers JSONB,
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+COMMENT ON TABLE idempotency_keys IS 'This table stores responses of requests sent with an Idempotency-Key header to replay them on retries';
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,3 @@
+-- Drop version columns
+ALTER TABLE items DROP COLUMN IF EXISTS version;
+ALTER TABLE users DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+-- optimistic concurrency control: every update bumps the version
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE items ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
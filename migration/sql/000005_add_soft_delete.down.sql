@@ -0,0 +1,23 @@
+-- Soft deleted rows become active again, which fails while one shares its email with another user:
+-- purge or rename those users first, as the unique email constraint cannot be restored.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(DISTINCT u.id::TEXT, ', ') INTO conflicts
+    FROM users u
+    WHERE u.deleted_at IS NOT NULL
+      AND EXISTS (SELECT 1 FROM users o WHERE o.email = u.email AND o.id <> u.id);
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'soft deleted users share their email with other users: %', conflicts;
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_items_deleted_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS users_email_active_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE items DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
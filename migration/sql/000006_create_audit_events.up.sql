@@ -0,0 +1,16 @@
+-- audit events
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID,
+    action VARCHAR(50) NOT NULL,
+    diff JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE audit_events IS 'This table stores who changed which entity, and how';
+
+CREATE INDEX idx_audit_events_entity ON audit_events (entity_type, entity_id, id);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor, id);
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_items_name_trgm;
+DROP INDEX IF EXISTS idx_items_search;
+
+ALTER TABLE items DROP COLUMN IF EXISTS search;
+ALTER TABLE items DROP COLUMN IF EXISTS description;
+ALTER TABLE items DROP COLUMN IF EXISTS name;
+
+-- pg_trgm is kept, as other objects may depend on it
@@ -0,0 +1,14 @@
+-- trigram similarity, to find items by names with typos
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- names and descriptions of imported items
+ALTER TABLE items ADD COLUMN name TEXT NOT NULL DEFAULT '';
+ALTER TABLE items ADD COLUMN description TEXT NOT NULL DEFAULT '';
+
+-- full-text search document, names weighted above descriptions
+ALTER TABLE items ADD COLUMN search TSVECTOR NOT NULL GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', name), 'A') || setweight(to_tsvector('english', description), 'B')
+) STORED;
+
+CREATE INDEX idx_items_search ON items USING GIN (search);
+CREATE INDEX idx_items_name_trgm ON items USING GIN (name gin_trgm_ops);
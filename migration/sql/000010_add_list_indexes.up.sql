@@ -0,0 +1,3 @@
+-- lists of active users and items are sorted newest first by default, with id breaking ties
+CREATE INDEX idx_users_created_at ON users (created_at DESC, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_items_created_at ON items (created_at DESC, id) WHERE deleted_at IS NULL;
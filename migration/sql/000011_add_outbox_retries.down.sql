@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_outbox_dead_lettered_at;
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX idx_outbox_pending ON outbox (id) WHERE published_at IS NULL;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS dead_lettered_at;
+ALTER TABLE outbox DROP COLUMN IF EXISTS next_attempt_at;
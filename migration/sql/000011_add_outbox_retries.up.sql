@@ -0,0 +1,8 @@
+-- outbox events failing to publish are retried with backoff, and dead-lettered after too many attempts
+ALTER TABLE outbox ADD COLUMN next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE outbox ADD COLUMN dead_lettered_at TIMESTAMP WITH TIME ZONE;
+
+-- the relay looks up the earliest pending event of each aggregate
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX idx_outbox_pending ON outbox (aggregate_type, aggregate_id, id) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+CREATE INDEX idx_outbox_dead_lettered_at ON outbox (dead_lettered_at) WHERE dead_lettered_at IS NOT NULL;
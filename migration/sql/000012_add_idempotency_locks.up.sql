@@ -0,0 +1,3 @@
+-- a request holds its idempotency key until locked_until instead of locking the row while its handler runs,
+-- so that a retry can take over the key of a request that stopped without storing a response
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
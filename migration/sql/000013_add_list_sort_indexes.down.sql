@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_items_updated_at;
+DROP INDEX IF EXISTS idx_items_name;
+DROP INDEX IF EXISTS idx_users_updated_at;
+DROP INDEX IF EXISTS idx_users_name;
@@ -0,0 +1,5 @@
+-- lists of active users and items can also be sorted by name, A to Z, or most recently updated first, with id breaking ties
+CREATE INDEX idx_users_name ON users (name, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_updated_at ON users (updated_at DESC, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_items_name ON items (name, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_items_updated_at ON items (updated_at DESC, id) WHERE deleted_at IS NULL;